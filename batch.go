@@ -1,33 +1,137 @@
 package ulog
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 type cfgkey string
 
-// batchHandler is the interface for batch handlers.  A transport
-// will provide a batch handler when initialising a batch, which
-// will use the handler to send full or flushed batches.
-type batchHandler interface {
+// BatchHandler is the interface for batch handlers.  A batch uses its
+// handler to send full or flushed batches.
+//
+// Send is called with a batch containing the entries to be sent.  If the
+// batch cannot be sent an error should be returned, in which case the
+// entries are retained and sent again when the batch is next flushed.  A
+// handler that is able to send only some of the entries may call Retain to
+// identify the entries to be retained before returning an error.
+type BatchHandler interface {
+	Send(*Batch) error
+}
+
+// configurableHandler is implemented by the batch handlers of the
+// transports provided by this package, to which transport options apply
+// configuration.
+type configurableHandler interface {
 	configure(key cfgkey, value any) error
-	send(*Batch) error
 }
 
+// recordHandler is implemented by the batch handler of a transport that
+// adds entries to a batch as records comprising a header followed by the
+// formatted entry.  Only the formatted entry of a record is truncated.
+type recordHandler interface {
+	headerLen(rec []byte) int // returns the length of the header of a record
+}
+
+// OversizeEntryPolicy determines how a Batch handles an entry that is
+// larger than the maximum entry size configured for the batch.
+type OversizeEntryPolicy int
+
+const (
+	RejectOversizeEntries   OversizeEntryPolicy = iota // RejectOversizeEntries discards any entry that exceeds the maximum entry size
+	TruncateOversizeEntries                            // TruncateOversizeEntries truncates any entry that exceeds the maximum entry size
+)
+
 // Batch is a collection of log entries that can be written by
-// a Transport in a single operation.
+// a Transport in a single operation.  A Batch is used by the batching
+// transports provided by this package and may also be used to implement
+// batching in other code, using NewBatch with a BatchHandler to send
+// batches of entries added using Add.
+//
+// A batch is sent when any of the following limits is reached:
+//
+//   - the number of entries in the batch reaches the maximum
+//   - the size of the batch (in bytes) reaches the maximum
+//   - the oldest entry in the batch has been held for the maximum latency
+//
+// An entry that would take the batch over the maximum size causes the
+// existing entries to be sent first, with the new entry then added to
+// an empty batch.
+//
+// A batch that could not be sent is retained and sent again when next
+// flushed.  If a retained batch exceeds the entry or byte limits it is
+// split, with each part sent separately.
+//
+// A Batch is not safe for concurrent use.  The maximum latency of a batch
+// is applied by the batching transports of this package; other code using
+// a Batch is responsible for calling Flush as required.
 type Batch struct {
-	entries      [][]byte // the batched entries
-	size         int      // size of the batch in bytes
-	len          int      // number of entries in the batch
-	max          int      // maximum number of entries in the batch
-	batchHandler          // the handler for the batch
+	entries       [][]byte            // the batched entries
+	size          int                 // size of the batch in bytes
+	len           int                 // number of entries in the batch
+	max           int                 // maximum number of entries in the batch
+	maxBytes      int                 // maximum size of the batch in bytes; 0 = no limit
+	maxEntryBytes int                 // maximum size of an individual entry in bytes; 0 = maxBytes
+	maxLatency    time.Duration       // maximum time for which an entry is held before the batch is sent; 0 = no limit
+	oversize      OversizeEntryPolicy // policy applied to entries larger than the maximum entry size
+	handler       BatchHandler        // the handler for the batch
+}
+
+// Entries returns the entries in the batch.  The returned slice and the
+// entries in it must not be modified.
+func (b *Batch) Entries() [][]byte {
+	return b.entries
+}
+
+// Len returns the number of entries in the batch.
+func (b *Batch) Len() int {
+	return b.len
+}
+
+// Size returns the total size of the entries in the batch, in bytes.
+func (b *Batch) Size() int {
+	return b.size
+}
+
+// NewBatch returns a new Batch that sends batches of entries using a
+// specified handler, with specified configuration options applied.  By
+// default a batch is sent when it contains 100 entries, with no limit on
+// the size of a batch.
+func NewBatch(handler BatchHandler, opts ...BatchOption) (*Batch, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("%w: NewBatch: handler is nil", ErrInvalidConfiguration)
+	}
+
+	b := &Batch{}
+	b.init(handler, 100)
+
+	errs := []error{}
+	for _, opt := range opts {
+		errs = append(errs, opt(b))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return b, nil
 }
 
 // init initialises the batch with a handler and a maximum number
 // of entries.
-func (b *Batch) init(handler batchHandler, max int) {
+func (b *Batch) init(handler BatchHandler, max int) {
 	b.len = 0
 	b.size = 0
 	b.max = max
 	b.entries = make([][]byte, 0, max)
-	b.batchHandler = handler
+	b.handler = handler
+}
+
+// configure applies configuration to the handler of the batch.
+func (b *Batch) configure(key cfgkey, value any) error {
+	if h, ok := b.handler.(configurableHandler); ok {
+		return h.configure(key, value)
+	}
+	return fmt.Errorf("%w: %s", ErrKeyNotSupported, key)
 }
 
 // clear resets the batch to an empty state.
@@ -37,23 +141,132 @@ func (b *Batch) clear() {
 	b.len = 0
 }
 
-// add adds an entry to the batch.  If the batch is full it is
-// sent to the handler and the batch is reset.
-func (b *Batch) add(entry []byte) {
+// entryLimit returns the maximum size of an individual entry in the
+// batch; 0 indicates no limit.
+func (b *Batch) entryLimit() int {
+	if b.maxEntryBytes > 0 && (b.maxBytes == 0 || b.maxEntryBytes < b.maxBytes) {
+		return b.maxEntryBytes
+	}
+	return b.maxBytes
+}
+
+// Add adds an entry to the batch.  If the batch is full it is
+// sent to the handler and the batch is reset.  The batch retains the
+// entry; the entry must not be modified after it has been added.
+//
+// If the entry would take the batch over the maximum size (in bytes)
+// the existing entries are sent before the new entry is added.
+//
+// An entry that is larger than the maximum entry size is rejected or
+// truncated, according to the OversizeEntryPolicy of the batch.  If the
+// handler adds entries as records (see: recordHandler) only the formatted
+// entry is truncated; a record with a header that exceeds the limit is
+// rejected.
+func (b *Batch) Add(entry []byte) {
+	if limit := b.entryLimit(); limit > 0 && len(entry) > limit {
+		if b.oversize != TruncateOversizeEntries {
+			tracef("batch: entry rejected: %d bytes exceeds limit of %d bytes", len(entry), limit)
+			return
+		}
+		if rh, ok := b.handler.(recordHandler); ok {
+			if hl := rh.headerLen(entry); hl >= limit {
+				tracef("batch: entry rejected: record header of %d bytes exceeds limit of %d bytes", hl, limit)
+				return
+			}
+		}
+		tracef("batch: entry truncated: %d bytes exceeds limit of %d bytes", len(entry), limit)
+		entry = entry[:limit]
+	}
+
+	if b.maxBytes > 0 && b.len > 0 && b.size+len(entry) > b.maxBytes {
+		b.Flush()
+	}
+
 	b.entries = append(b.entries, entry)
 	b.size += len(entry)
 	b.len += 1
 
-	if b.len >= b.max {
-		b.flush()
+	if b.len >= b.max || (b.maxBytes > 0 && b.size >= b.maxBytes) {
+		b.Flush()
+	}
+}
+
+// Flush sends a non-empty batch to the handler and resets.
+//
+// If the batch exceeds the entry or byte limits (which is possible only
+// if a previous flush failed) the batch is split and each part is sent
+// separately.  If a part cannot be sent, that part and any remaining
+// entries are retained.
+//
+// A handler that is able to send only some of the entries in a batch may
+// call Retain to identify the entries to be retained before returning an
+// error; only those entries are then retained (together with any entries
+// remaining in the batch that were not yet sent).
+func (b *Batch) Flush() {
+	for b.len > 0 {
+		n, size := b.split()
+		if n == b.len {
+			if err := b.handler.Send(b); err == nil {
+				b.clear()
+			}
+			return
+		}
+
+		part := &Batch{
+			entries:  b.entries[:n],
+			size:     size,
+			len:      n,
+			max:      b.max,
+			maxBytes: b.maxBytes,
+			handler:  b.handler,
+		}
+		if err := b.handler.Send(part); err != nil {
+			if part.len < n {
+				b.entries = append(part.entries, b.entries[n:]...)
+				b.size -= size - part.size
+				b.len -= n - part.len
+			}
+			return
+		}
+		b.entries = b.entries[n:]
+		b.size -= size
+		b.len -= n
 	}
 }
 
-// flush sends a non-empty batch to the handler and resets.
-func (b *Batch) flush() {
-	if b.len > 0 {
-		if err := b.send(b); err == nil {
-			b.clear()
+// Retain reduces the batch to the entries at the specified indices, which
+// must be in ascending order.  Indices that do not identify an entry in the
+// batch are ignored.
+func (b *Batch) Retain(idx []int) {
+	entries := make([][]byte, 0, max(b.max, len(idx)))
+	size := 0
+	for _, i := range idx {
+		if i < 0 || i >= b.len {
+			continue
+		}
+		entries = append(entries, b.entries[i])
+		size += len(b.entries[i])
+	}
+	b.entries = entries
+	b.size = size
+	b.len = len(entries)
+}
+
+// split returns the number of entries (and their total size) that may be
+// sent from the start of the batch without exceeding the entry or byte
+// limits.  At least one entry is always included.
+func (b *Batch) split() (int, int) {
+	if (b.max == 0 || b.len <= b.max) && (b.maxBytes == 0 || b.size <= b.maxBytes) {
+		return b.len, b.size
+	}
+
+	n, size := 0, 0
+	for _, e := range b.entries {
+		if n > 0 && ((b.max > 0 && n == b.max) || (b.maxBytes > 0 && size+len(e) > b.maxBytes)) {
+			break
 		}
+		n++
+		size += len(e)
 	}
+	return n, size
 }
@@ -0,0 +1,96 @@
+package ulog
+
+import (
+	"fmt"
+	"time"
+)
+
+type BatchOption = func(*Batch) error // BatchOption is a function that configures a Batch
+
+// BatchMaxEntries configures the maximum number of entries in a batch.
+// A batch is sent when the number of entries reaches this number.
+func BatchMaxEntries(n int) BatchOption {
+	return func(b *Batch) error {
+		if n < 1 {
+			return fmt.Errorf("%w: BatchMaxEntries: %d: must be >= 1", ErrInvalidConfiguration, n)
+		}
+		b.init(b.handler, n)
+		return nil
+	}
+}
+
+// BatchMaxBytes configures the maximum size of a batch, in bytes.  A
+// batch is sent when its size reaches this limit; an entry that would
+// take the batch over the limit causes the existing entries to be sent
+// first.
+//
+// The limit applies to the formatted entries only; a transport may
+// add framing or encoding overhead when sending a batch, so the limit
+// should allow some headroom below any limit imposed by the receiving
+// service.
+//
+// A value of 0 (the default) disables the limit.
+func BatchMaxBytes(n int) BatchOption {
+	return func(b *Batch) error {
+		if n < 0 {
+			return fmt.Errorf("%w: BatchMaxBytes: %d: must be >= 0", ErrInvalidConfiguration, n)
+		}
+		b.maxBytes = n
+		return nil
+	}
+}
+
+// BatchMaxEntryBytes configures the maximum size of an individual entry,
+// in bytes.  An entry larger than this is rejected or truncated according
+// to the BatchOversizeEntries policy.
+//
+// If not configured, or if greater than the BatchMaxBytes limit, the
+// BatchMaxBytes limit applies.
+func BatchMaxEntryBytes(n int) BatchOption {
+	return func(b *Batch) error {
+		if n < 0 {
+			return fmt.Errorf("%w: BatchMaxEntryBytes: %d: must be >= 0", ErrInvalidConfiguration, n)
+		}
+		b.maxEntryBytes = n
+		return nil
+	}
+}
+
+// BatchMaxLatency configures the maximum time for which an entry is held
+// in a batch.  A batch containing at least one entry is sent when the
+// oldest entry has been held for this time, even if no other limit has
+// been reached.
+//
+// A value of 0 disables the latency limit; a batch is then sent only
+// when full or when the transport is closed.
+func BatchMaxLatency(d time.Duration) BatchOption {
+	return func(b *Batch) error {
+		if d < 0 {
+			return fmt.Errorf("%w: BatchMaxLatency: %v: must be >= 0", ErrInvalidConfiguration, d)
+		}
+		b.maxLatency = d
+		return nil
+	}
+}
+
+// BatchOversizeEntries configures the policy applied to an entry that is
+// larger than the maximum entry size.  The default is RejectOversizeEntries.
+//
+// Truncating an entry will usually result in an entry that is no longer
+// valid for the format in which it was written (e.g. JSON); truncation
+// is appropriate only where the receiving service tolerates this.
+//
+// Transports that encode entries in a structured envelope (e.g. datadog,
+// fluent, otlp and splunk) do not support truncation and return an error
+// if TruncateOversizeEntries is configured.
+func BatchOversizeEntries(p OversizeEntryPolicy) BatchOption {
+	return func(b *Batch) error {
+		switch p {
+		case RejectOversizeEntries, TruncateOversizeEntries:
+			b.oversize = p
+			return nil
+		default:
+			return fmt.Errorf("%w: BatchOversizeEntries: invalid policy (%d)", ErrInvalidConfiguration, p)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ulog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestBatchOptions(t *testing.T) {
+	// ARRANGE
+	var sut *Batch
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "BatchMaxEntries",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxEntries(42)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.max).Equals(42)
+				test.That(t, cap(sut.entries)).Equals(42)
+			},
+		},
+		{scenario: "BatchMaxEntries/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxEntries(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "BatchMaxBytes",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxBytes(1024)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.maxBytes).Equals(1024)
+			},
+		},
+		{scenario: "BatchMaxBytes/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxBytes(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "BatchMaxEntryBytes",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxEntryBytes(256)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.maxEntryBytes).Equals(256)
+			},
+		},
+		{scenario: "BatchMaxEntryBytes/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxEntryBytes(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "BatchMaxEntryBytes/limited by max bytes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = BatchMaxBytes(128)(sut)
+
+				// ACT
+				_ = BatchMaxEntryBytes(256)(sut)
+
+				// ASSERT
+				test.That(t, sut.entryLimit()).Equals(128)
+			},
+		},
+		{scenario: "BatchMaxLatency",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxLatency(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.maxLatency).Equals(time.Minute)
+			},
+		},
+		{scenario: "BatchMaxLatency/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchMaxLatency(-time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "BatchOversizeEntries",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchOversizeEntries(TruncateOversizeEntries)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.oversize).Equals(TruncateOversizeEntries)
+			},
+		},
+		{scenario: "BatchOversizeEntries/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BatchOversizeEntries(OversizeEntryPolicy(-1))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &Batch{}
+			sut.init(&mockBatchHandler{}, 16)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package ulog
+
+import "time"
+
+// batchTransport provides the channel and run loop common to transports
+// that send log entries in batches.  A batching transport embeds a
+// batchTransport with a Batch initialised with a batchHandler specific
+// to the service to which batches are sent.
+type batchTransport struct {
+	name  string      // name of the transport (used in trace messages)
+	ch    chan []byte // channel over which log entries are received
+	batch *Batch      // the current batch
+}
+
+// log sends a formatted log entry to the transport.
+func (t *batchTransport) log(b []byte) {
+	// we need to copy the contents of the slice before sending to
+	// the transport channel (asynchronous) as the slice is owned by
+	// the target; if we don't copy the contents, the target will
+	// re-use the slice for subsequent log entries
+	buf := make([]byte, len(b))
+	copy(buf, b)
+
+	t.ch <- buf
+}
+
+// stop closes the channel over which log entries are received.
+func (t *batchTransport) stop() {
+	tracef("%s: transport requested to stop...", t.name)
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  The run loop
+// terminates when the channel over which log entries are received is
+// closed.
+//
+// logs are read from the channel and added to the batch, which is sent
+// when it reaches the configured entry or byte limits.
+//
+// when the first entry is added to an empty batch a timer is started;
+// if the timer expires before the batch has been sent, the batch is
+// sent regardless of its size.  if the batch could not be sent, the
+// timer is restarted so that sending is retried.
+//
+// when the channel is closed any entries remaining in the batch are
+// sent before the run loop terminates.
+func (t *batchTransport) run() {
+	var (
+		batch   = t.batch
+		timer   *time.Timer
+		expired <-chan time.Time
+	)
+	startTimer := func() {
+		if batch.maxLatency > 0 {
+			timer = time.NewTimer(batch.maxLatency)
+			expired = timer.C
+		}
+	}
+	stopTimer := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, expired = nil, nil
+		}
+	}
+
+loop:
+	for {
+		select {
+		case entry, ok := <-t.ch:
+			if !ok {
+				tracef("%s: transport stopping...", t.name)
+				stopTimer()
+				batch.Flush()
+				break loop
+			}
+			batch.Add(entry)
+
+			switch {
+			case batch.len == 0:
+				stopTimer()
+			case timer == nil:
+				startTimer()
+			}
+		case <-expired:
+			timer, expired = nil, nil
+			batch.Flush()
+			if batch.len > 0 {
+				startTimer()
+			}
+		}
+	}
+	tracef("%s: transport stopped", t.name)
+}
@@ -0,0 +1,219 @@
+package ulog
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestBatchTransport(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "log",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &batchTransport{ch: make(chan []byte)}
+
+				// setup a coroutine to listen to the channel used by the transport
+				// when sending log entries, copying the sent bytes
+				sent := []byte{}
+				wg := &sync.WaitGroup{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sent = append(sent, <-sut.ch...)
+				}()
+
+				// ACT
+				sut.log([]byte("bytes sent"))
+
+				// CLEANUP
+				close(sut.ch)
+				wg.Wait()
+
+				// ASSERT
+				test.That(t, sent).Equals([]byte("bytes sent"))
+			},
+		},
+
+		// run scenarios
+		{scenario: "run",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				mh := &mockBatchHandler{}
+				sut := &batchTransport{
+					name:  "test",
+					ch:    make(chan []byte),
+					batch: &Batch{maxLatency: 50 * time.Millisecond},
+				}
+
+				testcases := []struct {
+					scenario string
+					exec     func(t *testing.T)
+				}{
+					{scenario: "adds entries to batch and flushes when batch full or channel closed",
+						exec: func(t *testing.T) {
+							// ARRANGE
+							wg := &sync.WaitGroup{}
+							wg.Add(1)
+							go func() {
+								defer wg.Done()
+								sut.run()
+							}()
+
+							// ACT
+							sut.ch <- []byte("entry 1")
+							sut.ch <- []byte("entry 2")
+							sut.ch <- []byte("entry 3") // <- fills the first batch
+							sut.ch <- []byte("entry 4") // <- incomplete batch will be sent when the channel is closed
+
+							// CLEANUP
+							close(sut.ch)
+							wg.Wait()
+
+							// ASSERT
+							test.That(t, mh.sendCalls).Equals(2)
+							test.That(t, mh.sentEntries).Equals(4)
+						},
+					},
+					{scenario: "sends partial batch when max latency exceeded",
+						exec: func(t *testing.T) {
+							// ARRANGE
+							wg := &sync.WaitGroup{}
+							wg.Add(1)
+							go func() {
+								defer wg.Done()
+								sut.run()
+							}()
+
+							// ACT
+							sut.ch <- []byte("entry 1")
+							sut.ch <- []byte("entry 2")
+							time.Sleep(sut.batch.maxLatency * 2)
+
+							// CLEANUP
+							close(sut.ch)
+							wg.Wait()
+
+							// ASSERT
+							test.That(t, mh.sendCalls).Equals(1)
+							test.That(t, mh.sentEntries).Equals(2)
+						},
+					},
+					{scenario: "max latency is measured from the first entry in the batch",
+						exec: func(t *testing.T) {
+							// ARRANGE
+							sent := make(chan struct{}, 10)
+							mh.sendfn = func(*Batch) error { sent <- struct{}{}; return nil }
+							defer func() { mh.sendfn = nil }()
+
+							wg := &sync.WaitGroup{}
+							wg.Add(1)
+							go func() {
+								defer wg.Done()
+								sut.run()
+							}()
+
+							// ACT
+							// (entries are sent at intervals shorter than the max latency
+							// but the batch is sent once the first entry has been held for
+							// longer than the max latency)
+							for i := 0; i < 2; i++ {
+								sut.ch <- []byte("entry")
+								time.Sleep(sut.batch.maxLatency * 3 / 4)
+							}
+							sentBeforeClose := len(sent)
+
+							// CLEANUP
+							close(sut.ch)
+							wg.Wait()
+
+							// ASSERT
+							test.That(t, sentBeforeClose).Equals(1)
+							test.That(t, mh.sentEntries).Equals(2)
+						},
+					},
+					{scenario: "retries a failed batch when max latency exceeded",
+						exec: func(t *testing.T) {
+							// ARRANGE
+							sent := make(chan struct{}, 10)
+							fail := true
+							mh.sendfn = func(*Batch) error {
+								sent <- struct{}{}
+								if fail {
+									fail = false
+									return errors.New("send error")
+								}
+								return nil
+							}
+							defer func() { mh.sendfn = nil }()
+
+							wg := &sync.WaitGroup{}
+							wg.Add(1)
+							go func() {
+								defer wg.Done()
+								sut.run()
+							}()
+
+							// ACT
+							sut.ch <- []byte("entry 1")
+							time.Sleep(sut.batch.maxLatency * 3)
+							sentBeforeClose := len(sent)
+
+							// CLEANUP
+							close(sut.ch)
+							wg.Wait()
+
+							// ASSERT
+							test.That(t, sentBeforeClose).Equals(2)
+							test.That(t, mh.sentEntries).Equals(1)
+						},
+					},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.scenario, func(t *testing.T) {
+						// ARRANGE
+						mh.reset()
+						sut.batch.init(mh, 3)
+						sut.ch = make(chan []byte)
+
+						// ACT
+						tc.exec(t)
+					})
+				}
+			},
+		},
+
+		// stop
+		{scenario: "stop",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &batchTransport{ch: make(chan []byte)}
+				wg := &sync.WaitGroup{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-sut.ch // will not receive anything; will yield nil when the channel is closed
+				}()
+
+				// ACT
+				sut.stop()
+
+				// ACT / ASSERT
+				// (nothing to assert; the test will timeout if the channel is not closed)
+				wg.Wait()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
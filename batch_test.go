@@ -26,11 +26,11 @@ func TestBatch_add(t *testing.T) {
 			exec: func(t *testing.T) {
 				// ARRANGE
 				handler := &mockBatchHandler{}
-				sut := &Batch{entries: [][]byte{}, max: 10, batchHandler: handler}
+				sut := &Batch{entries: [][]byte{}, max: 10, handler: handler}
 				want := &Batch{entries: [][]byte{[]byte("foo")}, max: 10, size: 3, len: 1}
 
 				// ACT
-				sut.add([]byte("foo"))
+				sut.Add([]byte("foo"))
 
 				// ASSERT
 				test.That(t, sut).Equals(want, batchesEqual)
@@ -41,11 +41,11 @@ func TestBatch_add(t *testing.T) {
 			exec: func(t *testing.T) {
 				// ARRANGE
 				handler := &mockBatchHandler{}
-				sut := &Batch{entries: [][]byte{}, max: 1, batchHandler: handler}
+				sut := &Batch{entries: [][]byte{}, max: 1, handler: handler}
 				want := &Batch{entries: [][]byte{}, max: 1, size: 0, len: 0}
 
 				// ACT
-				sut.add([]byte("foo"))
+				sut.Add([]byte("foo"))
 
 				// ASSERT
 				test.That(t, sut).Equals(want, batchesEqual)
@@ -58,11 +58,11 @@ func TestBatch_add(t *testing.T) {
 				handler := &mockBatchHandler{
 					sendfn: func(*Batch) error { return errors.New("flush error") },
 				}
-				sut := &Batch{entries: [][]byte{}, max: 1, batchHandler: handler}
+				sut := &Batch{entries: [][]byte{}, max: 1, handler: handler}
 				want := &Batch{entries: [][]byte{[]byte("foo")}, max: 1, size: 3, len: 1}
 
 				// ACT
-				sut.add([]byte("foo"))
+				sut.Add([]byte("foo"))
 
 				// ASSERT
 				test.That(t, sut).Equals(want, batchesEqual)
@@ -71,6 +71,69 @@ func TestBatch_add(t *testing.T) {
 			},
 		},
 
+		{scenario: "add/max bytes reached",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				sut := &Batch{entries: [][]byte{}, max: 10, maxBytes: 6, handler: handler}
+
+				// ACT
+				sut.Add([]byte("foo"))
+				sut.Add([]byte("bar"))
+
+				// ASSERT
+				test.That(t, handler.sendCalls, "batches sent").Equals(1)
+				test.That(t, handler.sentEntries, "entries sent").Equals(2)
+				test.That(t, sut.len, "entries in batch").Equals(0)
+			},
+		},
+		{scenario: "add/entry would exceed max bytes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				sut := &Batch{entries: [][]byte{}, max: 10, maxBytes: 8, handler: handler}
+				want := &Batch{entries: [][]byte{[]byte("bar")}, max: 10, size: 3, len: 1}
+
+				// ACT
+				sut.Add([]byte("foobar"))
+				sut.Add([]byte("bar"))
+
+				// ASSERT
+				test.That(t, handler.sendCalls, "batches sent").Equals(1)
+				test.That(t, handler.sentBytes, "bytes sent").Equals(6)
+				test.That(t, sut).Equals(want, batchesEqual)
+			},
+		},
+		{scenario: "add/oversize entry/rejected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				sut := &Batch{entries: [][]byte{}, max: 10, maxBytes: 100, maxEntryBytes: 4, handler: handler}
+				want := &Batch{entries: [][]byte{}, max: 10}
+
+				// ACT
+				sut.Add([]byte("foobar"))
+
+				// ASSERT
+				test.That(t, sut).Equals(want, batchesEqual)
+				test.That(t, handler.sendCalls, "batches sent").Equals(0)
+			},
+		},
+		{scenario: "add/oversize entry/truncated",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				sut := &Batch{entries: [][]byte{}, max: 10, maxBytes: 4, oversize: TruncateOversizeEntries, handler: handler}
+
+				// ACT
+				sut.Add([]byte("foobar"))
+
+				// ASSERT
+				test.That(t, handler.sendCalls, "batches sent").Equals(1)
+				test.That(t, handler.sentBytes, "bytes sent").Equals(4)
+			},
+		},
+
 		// clear tests
 		{scenario: "clear",
 			exec: func(t *testing.T) {
@@ -92,10 +155,10 @@ func TestBatch_add(t *testing.T) {
 			exec: func(t *testing.T) {
 				// ARRANGE
 				handler := &mockBatchHandler{}
-				sut := &Batch{entries: [][]byte{}, max: 10, batchHandler: handler}
+				sut := &Batch{entries: [][]byte{}, max: 10, handler: handler}
 
 				// ACT
-				sut.flush()
+				sut.Flush()
 
 				// ASSERT
 				test.That(t, handler.sendCalls, "batches sent").Equals(0)
@@ -105,11 +168,11 @@ func TestBatch_add(t *testing.T) {
 			exec: func(t *testing.T) {
 				// ARRANGE
 				handler := &mockBatchHandler{}
-				sut := &Batch{entries: [][]byte{}, max: 10, len: 1, batchHandler: handler}
+				sut := &Batch{entries: [][]byte{}, max: 10, len: 1, handler: handler}
 				want := &Batch{entries: [][]byte{}, max: 10}
 
 				// ACT
-				sut.flush()
+				sut.Flush()
 
 				// ASSERT
 				test.That(t, handler.sendCalls, "batches sent").Equals(1)
@@ -117,6 +180,212 @@ func TestBatch_add(t *testing.T) {
 				test.That(t, sut).Equals(want, batchesEqual)
 			},
 		},
+		{scenario: "flush/retained batch exceeds limits",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				sut := &Batch{
+					entries:  [][]byte{[]byte("a"), []byte("bb"), []byte("ccc"), []byte("dddd")},
+					len:      4,
+					size:     10,
+					max:      3,
+					maxBytes: 5,
+					handler:  handler,
+				}
+
+				// ACT
+				sut.Flush()
+
+				// ASSERT
+				test.That(t, handler.sendCalls, "batches sent").Equals(3)
+				test.That(t, handler.sentEntries, "entries sent").Equals(4)
+				test.That(t, handler.sentBytes, "bytes sent").Equals(10)
+				test.That(t, sut.len, "entries in batch").Equals(0)
+			},
+		},
+		{scenario: "flush/retained batch exceeds limits/part fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				handler.sendfn = func(b *Batch) error {
+					if handler.sendCalls > 1 {
+						return errors.New("send error")
+					}
+					return nil
+				}
+				sut := &Batch{
+					entries:  [][]byte{[]byte("a"), []byte("bb"), []byte("ccc"), []byte("dddd")},
+					len:      4,
+					size:     10,
+					max:      10,
+					maxBytes: 5,
+					handler:  handler,
+				}
+				want := &Batch{entries: [][]byte{[]byte("ccc"), []byte("dddd")}, max: 10, size: 7, len: 2}
+
+				// ACT
+				sut.Flush()
+
+				// ASSERT
+				test.That(t, handler.sendCalls, "batches sent").Equals(2)
+				test.That(t, sut).Equals(want, batchesEqual)
+			},
+		},
+
+		{scenario: "flush/retained batch exceeds limits/part partially retained",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				handler.sendfn = func(b *Batch) error {
+					b.Retain([]int{1})
+					return errors.New("send error")
+				}
+				sut := &Batch{
+					entries:  [][]byte{[]byte("a"), []byte("bb"), []byte("ccc"), []byte("dddd")},
+					len:      4,
+					size:     10,
+					max:      10,
+					maxBytes: 5,
+					handler:  handler,
+				}
+				want := &Batch{entries: [][]byte{[]byte("bb"), []byte("ccc"), []byte("dddd")}, max: 10, size: 9, len: 3}
+
+				// ACT
+				sut.Flush()
+
+				// ASSERT
+				test.That(t, handler.sendCalls, "batches sent").Equals(1)
+				test.That(t, sut).Equals(want, batchesEqual)
+			},
+		},
+		{scenario: "flush/partially retained",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+				handler.sendfn = func(b *Batch) error {
+					b.Retain([]int{0, 2})
+					return errors.New("send error")
+				}
+				sut := &Batch{
+					entries: [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")},
+					len:     3,
+					size:    6,
+					max:     10,
+					handler: handler,
+				}
+				want := &Batch{entries: [][]byte{[]byte("a"), []byte("ccc")}, max: 10, size: 4, len: 2}
+
+				// ACT
+				sut.Flush()
+
+				// ASSERT
+				test.That(t, sut).Equals(want, batchesEqual)
+			},
+		},
+
+		// retain tests
+		{scenario: "retain",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &Batch{entries: [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")}, max: 10, size: 6, len: 3}
+				want := &Batch{entries: [][]byte{[]byte("bb"), []byte("ccc")}, max: 10, size: 5, len: 2}
+
+				// ACT
+				sut.Retain([]int{-1, 1, 2, 3})
+
+				// ASSERT
+				test.That(t, sut).Equals(want, batchesEqual)
+			},
+		},
+
+		// accessor tests
+		{scenario: "Entries/Len/Size",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &Batch{entries: [][]byte{[]byte("foo")}, max: 10, size: 3, len: 1}
+
+				// ACT / ASSERT
+				test.That(t, sut.Entries()).Equals([][]byte{[]byte("foo")})
+				test.That(t, sut.Len()).Equals(1)
+				test.That(t, sut.Size()).Equals(3)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestNewBatch(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(*testing.T)
+	}{
+		{scenario: "NewBatch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				handler := &mockBatchHandler{}
+
+				// ACT
+				result, err := NewBatch(handler, BatchMaxEntries(2))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.max).Equals(2)
+				test.That(t, result.handler).Equals(BatchHandler(handler))
+
+				result.Add([]byte("a"))
+				result.Add([]byte("b"))
+				test.That(t, handler.sendCalls, "batches sent").Equals(1)
+				test.That(t, result.Len()).Equals(0)
+			},
+		},
+		{scenario: "NewBatch/defaults",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NewBatch(&mockBatchHandler{})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.max).Equals(100)
+				test.That(t, result.maxBytes).Equals(0)
+			},
+		},
+		{scenario: "NewBatch/nil handler",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NewBatch(nil)
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NewBatch/option error",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NewBatch(&mockBatchHandler{}, BatchMaxEntries(0))
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "configure/handler not configurable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut, _ := NewBatch(batchHandlerFunc(func(*Batch) error { return nil }))
+
+				// ACT
+				err := sut.configure(cfgkey("key"), "value")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
@@ -124,3 +393,8 @@ func TestBatch_add(t *testing.T) {
 		})
 	}
 }
+
+// batchHandlerFunc is a BatchHandler implemented by a function.
+type batchHandlerFunc func(*Batch) error
+
+func (fn batchHandlerFunc) Send(b *Batch) error { return fn(b) }
@@ -0,0 +1,132 @@
+package ulog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"time"
+)
+
+type BufferedOption = func(*buffered) error // BufferedOption is a function that configures a buffered transport
+
+// BufferedTransport returns a transport factory function to create and
+// configure a transport that writes log entries to an io.Writer through
+// a buffer, with specified configuration options applied.
+//
+// Each entry is formatted by the target Formatter and written to the
+// buffer followed by a newline.  Writes to the buffer are performed by
+// the goroutine of the transport, coalescing entries into a single write
+// to the io.Writer when the buffer is flushed.  The buffer is flushed:
+//
+//   - when it is full (see: BufferedSize)
+//   - periodically (see: BufferedFlushInterval)
+//   - after writing an entry at or above a specified level (see: BufferedFlushLevel)
+//   - when the transport is stopped
+//
+// Compared to a StdioTransport, this significantly reduces the number of
+// writes (and so syscalls) when writing to a file or pipe, at the cost of
+// a delay (up to the flush interval) before entries below the flush level
+// are written.
+func BufferedTransport(w io.Writer, opts ...BufferedOption) TransportFactory {
+	return func() (transport, error) {
+		t := &buffered{
+			ch:         make(chan bufferedEntry, 100),
+			w:          w,
+			size:       64 * 1024,
+			interval:   time.Second,
+			flushLevel: ErrorLevel,
+		}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+}
+
+// bufferedEntry is a formatted entry, together with an indication of
+// whether the buffer should be flushed after writing the entry.
+type bufferedEntry struct {
+	b     []byte
+	flush bool
+}
+
+// buffered implements a transport that writes log entries to an io.Writer
+// through a buffer.
+type buffered struct {
+	ch         chan bufferedEntry // channel over which entries are received
+	w          io.Writer          // the writer to which the buffer is flushed
+	size       int                // the size of the buffer
+	interval   time.Duration      // the interval at which the buffer is flushed
+	flushLevel Level              // entries at or above this level cause the buffer to be flushed
+}
+
+// log implements the transport interface.  It is not used; entries are
+// received by logEntry.
+func (t *buffered) log([]byte) {}
+
+// logEntry implements the entryTransport interface.  A copy of the
+// formatted entry, terminated by a newline, is sent to the transport
+// channel, together with an indication of whether the buffer should be
+// flushed after it is written, according to the level of the entry.
+func (t *buffered) logEntry(e entry, format func(entry) []byte) {
+	b := format(e)
+	rec := make([]byte, len(b), len(b)+1)
+	copy(rec, b)
+	t.ch <- bufferedEntry{b: append(rec, '\n'), flush: e.Level <= t.flushLevel}
+}
+
+// stop closes the channel over which entries are received.
+func (t *buffered) stop() {
+	tracef("buffered: transport requested to stop...")
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  Entries are written to
+// the buffer as they are received and the buffer is flushed when required
+// and at each flush interval (if there are any buffered entries).
+//
+// The run loop terminates when the channel over which entries are
+// received is closed, after writing any remaining entries and flushing
+// the buffer.
+func (t *buffered) run() {
+	buf := bufio.NewWriterSize(t.w, t.size)
+	flush := func() {
+		if buf.Buffered() == 0 {
+			return
+		}
+		if err := buf.Flush(); err != nil {
+			trace("buffered: entries discarded: " + err.Error())
+			buf.Reset(t.w)
+		}
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			flush()
+
+		case e, ok := <-t.ch:
+			if !ok {
+				flush()
+				tracef("buffered: transport stopped")
+				return
+			}
+			if _, err := buf.Write(e.b); err != nil {
+				trace("buffered: entries discarded: " + err.Error())
+				buf.Reset(t.w)
+				continue
+			}
+			if e.flush {
+				flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ulog
+
+import (
+	"fmt"
+	"time"
+)
+
+// BufferedFlushInterval configures the interval at which the buffer is
+// flushed.  The interval determines the maximum delay before an entry
+// below the flush level is written.  The default is 1 second.
+func BufferedFlushInterval(d time.Duration) BufferedOption {
+	return func(t *buffered) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: BufferedFlushInterval: must be > 0", ErrInvalidConfiguration)
+		}
+		t.interval = d
+		return nil
+	}
+}
+
+// BufferedFlushLevel configures the level at or above which writing an
+// entry causes the buffer to be flushed immediately.  The default is
+// ErrorLevel.
+func BufferedFlushLevel(lv Level) BufferedOption {
+	return func(t *buffered) error {
+		if lv < FatalLevel || lv > TraceLevel {
+			return fmt.Errorf("%w: BufferedFlushLevel: invalid level (%d)", ErrInvalidConfiguration, lv)
+		}
+		t.flushLevel = lv
+		return nil
+	}
+}
+
+// BufferedSize configures the size of the buffer, in bytes.  The buffer is
+// flushed when it is full; an entry larger than the buffer is written
+// directly.  The default is 64KiB.
+func BufferedSize(n int) BufferedOption {
+	return func(t *buffered) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: BufferedSize: must be > 0", ErrInvalidConfiguration)
+		}
+		t.size = n
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package ulog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestBufferedTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *buffered
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "BufferedFlushInterval",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BufferedFlushInterval(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.interval).Equals(time.Minute)
+			},
+		},
+		{scenario: "BufferedFlushInterval/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BufferedFlushInterval(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "BufferedFlushLevel",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BufferedFlushLevel(WarnLevel)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.flushLevel).Equals(WarnLevel)
+			},
+		},
+		{scenario: "BufferedFlushLevel/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					BufferedFlushLevel(levelNotSet)(sut),
+					BufferedFlushLevel(TraceLevel + 1)(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "BufferedSize",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BufferedSize(4096)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.size).Equals(4096)
+			},
+		},
+		{scenario: "BufferedSize/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := BufferedSize(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &buffered{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
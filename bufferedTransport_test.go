@@ -0,0 +1,246 @@
+package ulog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// writeRecorder is an io.Writer that records each write, signalling each
+// write on a channel (if configured).  If an error is configured, writes
+// fail with that error.
+type writeRecorder struct {
+	sync.Mutex
+	writes []string
+	err    error
+	ch     chan struct{}
+}
+
+func (w *writeRecorder) Write(b []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(b))
+	if w.ch != nil {
+		w.ch <- struct{}{}
+	}
+	return len(b), nil
+}
+
+func (w *writeRecorder) recorded() []string {
+	w.Lock()
+	defer w.Unlock()
+	return append([]string{}, w.writes...)
+}
+
+func TestBufferedTransport(t *testing.T) {
+	// ARRANGE
+	format := func(e entry) []byte { return []byte(e.Message) }
+
+	// start runs a buffered transport writing to a specified writer,
+	// returning a function that stops the transport and waits for the
+	// run loop to terminate
+	start := func(sut *buffered) func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			sut.run()
+		}()
+		return func() {
+			sut.stop()
+			<-done
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// BufferedTransport tests
+		{scenario: "BufferedTransport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &bytes.Buffer{}
+
+				// ACT
+				result, err := BufferedTransport(w)()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*buffered](t, result); ok {
+					test.That(t, result.w, "writer").Equals(io.Writer(w))
+					test.That(t, result.size, "size").Equals(64 * 1024)
+					test.That(t, result.interval, "interval").Equals(time.Second)
+					test.That(t, result.flushLevel, "flush level").Equals(ErrorLevel)
+				}
+			},
+		},
+		{scenario: "BufferedTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*buffered) error { return opterr }
+
+				// ACT
+				result, err := BufferedTransport(&bytes.Buffer{}, opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &buffered{ch: make(chan bufferedEntry, 2), flushLevel: WarnLevel}
+				b := []byte("entry")
+				format := func(entry) []byte { return b }
+
+				// ACT
+				sut.log(b)
+				sut.logEntry(entry{Level: InfoLevel}, format)
+				sut.logEntry(entry{Level: WarnLevel}, format)
+				b[0] = 'E'
+
+				// ASSERT
+				result := []bufferedEntry{<-sut.ch, <-sut.ch}
+				test.That(t, string(result[0].b)).Equals("entry\n")
+				test.IsFalse(t, result[0].flush)
+				test.IsTrue(t, result[1].flush)
+			},
+		},
+
+		// run tests
+		{scenario: "run/flushes when stopped",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &writeRecorder{}
+				sut := &buffered{ch: make(chan bufferedEntry, 10), w: w, size: 1024, interval: time.Hour, flushLevel: ErrorLevel}
+				stop := start(sut)
+
+				// ACT
+				sut.logEntry(entry{Level: InfoLevel, Message: "one"}, format)
+				sut.logEntry(entry{Level: InfoLevel, Message: "two"}, format)
+				stop()
+
+				// ASSERT
+				test.Slice(t, w.recorded()).Equals([]string{"one\ntwo\n"})
+			},
+		},
+		{scenario: "run/flushes at flush level",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &writeRecorder{ch: make(chan struct{}, 10)}
+				sut := &buffered{ch: make(chan bufferedEntry, 10), w: w, size: 1024, interval: time.Hour, flushLevel: ErrorLevel}
+				stop := start(sut)
+				defer stop()
+
+				// ACT
+				sut.logEntry(entry{Level: InfoLevel, Message: "info"}, format)
+				sut.logEntry(entry{Level: ErrorLevel, Message: "error"}, format)
+				<-w.ch
+
+				// ASSERT
+				test.Slice(t, w.recorded()).Equals([]string{"info\nerror\n"})
+			},
+		},
+		{scenario: "run/flushes at interval",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &writeRecorder{ch: make(chan struct{}, 10)}
+				sut := &buffered{ch: make(chan bufferedEntry, 10), w: w, size: 1024, interval: 10 * time.Millisecond, flushLevel: ErrorLevel}
+				stop := start(sut)
+				defer stop()
+
+				// ACT
+				sut.logEntry(entry{Level: InfoLevel, Message: "info"}, format)
+				<-w.ch
+
+				// ASSERT
+				test.Slice(t, w.recorded()).Equals([]string{"info\n"})
+			},
+		},
+		{scenario: "run/flushes when full",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &writeRecorder{ch: make(chan struct{}, 10)}
+				sut := &buffered{ch: make(chan bufferedEntry, 10), w: w, size: 6, interval: time.Hour, flushLevel: ErrorLevel}
+				stop := start(sut)
+				defer stop()
+
+				// ACT
+				sut.logEntry(entry{Level: InfoLevel, Message: "one"}, format)
+				sut.logEntry(entry{Level: InfoLevel, Message: "two"}, format)
+				<-w.ch
+
+				// ASSERT
+				test.Slice(t, w.recorded()).Equals([]string{"one\ntw"})
+			},
+		},
+		{scenario: "run/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+
+				w := &writeRecorder{err: errors.New("write error")}
+				sut := &buffered{ch: make(chan bufferedEntry, 10), w: w, size: 4, interval: time.Hour, flushLevel: ErrorLevel}
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.logEntry(entry{Level: InfoLevel, Message: "entry"}, format)
+					sut.logEntry(entry{Level: ErrorLevel, Message: "error"}, format)
+					sut.stop()
+					sut.run()
+				})
+
+				// ASSERT
+				stderr.Contains("buffered: entries discarded: write error")
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "writes entries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &writeRecorder{}
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(LogfmtFormatter()),
+							TargetTransport(BufferedTransport(w, BufferedFlushInterval(time.Hour))),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("one")
+				logger.Info("two")
+				closelog()
+
+				// ASSERT
+				result := w.recorded()
+				test.That(t, len(result)).Equals(1)
+				test.That(t, bytes.Count([]byte(result[0]), []byte("\n"))).Equals(2)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
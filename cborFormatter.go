@@ -0,0 +1,330 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type CBOROption func(*cborfmt) error // CBOROption is a function for configuring a CBOR formatter
+
+// CBORTimeFormatType identifies the encoding of the time of entries in CBOR
+// formatted log entries.
+type CBORTimeFormatType int
+
+const (
+	CBORTimeEpoch   CBORTimeFormatType = iota // CBORTimeEpoch encodes time as seconds since the unix epoch (tag 1)
+	CBORTimeRFC3339                           // CBORTimeRFC3339 encodes time as an RFC3339 string (tag 0)
+)
+
+// CBOR major types (RFC 8949, section 3.1)
+const (
+	cborUint   byte = 0 << 5
+	cborNegInt byte = 1 << 5
+	cborBytes  byte = 2 << 5
+	cborString byte = 3 << 5
+	cborArray  byte = 4 << 5
+	cborMap    byte = 5 << 5
+	cborTag    byte = 6 << 5
+)
+
+// CBOR simple values and floating-point initial bytes
+const (
+	cborFalse   byte = 0xf4
+	cborTrue    byte = 0xf5
+	cborNull    byte = 0xf6
+	cborFloat32 byte = 0xfa
+	cborFloat64 byte = 0xfb
+)
+
+// CBOR tags for date/time values
+const (
+	cborTagDateTime  = 0 // standard date/time string (RFC3339)
+	cborTagEpochTime = 1 // epoch-based date/time
+)
+
+// CBORFormatter returns a function that configures a formatter that writes
+// log entries as CBOR (RFC 8949) encoded maps, with specified configuration
+// options applied.
+//
+// Each entry is encoded as a map with the time, level and message of the
+// entry, the callsite (if enabled) and the fields of the entry.  The time
+// is encoded as a tag 1 (epoch) or tag 0 (RFC3339) date/time (see:
+// CBORTimeFormat).  Errors are encoded as the Error() string and structs as
+// maps, using their JSON representation.
+//
+// The keys of a CBOR map must be unique; a field with the same name as the
+// key of a core field (see: CBORKeys) is omitted.
+func CBORFormatter(opts ...CBOROption) FormatterFactory {
+	return func() (Formatter, error) {
+		cf := &cborfmt{}
+		cf.init()
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(cf))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		return cf, nil
+	}
+}
+
+func (f *cborfmt) init() {
+	f.names = [numFields]string{
+		LevelField:            "level",
+		MessageField:          "message",
+		TimeField:             "timestamp",
+		CallsiteFileField:     "file",
+		CallsiteFunctionField: "function",
+	}
+	for k, v := range f.names {
+		f.keys[k] = cborAppendString(nil, v)
+	}
+	f.levels = [numLevels][]byte{
+		TraceLevel: cborAppendString(nil, "trace"),
+		DebugLevel: cborAppendString(nil, "debug"),
+		InfoLevel:  cborAppendString(nil, "info"),
+		WarnLevel:  cborAppendString(nil, "warning"),
+		ErrorLevel: cborAppendString(nil, "error"),
+		FatalLevel: cborAppendString(nil, "fatal"),
+	}
+}
+
+type cborfmt struct {
+	names      [numFields]string  // slice indexed by ord(Key) of the names of the core fields
+	keys       [numFields][]byte  // slice indexed by ord(Key) to pre-encoded byte slices
+	levels     [numLevels][]byte  // slice indexed by level of pre-encoded byte slices
+	timeFormat CBORTimeFormatType // the encoding of the time of entries
+}
+
+// cborAppendHead appends the head of a data item with a specified major
+// type and argument, using the shortest encoding of the argument.
+func cborAppendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+// cborAppendString appends a text string.
+func cborAppendString(b []byte, s string) []byte {
+	b = cborAppendHead(b, cborString, uint64(len(s)))
+	return append(b, s...)
+}
+
+// cborAppendInt appends a signed integer.
+func cborAppendInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return cborAppendHead(b, cborNegInt, uint64(-(i + 1)))
+	}
+	return cborAppendHead(b, cborUint, uint64(i))
+}
+
+// cborAppendFloat appends a double-precision float.
+func cborAppendFloat(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, cborFloat64), math.Float64bits(f))
+}
+
+// appendTime appends a time as a tagged date/time.  An epoch time with no
+// fractional seconds is encoded as an integer, otherwise as a float.
+func (f *cborfmt) appendTime(b []byte, t time.Time) []byte {
+	if f.timeFormat == CBORTimeRFC3339 {
+		b = cborAppendHead(b, cborTag, cborTagDateTime)
+		return cborAppendString(b, t.Format(time.RFC3339Nano))
+	}
+
+	b = cborAppendHead(b, cborTag, cborTagEpochTime)
+	if t.Nanosecond() == 0 {
+		return cborAppendInt(b, t.Unix())
+	}
+	return cborAppendFloat(b, float64(t.UnixNano())/1e9)
+}
+
+// appendValue appends an arbitrary value.
+func (f *cborfmt) appendValue(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, cborNull)
+	case string:
+		return cborAppendString(b, v)
+	case []byte:
+		b = cborAppendHead(b, cborBytes, uint64(len(v)))
+		return append(b, v...)
+	case bool:
+		if v {
+			return append(b, cborTrue)
+		}
+		return append(b, cborFalse)
+	case float32:
+		return binary.BigEndian.AppendUint32(append(b, cborFloat32), math.Float32bits(v))
+	case time.Time:
+		return f.appendTime(b, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return cborAppendInt(b, i)
+		}
+		fv, _ := v.Float64()
+		return cborAppendFloat(b, fv)
+	case error:
+		return cborAppendString(b, v.Error())
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cborAppendInt(b, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cborAppendHead(b, cborUint, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cborAppendFloat(b, rv.Float())
+	case reflect.String:
+		return cborAppendString(b, rv.String())
+	case reflect.Bool:
+		return f.appendValue(b, rv.Bool())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return append(b, cborNull)
+		}
+		b = cborAppendHead(b, cborArray, uint64(rv.Len()))
+		for i := 0; i < rv.Len(); i++ {
+			b = f.appendValue(b, rv.Index(i).Interface())
+		}
+		return b
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return append(b, cborNull)
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		slices.Sort(keys)
+		b = cborAppendHead(b, cborMap, uint64(len(keys)))
+		for _, k := range keys {
+			b = cborAppendString(b, k)
+			b = f.appendValue(b, rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface())
+		}
+		return b
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return append(b, cborNull)
+		}
+		if rv.Elem().Kind() == reflect.Struct {
+			return f.appendStruct(b, v)
+		}
+		return f.appendValue(b, rv.Elem().Interface())
+	case reflect.Struct:
+		return f.appendStruct(b, v)
+	}
+	return cborAppendString(b, fmt.Sprintf("%v", v))
+}
+
+// appendStruct appends a struct as a map, using the JSON representation of
+// the struct.
+func (f *cborfmt) appendStruct(b []byte, v any) []byte {
+	j, err := jsonMarshal(v)
+	if err != nil {
+		return cborAppendString(b, fmt.Sprintf("CBOR_ERROR: marshalling error: %v", err))
+	}
+
+	// decode the json (no need to check for errors; we are decoding
+	// marshalled JSON, it cannot be invalid), preserving numbers
+	var m any
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	_ = dec.Decode(&m)
+
+	return f.appendValue(b, m)
+}
+
+// Format implements a Formatter that writes log entries as CBOR encoded
+// maps.
+func (f *cborfmt) Format(id int, e entry, b ByteWriter) {
+	var fields *fields
+	if e.logcontext != nil {
+		fields = e.fields
+	}
+
+	n := 3
+	if e.callsite != nil {
+		n += 2
+	}
+	if fields != nil {
+		n += len(fields.m)
+		for _, k := range f.names {
+			if _, ok := fields.m[k]; ok {
+				n--
+			}
+		}
+	}
+
+	buf := make([]byte, 0, 128+len(e.Message))
+	buf = cborAppendHead(buf, cborMap, uint64(n))
+
+	buf = append(buf, f.keys[TimeField]...)
+	buf = f.appendTime(buf, e.Time)
+
+	buf = append(buf, f.keys[LevelField]...)
+	buf = append(buf, f.levels[e.Level]...)
+
+	buf = append(buf, f.keys[MessageField]...)
+	buf = cborAppendString(buf, e.Message)
+
+	if e.callsite != nil {
+		buf = append(buf, f.keys[CallsiteFunctionField]...)
+		buf = cborAppendString(buf, e.callsite.function)
+		buf = append(buf, f.keys[CallsiteFileField]...)
+		buf = cborAppendString(buf, e.callsite.file+":"+strconv.Itoa(e.callsite.line))
+	}
+	_, _ = b.Write(buf)
+
+	fbb := fields.getFormattedBytes(id)
+	if fbb == nil { // nil => no fields
+		return
+	}
+
+	if fbb.Len() > 0 { // cached cbor encoding retrieved
+		_, _ = b.Write(fbb.Bytes())
+		return
+	}
+
+	// encode the fields, sorted by name, omitting any with the name of a
+	// core field
+	keys := make([]string, 0, len(fields.m))
+	for k := range fields.m {
+		if !slices.Contains(f.names[:], k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	fb := make([]byte, 0, 64*len(keys))
+	for _, k := range keys {
+		fb = cborAppendString(fb, k)
+		fb = f.appendValue(fb, fields.m[k])
+	}
+	fields.setFormattedBytes(id, fb)
+
+	_, _ = b.Write(fb)
+}
@@ -0,0 +1,62 @@
+package ulog
+
+import "fmt"
+
+// CBORKeys configures the keys used for the each of the core
+// fields in a log entry: time, level, message, file and function.
+//
+// A map[FieldId]string is used to override the default label for each
+// field that is required; if a field is not included in the map, the
+// default label will continue to be used for that field.
+//
+// The default labels for each field are:
+//
+//	TimeField:              timestamp
+//	LevelField:             level
+//	MessageField:           message
+//	CallsiteFileField:      file
+//	CallsiteFunctionField:  function
+//
+// Although the label for each field may be configured, the inclusion
+// of these fields and their order is fixed, and cannot be changed.
+func CBORKeys(keys map[FieldId]string) CBOROption {
+	return func(cf *cborfmt) error {
+		for k, v := range keys {
+			cf.names[k] = v
+			cf.keys[k] = cborAppendString(nil, v)
+		}
+		return nil
+	}
+}
+
+// CBORLevels configures the values used for the Level field
+// in CBOR formatted log entries.
+func CBORLevels(levels map[Level]string) CBOROption {
+	return func(cf *cborfmt) error {
+		for k, v := range levels {
+			if k <= levelNotSet || k >= numLevels {
+				return fmt.Errorf("%w: CBORLevels: invalid level (%d)", ErrInvalidConfiguration, int(k))
+			}
+			cf.levels[k] = cborAppendString(nil, v)
+		}
+		return nil
+	}
+}
+
+// CBORTimeFormat configures the encoding of the time of entries:
+//
+//	CBORTimeEpoch     tag 1; seconds since the unix epoch (integer or float)
+//	CBORTimeRFC3339   tag 0; an RFC3339 string
+//
+// The default is CBORTimeEpoch.
+func CBORTimeFormat(tf CBORTimeFormatType) CBOROption {
+	return func(cf *cborfmt) error {
+		switch tf {
+		case CBORTimeEpoch, CBORTimeRFC3339:
+			cf.timeFormat = tf
+			return nil
+		default:
+			return fmt.Errorf("%w: CBORTimeFormat: invalid format (%d)", ErrInvalidConfiguration, tf)
+		}
+	}
+}
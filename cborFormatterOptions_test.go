@@ -0,0 +1,79 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestCBORFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *cborfmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "CBORKeys",
+			exec: func(t *testing.T) {
+				// ACT
+				err := CBORKeys(map[FieldId]string{MessageField: "msg"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.names[MessageField]).Equals("msg")
+				test.That(t, sut.keys[MessageField]).Equals([]byte("cmsg"))
+				test.That(t, sut.keys[LevelField]).Equals([]byte("elevel"))
+			},
+		},
+		{scenario: "CBORLevels",
+			exec: func(t *testing.T) {
+				// ACT
+				err := CBORLevels(map[Level]string{WarnLevel: "warn"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.levels[WarnLevel]).Equals([]byte("dwarn"))
+				test.That(t, sut.levels[InfoLevel]).Equals([]byte("dinfo"))
+			},
+		},
+		{scenario: "CBORLevels/invalid level",
+			exec: func(t *testing.T) {
+				// ACT
+				err := CBORLevels(map[Level]string{Level(9): "x"})(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "CBORTimeFormat",
+			exec: func(t *testing.T) {
+				// ACT
+				err := CBORTimeFormat(CBORTimeRFC3339)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.timeFormat).Equals(CBORTimeRFC3339)
+			},
+		},
+		{scenario: "CBORTimeFormat/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := CBORTimeFormat(CBORTimeFormatType(-1))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &cborfmt{}
+			sut.init()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// cborHex returns the bytes of a hex string, ignoring spaces.
+func cborHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestCBORFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Unix(1363896240, 0).UTC()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// CBORFormatter tests
+		{scenario: "CBORFormatter",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := CBORFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*cborfmt).keys[TimeField]).Equals(cborHex("69 74696d657374616d70"))
+				test.That(t, result.(*cborfmt).levels[InfoLevel]).Equals(cborHex("64 696e666f"))
+				test.That(t, result.(*cborfmt).timeFormat).Equals(CBORTimeEpoch)
+			},
+		},
+		{scenario: "CBORFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := CBORFormatter(func(*cborfmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// Format tests
+		{scenario: "Format",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &cborfmt{}
+				sut.init()
+				buf := &bytes.Buffer{}
+				e := entry{Time: tm, Level: InfoLevel, Message: "msg", logcontext: &logcontext{}}
+
+				// ACT
+				sut.Format(0, e, buf)
+
+				// ASSERT
+				test.That(t, buf.Bytes()).Equals(cborHex(
+					"a3" + // map(3)
+						"69 74696d657374616d70 c1 1a 514b67b0" + // "timestamp": 1(1363896240)
+						"65 6c6576656c 64 696e666f" + // "level": "info"
+						"67 6d657373616765 63 6d7367", // "message": "msg"
+				))
+			},
+		},
+		{scenario: "Format/callsite",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &cborfmt{}
+				sut.init()
+				buf := &bytes.Buffer{}
+				e := entry{Time: tm, Level: InfoLevel, Message: "msg",
+					callsite:   &callsite{file: "f.go", line: 1, function: "fn"},
+					logcontext: &logcontext{},
+				}
+
+				// ACT
+				sut.Format(0, e, buf)
+
+				// ASSERT
+				test.IsTrue(t, bytes.HasPrefix(buf.Bytes(), cborHex("a5")))
+				test.IsTrue(t, bytes.HasSuffix(buf.Bytes(), cborHex(
+					"68 66756e6374696f6e 62 666e"+ // "function": "fn"
+						"64 66696c65 66 662e676f3a31", // "file": "f.go:1"
+				)))
+			},
+		},
+		{scenario: "Format/fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &cborfmt{}
+				sut.init()
+				buf := &bytes.Buffer{}
+				e := entry{Time: tm, Level: InfoLevel, Message: "msg",
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"b":   errors.New("e"),
+						"a":   1,
+						"obj": struct{ ID int }{ID: 2},
+					})},
+				}
+
+				// ACT
+				sut.Format(1, e, buf)
+
+				// ASSERT
+				fields := cborHex(
+					"61 61 01" + // "a": 1
+						"61 62 61 65" + // "b": "e"
+						"63 6f626a a1 62 4944 02", // "obj": {"ID": 2}
+				)
+				test.IsTrue(t, bytes.HasPrefix(buf.Bytes(), cborHex("a6")))
+				test.IsTrue(t, bytes.HasSuffix(buf.Bytes(), fields))
+				test.That(t, e.fields.b[1]).Equals(fields)
+			},
+		},
+		{scenario: "Format/fields/core field names",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &cborfmt{}
+				sut.init()
+				buf := &bytes.Buffer{}
+				e := entry{Time: tm, Level: InfoLevel, Message: "msg",
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"a":       1,
+						"message": "other",
+						"file":    "other.go",
+					})},
+				}
+
+				// ACT
+				sut.Format(1, e, buf)
+
+				// ASSERT
+				test.IsTrue(t, bytes.HasPrefix(buf.Bytes(), cborHex("a4")))
+				test.IsTrue(t, bytes.HasSuffix(buf.Bytes(), cborHex("63 6d7367 61 61 01"))) // "msg", "a": 1
+				test.That(t, e.fields.b[1]).Equals(cborHex("61 61 01"))
+			},
+		},
+		{scenario: "Format/fields/cached",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &cborfmt{}
+				sut.init()
+				buf := &bytes.Buffer{}
+				e := entry{Time: tm, Level: InfoLevel, Message: "msg",
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"a": 1})},
+				}
+				e.fields.setFormattedBytes(1, cborHex("61 61 02"))
+
+				// ACT
+				sut.Format(1, e, buf)
+
+				// ASSERT
+				test.IsTrue(t, bytes.HasSuffix(buf.Bytes(), cborHex("61 61 02")))
+			},
+		},
+		{scenario: "Format/keys and levels",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := CBORFormatter(
+					CBORKeys(map[FieldId]string{TimeField: "t", LevelField: "l", MessageField: "m"}),
+					CBORLevels(map[Level]string{InfoLevel: "I"}),
+					CBORTimeFormat(CBORTimeRFC3339),
+				)()
+				buf := &bytes.Buffer{}
+				e := entry{Time: tm, Level: InfoLevel, Message: "msg", logcontext: &logcontext{}}
+
+				// ACT
+				f.Format(0, e, buf)
+
+				// ASSERT
+				test.That(t, buf.Bytes()).Equals(cborHex(
+					"a3" +
+						"61 74 c0 74 323031332d30332d32315432303a30343a30305a" + // "t": 0("2013-03-21T20:04:00Z")
+						"61 6c 61 49" + // "l": "I"
+						"61 6d 63 6d7367", // "m": "msg"
+				))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestCBORValues(t *testing.T) {
+	// ARRANGE
+	type named string
+	type point struct {
+		X int     `json:"x"`
+		Y float64 `json:"y"`
+	}
+	sut := &cborfmt{}
+
+	// examples from RFC 8949, Appendix A, and others
+	testcases := []struct {
+		scenario string
+		value    any
+		result   string
+	}{
+		{scenario: "0", value: 0, result: "00"},
+		{scenario: "23", value: 23, result: "17"},
+		{scenario: "24", value: uint8(24), result: "1818"},
+		{scenario: "100", value: int16(100), result: "1864"},
+		{scenario: "1000", value: uint(1000), result: "1903e8"},
+		{scenario: "1000000", value: int32(1000000), result: "1a000f4240"},
+		{scenario: "1000000000000", value: int64(1000000000000), result: "1b000000e8d4a51000"},
+		{scenario: "-1", value: -1, result: "20"},
+		{scenario: "-100", value: -100, result: "3863"},
+		{scenario: "-1000", value: -1000, result: "3903e7"},
+		{scenario: "1.1", value: 1.1, result: "fb3ff199999999999a"},
+		{scenario: "float32", value: float32(100000.0), result: "fa47c35000"},
+		{scenario: "false", value: false, result: "f4"},
+		{scenario: "true", value: true, result: "f5"},
+		{scenario: "nil", value: nil, result: "f6"},
+		{scenario: "empty string", value: "", result: "60"},
+		{scenario: "string", value: "IETF", result: "6449455446"},
+		{scenario: "named string", value: named("a"), result: "6161"},
+		{scenario: "bytes", value: []byte{1, 2, 3, 4}, result: "4401020304"},
+		{scenario: "array", value: []int{1, 2, 3}, result: "83010203"},
+		{scenario: "nil slice", value: []int(nil), result: "f6"},
+		{scenario: "map", value: map[string]any{"b": []int{2, 3}, "a": 1}, result: "a26161016162820203"},
+		{scenario: "duration", value: time.Second, result: "1a3b9aca00"},
+		{scenario: "time", value: time.Unix(1363896240, 0), result: "c11a514b67b0"},
+		{scenario: "time/fractional", value: time.Unix(1363896240, 500000000), result: "c1fb41d452d9ec200000"},
+		{scenario: "error", value: errors.New("e"), result: "6165"},
+		{scenario: "struct", value: point{X: 1, Y: 1.5}, result: "a2 6178 01 6179 fb3ff8000000000000"},
+		{scenario: "struct pointer", value: &point{X: 1}, result: "a2 6178 01 6179 00"},
+		{scenario: "nil pointer", value: (*point)(nil), result: "f6"},
+		{scenario: "pointer", value: &[]int{1}, result: "8101"},
+		{scenario: "unsupported", value: map[int]int{1: 2}, result: "68 6d61705b313a325d"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			result := sut.appendValue(nil, tc.value)
+
+			// ASSERT
+			test.That(t, hex.EncodeToString(result)).Equals(strings.ReplaceAll(tc.result, " ", ""))
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type ConsoleFormatterOption func(*consolefmt) error // ConsoleFormatterOption is a function for configuring a console formatter
+
+// ConsoleColorMode determines whether a console formatter uses colours.
+type ConsoleColorMode int
+
+const (
+	ConsoleColorAuto   ConsoleColorMode = iota // ConsoleColorAuto uses colours if the output is a terminal and NO_COLOR is not set
+	ConsoleColorAlways                         // ConsoleColorAlways always uses colours
+	ConsoleColorNever                          // ConsoleColorNever never uses colours
+)
+
+// ANSI escape sequences used by the console formatter
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+)
+
+// consoleLevels are the (fixed width) labels for each Level.
+var consoleLevels = [numLevels]string{
+	TraceLevel: "TRACE",
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO ",
+	WarnLevel:  "WARN ",
+	ErrorLevel: "ERROR",
+	FatalLevel: "FATAL",
+}
+
+// consoleColors are the ANSI escape sequences for the colour of each Level.
+var consoleColors = [numLevels]string{
+	TraceLevel: "\x1b[90m",   // grey
+	DebugLevel: "\x1b[36m",   // cyan
+	InfoLevel:  "\x1b[32m",   // green
+	WarnLevel:  "\x1b[33m",   // yellow
+	ErrorLevel: "\x1b[31m",   // red
+	FatalLevel: "\x1b[1;31m", // bold red
+}
+
+// isTerminal returns true if a specified file is a terminal (character
+// device).
+var isTerminal = func(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ConsoleFormatter returns a function that configures a formatter that
+// writes log entries in a human-friendly format for reading in a terminal,
+// e.g. during local development, with specified configuration options
+// applied.  Entries are written similar to:
+//
+//	15:04:05.000 INFO  [pkg/file.go:42] message                      key=value n=1
+//
+// The time of the entry is written in local time (see: ConsoleTimeFormat),
+// followed by the level, the callsite (if enabled) as a short path (the
+// file and its parent directory) and the message.  Fields are written after
+// the message, sorted by name; if the entry has fields, the message is
+// padded to a minimum width so that fields are aligned (see:
+// ConsoleMessageWidth).
+//
+// Values that span multiple lines, errors that provide more detail when
+// formatted with %+v (e.g. a stack trace) and any lines after the first
+// line of the message are written indented on the lines following the
+// entry:
+//
+//	15:04:05.000 ERROR request failed                           id=42
+//	    err:
+//	        failed
+//	            main.main
+//	                /src/main.go:42
+//
+// Levels are coloured and field names dimmed using ANSI escape sequences.
+// By default, colours are used only if the output is a terminal (see:
+// ConsoleTTY) and the NO_COLOR environment variable is not set to a
+// non-empty value (see: https://no-color.org); this may be changed using
+// ConsoleColors.
+func ConsoleFormatter(opts ...ConsoleFormatterOption) FormatterFactory {
+	return func() (Formatter, error) {
+		cf := &consolefmt{
+			tty:          os.Stdout,
+			timeFormat:   "15:04:05.000",
+			messageWidth: 40,
+		}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(cf))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		switch cf.mode {
+		case ConsoleColorAlways:
+			cf.color = true
+		case ConsoleColorAuto:
+			cf.color = os.Getenv("NO_COLOR") == "" && isTerminal(cf.tty)
+		}
+
+		return cf, nil
+	}
+}
+
+type consolefmt struct {
+	mode         ConsoleColorMode // the configured colour mode
+	color        bool             // true if colours are used (resolved from the colour mode)
+	tty          *os.File         // the file checked to determine whether output is a terminal
+	timeFormat   string           // the layout of the time of entries
+	messageWidth int              // the minimum width of messages followed by fields
+}
+
+// consoleBlock is a multi-line value written on the lines following an
+// entry.
+type consoleBlock struct {
+	key   string
+	lines []string
+}
+
+// consoleShortPath returns a file path reduced to the file and its parent
+// directory.
+func consoleShortPath(path string) string {
+	i := strings.LastIndexByte(path, '/')
+	if i <= 0 {
+		return path
+	}
+	if j := strings.LastIndexByte(path[:i], '/'); j >= 0 {
+		return path[j+1:]
+	}
+	return path
+}
+
+// consoleQuote returns a string, quoted if it is empty or contains any
+// spaces, quotes, '=' or non-printable characters.
+func consoleQuote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \"=") || strconv.Quote(s) != `"`+s+`"` {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// consoleValue returns the string representation of a field value and
+// true if the value spans multiple lines.
+func consoleValue(v any) (string, bool) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+		if detail := fmt.Sprintf("%+v", v); detail != s {
+			s = detail
+		}
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Struct || (rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct) {
+			if j, err := jsonMarshal(v); err == nil {
+				return string(j), false
+			}
+		}
+		s = fmt.Sprintf("%v", v)
+	}
+	if strings.Contains(s, "\n") {
+		return strings.TrimRight(s, "\n"), true
+	}
+	return consoleQuote(s), false
+}
+
+// write writes a string, with a specified colour (ANSI escape sequence) if
+// colours are enabled.
+func (w *consolefmt) write(b ByteWriter, color string, s string) {
+	if w.color && color != "" {
+		_, _ = b.Write([]byte(color + s + ansiReset))
+		return
+	}
+	_, _ = b.Write([]byte(s))
+}
+
+// Format implements a Formatter that writes log entries for reading in a
+// terminal.
+func (w *consolefmt) Format(id int, e entry, b ByteWriter) {
+	w.write(b, ansiDim, e.Time.Local().Format(w.timeFormat))
+	_ = b.WriteByte(' ')
+	w.write(b, consoleColors[e.Level], consoleLevels[e.Level])
+
+	if e.callsite != nil {
+		_ = b.WriteByte(' ')
+		w.write(b, ansiDim, "["+consoleShortPath(e.callsite.file)+":"+strconv.Itoa(e.callsite.line)+"]")
+	}
+
+	blocks := []consoleBlock{}
+	msg, more, multiline := strings.Cut(e.Message, "\n")
+	if multiline {
+		blocks = append(blocks, consoleBlock{lines: strings.Split(strings.TrimRight(more, "\n"), "\n")})
+	}
+	_ = b.WriteByte(' ')
+	_, _ = b.Write([]byte(msg))
+
+	if e.logcontext == nil || e.fields == nil || len(e.fields.m) == 0 {
+		w.writeBlocks(b, blocks)
+		return
+	}
+
+	keys := make([]string, 0, len(e.fields.m))
+	for k := range e.fields.m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	padded := false
+	for _, k := range keys {
+		s, multiline := consoleValue(e.fields.m[k])
+		if multiline {
+			blocks = append(blocks, consoleBlock{key: k, lines: strings.Split(s, "\n")})
+			continue
+		}
+		if !padded {
+			if n := w.messageWidth - utf8.RuneCountInString(msg); n > 0 {
+				_, _ = b.Write([]byte(strings.Repeat(" ", n)))
+			}
+			padded = true
+		}
+		_ = b.WriteByte(' ')
+		w.write(b, ansiDim, k+"=")
+		_, _ = b.Write([]byte(s))
+	}
+
+	w.writeBlocks(b, blocks)
+}
+
+// writeBlocks writes multi-line values on the lines following an entry,
+// indented.
+func (w *consolefmt) writeBlocks(b ByteWriter, blocks []consoleBlock) {
+	for _, block := range blocks {
+		indent := "\n    "
+		if block.key != "" {
+			_, _ = b.Write([]byte(indent))
+			w.write(b, ansiDim, block.key+":")
+			indent = "\n        "
+		}
+		for _, line := range block.lines {
+			_, _ = b.Write([]byte(indent + line))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ulog
+
+import (
+	"fmt"
+	"os"
+)
+
+// ConsoleColors configures whether colours are used.  The default is
+// ConsoleColorAuto.
+func ConsoleColors(mode ConsoleColorMode) ConsoleFormatterOption {
+	return func(cf *consolefmt) error {
+		switch mode {
+		case ConsoleColorAuto, ConsoleColorAlways, ConsoleColorNever:
+			cf.mode = mode
+			return nil
+		default:
+			return fmt.Errorf("%w: ConsoleColors: invalid mode (%d)", ErrInvalidConfiguration, mode)
+		}
+	}
+}
+
+// ConsoleMessageWidth configures the minimum width of a message that is
+// followed by fields; shorter messages are padded so that fields are
+// aligned.  A width of 0 disables padding.  The default is 40.
+func ConsoleMessageWidth(n int) ConsoleFormatterOption {
+	return func(cf *consolefmt) error {
+		if n < 0 {
+			return fmt.Errorf("%w: ConsoleMessageWidth: %d: must be >= 0", ErrInvalidConfiguration, n)
+		}
+		cf.messageWidth = n
+		return nil
+	}
+}
+
+// ConsoleTimeFormat configures the layout (see: time.Layout) of the time of
+// entries, written in local time.  The default is "15:04:05.000".
+func ConsoleTimeFormat(layout string) ConsoleFormatterOption {
+	return func(cf *consolefmt) error {
+		if layout == "" {
+			return fmt.Errorf("%w: ConsoleTimeFormat: layout is required", ErrInvalidConfiguration)
+		}
+		cf.timeFormat = layout
+		return nil
+	}
+}
+
+// ConsoleTTY configures the file that is checked to determine whether the
+// output is a terminal when using ConsoleColorAuto, e.g. os.Stderr if the
+// logger is configured to write to os.Stderr.  The default is os.Stdout.
+func ConsoleTTY(f *os.File) ConsoleFormatterOption {
+	return func(cf *consolefmt) error {
+		if f == nil {
+			return fmt.Errorf("%w: ConsoleTTY: file is nil", ErrInvalidConfiguration)
+		}
+		cf.tty = f
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package ulog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestConsoleFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *consolefmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ConsoleColors",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleColors(ConsoleColorNever)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.mode).Equals(ConsoleColorNever)
+			},
+		},
+		{scenario: "ConsoleColors/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleColors(ConsoleColorMode(-1))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ConsoleMessageWidth",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleMessageWidth(20)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.messageWidth).Equals(20)
+			},
+		},
+		{scenario: "ConsoleMessageWidth/negative",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleMessageWidth(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ConsoleTimeFormat",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleTimeFormat("15:04")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.timeFormat).Equals("15:04")
+			},
+		},
+		{scenario: "ConsoleTimeFormat/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleTimeFormat("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ConsoleTTY",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleTTY(os.Stderr)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tty).Equals(os.Stderr)
+			},
+		},
+		{scenario: "ConsoleTTY/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ConsoleTTY(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &consolefmt{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
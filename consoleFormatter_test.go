@@ -0,0 +1,254 @@
+package ulog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// detailError is an error that provides more detail when formatted using
+// %+v.
+type detailError struct{}
+
+func (detailError) Error() string { return "failed" }
+
+func (e detailError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = s.Write([]byte("failed\n    main.main\n        /src/main.go:42"))
+		return
+	}
+	_, _ = s.Write([]byte(e.Error()))
+}
+
+func TestConsoleFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.Local)
+
+	// format returns the formatted entry as a string
+	format := func(sut *consolefmt, e entry) string {
+		buf := &bytes.Buffer{}
+		sut.Format(0, e, buf)
+		return buf.String()
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// ConsoleFormatter tests
+		{scenario: "ConsoleFormatter",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&isTerminal, func(*os.File) bool { return false })()
+
+				// ACT
+				result, err := ConsoleFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*consolefmt)).Equals(&consolefmt{
+					tty:          os.Stdout,
+					timeFormat:   "15:04:05.000",
+					messageWidth: 40,
+				})
+			},
+		},
+		{scenario: "ConsoleFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := ConsoleFormatter(func(*consolefmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "ConsoleFormatter/auto/terminal",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&isTerminal, func(*os.File) bool { return true })()
+				t.Setenv("NO_COLOR", "")
+
+				// ACT
+				result, _ := ConsoleFormatter()()
+
+				// ASSERT
+				test.IsTrue(t, result.(*consolefmt).color)
+			},
+		},
+		{scenario: "ConsoleFormatter/auto/terminal/NO_COLOR",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&isTerminal, func(*os.File) bool { return true })()
+				t.Setenv("NO_COLOR", "1")
+
+				// ACT
+				result, _ := ConsoleFormatter()()
+
+				// ASSERT
+				test.IsFalse(t, result.(*consolefmt).color)
+			},
+		},
+		{scenario: "ConsoleFormatter/auto/not a terminal",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&isTerminal, func(*os.File) bool { return false })()
+				t.Setenv("NO_COLOR", "")
+
+				// ACT
+				result, _ := ConsoleFormatter()()
+
+				// ASSERT
+				test.IsFalse(t, result.(*consolefmt).color)
+			},
+		},
+		{scenario: "ConsoleFormatter/always",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&isTerminal, func(*os.File) bool { return false })()
+				t.Setenv("NO_COLOR", "1")
+
+				// ACT
+				result, _ := ConsoleFormatter(ConsoleColors(ConsoleColorAlways))()
+
+				// ASSERT
+				test.IsTrue(t, result.(*consolefmt).color)
+			},
+		},
+		{scenario: "ConsoleFormatter/never",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&isTerminal, func(*os.File) bool { return true })()
+				t.Setenv("NO_COLOR", "")
+
+				// ACT
+				result, _ := ConsoleFormatter(ConsoleColors(ConsoleColorNever))()
+
+				// ASSERT
+				test.IsFalse(t, result.(*consolefmt).color)
+			},
+		},
+
+		// Format tests
+		{scenario: "Format",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &consolefmt{timeFormat: "15:04:05.000", messageWidth: 10}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				result := format(sut, e)
+
+				// ASSERT
+				test.That(t, result).Equals("07:06:05.432 INFO  message")
+			},
+		},
+		{scenario: "Format/callsite",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &consolefmt{timeFormat: "15:04:05.000"}
+				e := entry{Time: tm, Level: WarnLevel, Message: "message",
+					callsite: &callsite{file: "/src/project/pkg/file.go", line: 42, function: "pkg.fn"},
+				}
+
+				// ACT
+				result := format(sut, e)
+
+				// ASSERT
+				test.That(t, result).Equals("07:06:05.432 WARN  [pkg/file.go:42] message")
+			},
+		},
+		{scenario: "Format/fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &consolefmt{timeFormat: "15:04:05.000", messageWidth: 10}
+				e := entry{Time: tm, Level: DebugLevel, Message: "message",
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"b":      1,
+						"a":      "text with spaces",
+						"err":    errors.New("error"),
+						"struct": struct{ ID int }{ID: 42},
+						"empty":  "",
+					})},
+				}
+
+				// ACT
+				result := format(sut, e)
+
+				// ASSERT
+				test.That(t, result).Equals(`07:06:05.432 DEBUG message    a="text with spaces" b=1 empty="" err=error struct={"ID":42}`)
+			},
+		},
+		{scenario: "Format/fields/long message",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &consolefmt{timeFormat: "15:04:05.000", messageWidth: 4}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message",
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"a": 1})},
+				}
+
+				// ACT
+				result := format(sut, e)
+
+				// ASSERT
+				test.That(t, result).Equals("07:06:05.432 INFO  message a=1")
+			},
+		},
+		{scenario: "Format/multi-line values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &consolefmt{timeFormat: "15:04:05.000", messageWidth: 10}
+				e := entry{Time: tm, Level: ErrorLevel, Message: "message\nmore",
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"err":  detailError{},
+						"id":   42,
+						"text": "line 1\nline 2\n",
+					})},
+				}
+
+				// ACT
+				result := format(sut, e)
+
+				// ASSERT
+				test.That(t, result).Equals("07:06:05.432 ERROR message    id=42" +
+					"\n    more" +
+					"\n    err:" +
+					"\n        failed" +
+					"\n            main.main" +
+					"\n                /src/main.go:42" +
+					"\n    text:" +
+					"\n        line 1" +
+					"\n        line 2")
+			},
+		},
+		{scenario: "Format/colors",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &consolefmt{timeFormat: "15:04:05", color: true}
+				e := entry{Time: tm, Level: FatalLevel, Message: "message",
+					callsite:   &callsite{file: "file.go", line: 42},
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"a": 1})},
+				}
+
+				// ACT
+				result := format(sut, e)
+
+				// ASSERT
+				test.That(t, result).Equals("\x1b[2m07:06:05\x1b[0m \x1b[1;31mFATAL\x1b[0m \x1b[2m[file.go:42]\x1b[0m message \x1b[2ma=\x1b[0m1")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
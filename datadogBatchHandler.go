@@ -0,0 +1,95 @@
+package ulog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	datadogAPIKey     = cfgkey("datadog.apiKey")
+	datadogEndpoint   = cfgkey("datadog.endpoint")
+	datadogHeaders    = cfgkey("datadog.headers")
+	datadogHTTPClient = cfgkey("datadog.httpClient")
+	datadogRetry      = cfgkey("datadog.retry")
+	datadogTimeout    = cfgkey("datadog.timeout")
+)
+
+// datadogBatchHandler is a batch handler that sends batches of logs to
+// the Datadog logs intake api.
+type datadogBatchHandler struct {
+	httpSender
+	endpoint string
+	apiKey   string
+}
+
+// newDatadogBatchHandler creates a new, initialised datadog batch handler
+// configured to send logs to the datadoghq.com site.
+func newDatadogBatchHandler() *datadogBatchHandler {
+	return &datadogBatchHandler{
+		httpSender: newHTTPSender(),
+		endpoint:   datadogSiteEndpoint("datadoghq.com"),
+	}
+}
+
+// datadogSiteEndpoint returns the url of the logs intake api for a
+// specified Datadog site.
+func datadogSiteEndpoint(site string) string {
+	return "https://http-intake.logs." + site + "/api/v2/logs"
+}
+
+// configure applies configuration to the datadog batch handler.
+func (h *datadogBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case datadogAPIKey:
+		h.apiKey = value.(string)
+	case datadogEndpoint:
+		h.endpoint = value.(string)
+	case datadogHeaders:
+		h.setHeaders(value.(map[string]string))
+	case datadogHTTPClient:
+		h.client = value.(*http.Client)
+	case datadogRetry:
+		cfg := value.(retryConfig)
+		h.setRetry(cfg.n, cfg.backoff)
+	case datadogTimeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrDatadogConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// Send sends a batch of logs to the Datadog logs intake api, as a JSON
+// array.
+//
+// If the request fails or the response has a status indicating that
+// the request may succeed if sent again (e.g. 429 or 5xx), an error is
+// returned and the batch is retained.  Any other unsuccessful response
+// results in the batch being discarded.
+func (h *datadogBatchHandler) Send(batch *Batch) error {
+	tracef("datadog: send: sending %d entries", batch.len)
+
+	body := make([]byte, 0, batch.size+batch.len+1)
+	body = append(body, '[')
+	body = append(body, bytes.Join(batch.entries, []byte{','})...)
+	body = append(body, ']')
+
+	rq, err := h.newRequest(http.MethodPost, h.endpoint, body)
+	if err != nil {
+		trace("datadog: send: error initialising request: " + err.Error())
+		return err
+	}
+	rq.Header.Set("Content-Type", "application/json")
+	rq.Header.Set("DD-API-KEY", h.apiKey)
+
+	if _, err := h.do(rq); err != nil {
+		trace("datadog: send: error sending request: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("datadog: send: %d entries discarded", batch.len)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package ulog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestDatadogBatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut   *datadogBatchHandler
+		batch = func(logs ...string) *Batch {
+			b := &Batch{}
+			for _, l := range logs {
+				b.entries = append(b.entries, []byte(l))
+				b.size += len(l)
+				b.len++
+			}
+			return b
+		}
+		server = func(status int) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+		}
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				errs := []error{
+					sut.configure(datadogAPIKey, "key"),
+					sut.configure(datadogEndpoint, "http://localhost"),
+					sut.configure(datadogHeaders, map[string]string{"X-Custom": "value"}),
+					sut.configure(datadogHTTPClient, client),
+					sut.configure(datadogRetry, retryConfig{3, time.Second}),
+					sut.configure(datadogTimeout, time.Minute),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.apiKey).Equals("key")
+				test.That(t, sut.endpoint).Equals("http://localhost")
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrDatadogConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					apikey      string
+					contenttype string
+					body        []byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					apikey = r.Header.Get("DD-API-KEY")
+					contenttype = r.Header.Get("Content-Type")
+					body, _ = io.ReadAll(r.Body)
+					w.WriteHeader(http.StatusAccepted)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+				sut.apiKey = "key"
+
+				// ACT
+				err := sut.Send(batch(`{"message":"1"}`, `{"message":"2"}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, apikey).Equals("key")
+				test.That(t, contenttype).Equals("application/json")
+				test.That(t, string(body)).Equals(`[{"message":"1"},{"message":"2"}]`)
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusTooManyRequests)
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch(`{}`))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusForbidden)
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch(`{}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/invalid endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.endpoint = "\n"
+
+				// ACT
+				err := sut.Send(batch(`{}`))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newDatadogBatchHandler()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
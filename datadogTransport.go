@@ -0,0 +1,208 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+type DatadogOption = func(*datadog) error // DatadogOption is a function that configures a datadog transport
+
+// DatadogAttributeId identifies a reserved attribute of a Datadog log.
+type DatadogAttributeId int
+
+const (
+	DatadogHostname DatadogAttributeId = iota // DatadogHostname identifies the hostname attribute
+	DatadogService                            // DatadogService identifies the service attribute
+	DatadogSource                             // DatadogSource identifies the ddsource attribute
+	DatadogTags                               // DatadogTags identifies the ddtags attribute
+	numDatadogAttributes
+)
+
+// the names of the reserved attributes
+var datadogAttributeNames = [numDatadogAttributes]string{
+	DatadogHostname: "hostname",
+	DatadogService:  "service",
+	DatadogSource:   "ddsource",
+	DatadogTags:     "ddtags",
+}
+
+// limits imposed by the Datadog logs intake api
+const (
+	datadogMaxEntries      = 1000
+	datadogMaxEntryBytes   = 1024 * 1024
+	datadogMaxPayloadBytes = 5 * 1024 * 1024
+)
+
+// DatadogTransport returns a transport factory function to create and
+// configure a transport that sends log entries to the Datadog HTTP logs
+// intake api, with specified configuration options applied.
+//
+// An API key must be configured using the DatadogAPIKey option.  By
+// default logs are sent to the datadoghq.com site; a different site may
+// be selected using DatadogSite.
+//
+// Each entry is formatted by the target Formatter.  If the formatted entry
+// is a JSON object (e.g. when using the JSONFormatter) the properties of
+// the object are sent as attributes of the log, otherwise the formatted
+// entry is sent as the message of the log.  The Level of the entry is sent
+// as the status of the log.
+//
+// Batches are limited to the maximum number of entries and payload size
+// accepted by Datadog (1000 entries and 5MB).  Entries larger than 1MB are
+// rejected.
+func DatadogTransport(opts ...DatadogOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newDatadogBatchHandler()
+
+		t := &datadog{
+			batchTransport: batchTransport{
+				name: "datadog",
+				ch:   make(chan []byte, 100),
+				batch: &Batch{
+					maxBytes:      datadogMaxPayloadBytes - datadogMaxEntries - 1,
+					maxEntryBytes: datadogMaxEntryBytes,
+					maxLatency:    10 * time.Second,
+				},
+			},
+		}
+		t.batch.init(bh, datadogMaxEntries)
+		t.static[DatadogHostname], _ = os.Hostname()
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if bh.apiKey == "" {
+			return nil, fmt.Errorf("%w: %w: no api key configured", ErrDatadogConfiguration, ErrInvalidConfiguration)
+		}
+		if err := t.checkLimits(); err != nil {
+			return nil, fmt.Errorf("%w: %w: %w", ErrDatadogConfiguration, ErrInvalidConfiguration, err)
+		}
+		return t, nil
+	}
+}
+
+// datadog implements a transport that sends log entries to the Datadog
+// logs intake api.
+type datadog struct {
+	batchTransport
+	static [numDatadogAttributes]string // static attribute values
+	fields [numDatadogAttributes]string // names of the fields providing attribute values
+}
+
+// datadogStatus is the status of a log for each Level.
+var datadogStatus = [numLevels]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warning",
+	ErrorLevel: "error",
+	FatalLevel: "critical",
+}
+
+// checkLimits returns an error if the batch configuration exceeds the
+// limits imposed by the Datadog logs intake api.
+func (t *datadog) checkLimits() error {
+	b := t.batch
+	switch {
+	case b.max > datadogMaxEntries:
+		return fmt.Errorf("batch may not exceed %d entries", datadogMaxEntries)
+	case b.maxBytes == 0 || b.maxBytes > datadogMaxPayloadBytes-datadogMaxEntries-1:
+		return fmt.Errorf("batch may not exceed %d bytes", datadogMaxPayloadBytes-datadogMaxEntries-1)
+	case b.entryLimit() > datadogMaxEntryBytes:
+		return fmt.Errorf("entries may not exceed %d bytes", datadogMaxEntryBytes)
+	case b.oversize == TruncateOversizeEntries:
+		return errors.New("TruncateOversizeEntries is not supported")
+	}
+	return nil
+}
+
+// attributes returns the reserved attributes for an entry and the names
+// of any fields that provided attribute values.  A value from a field
+// replaces any static value, except for tags; tags from a field are added
+// to any static tags.
+func (t *datadog) attributes(e entry) ([numDatadogAttributes]string, []string) {
+	attrs := t.static
+	used := []string{}
+	if e.logcontext == nil || e.fields == nil {
+		return attrs, used
+	}
+	for id, k := range t.fields {
+		if k == "" {
+			continue
+		}
+		v, ok := e.fields.m[k]
+		if !ok {
+			continue
+		}
+		used = append(used, k)
+		if DatadogAttributeId(id) == DatadogTags && attrs[id] != "" {
+			attrs[id] += fmt.Sprintf(",%v", v)
+			continue
+		}
+		attrs[id] = fmt.Sprintf("%v", v)
+	}
+	return attrs, used
+}
+
+// logEntry implements the entryTransport interface.  The entry is
+// formatted, without any fields that provide attribute values, and
+// encoded as a Datadog log which is sent to the transport channel.
+func (t *datadog) logEntry(e entry, format func(entry) []byte) {
+	attrs, used := t.attributes(e)
+	if len(used) > 0 {
+		lc := *e.logcontext
+		lc.fields = e.fields.without(used...)
+		e.logcontext = &lc
+	}
+
+	t.ch <- encodeDatadogLog(attrs, datadogStatus[e.Level], format(e))
+}
+
+// encodeDatadogLog returns the JSON encoding of a Datadog log with
+// specified attributes, status and formatted entry.  The reserved
+// attributes are added to a formatted entry that is a JSON object,
+// otherwise the formatted entry is the message of the log.
+func encodeDatadogLog(attrs [numDatadogAttributes]string, status string, msg []byte) []byte {
+	quote := func(s string) []byte {
+		b, _ := json.Marshal(s)
+		return b
+	}
+
+	rec := make([]byte, 0, len(msg)+128)
+	rec = append(rec, '{')
+	for id, v := range attrs {
+		if v == "" {
+			continue
+		}
+		rec = append(rec, '"')
+		rec = append(rec, datadogAttributeNames[id]...)
+		rec = append(rec, `":`...)
+		rec = append(rec, quote(v)...)
+		rec = append(rec, ',')
+	}
+	rec = append(rec, `"status":`...)
+	rec = append(rec, quote(status)...)
+
+	obj := bytes.TrimSpace(msg)
+	if len(obj) > 0 && obj[0] == '{' && json.Valid(obj) {
+		if props := bytes.TrimSpace(obj[1:]); props[0] != '}' {
+			rec = append(rec, ',')
+			return append(rec, props...)
+		}
+		return append(rec, '}')
+	}
+
+	rec = append(rec, `,"message":`...)
+	rec = append(rec, quote(string(msg))...)
+	return append(rec, '}')
+}
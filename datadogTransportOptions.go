@@ -0,0 +1,147 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DatadogAPIKey configures the API key used to authenticate requests.
+//
+// The key may be specified as the name of an environment variable or file
+// from which to read the key (see: LogtailSourceToken).
+func DatadogAPIKey(s string) DatadogOption {
+	return func(t *datadog) error {
+		return t.batch.configure(datadogAPIKey, readSecret(s))
+	}
+}
+
+// DatadogAttributes configures static values for the hostname, service,
+// ddsource and/or ddtags attributes of all logs.  Tags are specified as
+// a comma separated list of key:value pairs, e.g. "env:prod,team:core".
+//
+// By default, the hostname is the host name reported by the kernel.  The
+// hostname may be omitted by configuring an empty value.
+func DatadogAttributes(attrs map[DatadogAttributeId]string) DatadogOption {
+	return func(t *datadog) error {
+		for id, v := range attrs {
+			if id < 0 || id >= numDatadogAttributes {
+				return fmt.Errorf("%w: DatadogAttributes: invalid attribute id (%d)", ErrInvalidConfiguration, id)
+			}
+			t.static[id] = v
+		}
+		return nil
+	}
+}
+
+// DatadogAttributeFields configures the names of fields that provide the
+// hostname, service, ddsource and/or ddtags attributes of a log.  If an
+// entry has a field identified for an attribute, the value of the field
+// replaces any static value configured using DatadogAttributes, except
+// for tags; tags from a field are added to any static tags.
+//
+// Fields that provide attributes are not included in the formatted entry.
+func DatadogAttributeFields(fields map[DatadogAttributeId]string) DatadogOption {
+	return func(t *datadog) error {
+		for id, k := range fields {
+			if id < 0 || id >= numDatadogAttributes {
+				return fmt.Errorf("%w: DatadogAttributeFields: invalid attribute id (%d)", ErrInvalidConfiguration, id)
+			}
+			t.fields[id] = k
+		}
+		return nil
+	}
+}
+
+// DatadogBatching applies batch options to configure the batching of log
+// entries sent by the transport.  By default a batch is sent when it
+// contains 1000 entries, reaches the 5MB payload limit or after 10 seconds.
+//
+// Batch options that exceed the limits imposed by Datadog result in an
+// error when the transport is created.
+func DatadogBatching(opts ...BatchOption) DatadogOption {
+	return func(t *datadog) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// DatadogEndpoint configures the url of the logs intake api, replacing the
+// url of any site configured using DatadogSite.  This is useful when logs
+// are sent via a proxy.
+func DatadogEndpoint(s string) DatadogOption {
+	return func(t *datadog) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: DatadogEndpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: DatadogEndpoint: %q: scheme must be http or https", ErrInvalidConfiguration, s)
+		}
+		return t.batch.configure(datadogEndpoint, u.String())
+	}
+}
+
+// DatadogHeaders configures additional headers to be sent with each
+// request.  This option may be specified multiple times; headers are
+// accumulated, with any header specified more than once taking the most
+// recently configured value.
+func DatadogHeaders(h map[string]string) DatadogOption {
+	return func(t *datadog) error {
+		return t.batch.configure(datadogHeaders, h)
+	}
+}
+
+// DatadogHTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func DatadogHTTPClient(c *http.Client) DatadogOption {
+	return func(t *datadog) error {
+		if c == nil {
+			return fmt.Errorf("%w: DatadogHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(datadogHTTPClient, c)
+	}
+}
+
+// DatadogRetry configures the number of times a request is retried if it
+// fails with an error that may succeed if retried (e.g. a network error
+// or a 429 or 5xx response), and the delay before the first retry.  The
+// delay is doubled for each subsequent retry.
+//
+// By default requests are not retried; a batch that cannot be sent is
+// retained and sent again when next flushed.
+func DatadogRetry(n int, backoff time.Duration) DatadogOption {
+	return func(t *datadog) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: DatadogRetry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(datadogRetry, retryConfig{n, backoff})
+	}
+}
+
+// DatadogSite configures the Datadog site to which logs are sent, e.g.
+// "datadoghq.eu" or "us5.datadoghq.com".  The default is "datadoghq.com".
+func DatadogSite(site string) DatadogOption {
+	return func(t *datadog) error {
+		if site == "" || strings.ContainsAny(site, ":/ ") {
+			return fmt.Errorf("%w: DatadogSite: %q: invalid site", ErrInvalidConfiguration, site)
+		}
+		return t.batch.configure(datadogEndpoint, datadogSiteEndpoint(site))
+	}
+}
+
+// DatadogTimeout configures the timeout for requests.  The default is 5
+// seconds.
+func DatadogTimeout(d time.Duration) DatadogOption {
+	return func(t *datadog) error {
+		return t.batch.configure(datadogTimeout, d)
+	}
+}
@@ -0,0 +1,204 @@
+package ulog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestDatadogTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		bh *datadogBatchHandler
+		dd *datadog
+	)
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "DatadogAPIKey",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogAPIKey("key")(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.apiKey).Equals("key")
+			},
+		},
+		{scenario: "DatadogAttributes",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogAttributes(map[DatadogAttributeId]string{
+					DatadogHostname: "host",
+					DatadogService:  "service",
+					DatadogSource:   "source",
+					DatadogTags:     "env:test",
+				})(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, dd.static).Equals([numDatadogAttributes]string{"host", "service", "source", "env:test"})
+			},
+		},
+		{scenario: "DatadogAttributes/invalid id",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogAttributes(map[DatadogAttributeId]string{numDatadogAttributes: "value"})(dd)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "DatadogAttributeFields",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogAttributeFields(map[DatadogAttributeId]string{DatadogService: "service"})(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, dd.fields).Equals([numDatadogAttributes]string{DatadogService: "service"})
+			},
+		},
+		{scenario: "DatadogAttributeFields/invalid id",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogAttributeFields(map[DatadogAttributeId]string{-1: "field"})(dd)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "DatadogBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogBatching(BatchMaxEntries(42))(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, dd.batch.max).Equals(42)
+			},
+		},
+		{scenario: "DatadogEndpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogEndpoint("http://proxy:3834/api/v2/logs")(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("http://proxy:3834/api/v2/logs")
+			},
+		},
+		{scenario: "DatadogEndpoint/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					DatadogEndpoint("\n")(dd),
+					DatadogEndpoint("proxy:3834")(dd),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "DatadogHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogHeaders(map[string]string{"X-Custom": "value"})(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "DatadogHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := DatadogHTTPClient(client)(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "DatadogHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogHTTPClient(nil)(dd)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "DatadogRetry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogRetry(3, time.Second)(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.retries).Equals(3)
+				test.That(t, bh.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "DatadogRetry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogRetry(-1, time.Second)(dd)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "DatadogSite",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogSite("datadoghq.eu")(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("https://http-intake.logs.datadoghq.eu/api/v2/logs")
+			},
+		},
+		{scenario: "DatadogSite/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					DatadogSite("")(dd),
+					DatadogSite("https://datadoghq.eu")(dd),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "DatadogTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := DatadogTimeout(time.Minute)(dd)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newDatadogBatchHandler()
+			dd = &datadog{batchTransport: batchTransport{batch: &Batch{}}}
+			dd.batch.init(bh, 16)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
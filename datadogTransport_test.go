@@ -0,0 +1,218 @@
+package ulog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestDatadogTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// DatadogTransport tests
+		{scenario: "DatadogTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*datadog) error { return opterr }
+
+				// ACT
+				result, err := DatadogTransport(DatadogAPIKey("key"), opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "DatadogTransport/no api key",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := DatadogTransport()()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrDatadogConfiguration)
+			},
+		},
+		{scenario: "DatadogTransport/batching exceeds limits",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					scenario string
+					opt      BatchOption
+				}{
+					{scenario: "entries", opt: BatchMaxEntries(1001)},
+					{scenario: "bytes", opt: BatchMaxBytes(0)},
+					{scenario: "entry bytes", opt: BatchMaxEntryBytes(datadogMaxEntryBytes + 1)},
+					{scenario: "truncate", opt: BatchOversizeEntries(TruncateOversizeEntries)},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.scenario, func(t *testing.T) {
+						// ACT
+						result, err := DatadogTransport(DatadogAPIKey("key"), DatadogBatching(tc.opt))()
+
+						// ASSERT
+						test.That(t, result).IsNil()
+						test.Error(t, err).Is(ErrDatadogConfiguration)
+						test.Error(t, err).Is(ErrInvalidConfiguration)
+					})
+				}
+			},
+		},
+		{scenario: "DatadogTransport/with api key",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hostname, _ := os.Hostname()
+
+				// ACT
+				result, err := DatadogTransport(DatadogAPIKey("key"))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*datadog](t, result); ok {
+					test.That(t, result.batch.max, "batch capacity").Equals(1000)
+					test.That(t, result.batch.maxBytes, "max bytes").Equals(5*1024*1024 - 1001)
+					test.That(t, result.batch.maxEntryBytes, "max entry bytes").Equals(1024 * 1024)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(10 * time.Second)
+					test.That(t, result.static[DatadogHostname], "hostname").Equals(hostname)
+					if handler, ok := test.IsType[*datadogBatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.endpoint, "endpoint").Equals("https://http-intake.logs.datadoghq.com/api/v2/logs")
+						test.That(t, handler.apiKey, "api key").Equals("key")
+					}
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry/with attributes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &datadog{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				sut.static[DatadogHostname] = "host"
+				sut.static[DatadogService] = "static-service"
+				sut.static[DatadogTags] = "env:test"
+				sut.fields[DatadogService] = "service"
+				sut.fields[DatadogTags] = "tags"
+				sut.fields[DatadogSource] = "source"
+				e := entry{
+					logcontext: &logcontext{fields: newFields(3).merge(map[string]any{
+						"service": "api",
+						"tags":    "team:core",
+						"key":     "value",
+					})},
+					Time:    tm,
+					Level:   ErrorLevel,
+					Message: "message",
+				}
+				formatted := map[string]any{}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte {
+					formatted = e.fields.m
+					return []byte(`{"message":"` + e.Message + `"}`)
+				})
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`{"hostname":"host","service":"api","ddtags":"env:test,team:core","status":"error","message":"message"}`)
+				test.Map(t, formatted, "formatted fields").Equals(map[string]any{"key": "value"})
+				test.That(t, len(e.fields.m), "original fields").Equals(3)
+			},
+		},
+
+		// encodeDatadogLog tests
+		{scenario: "encodeDatadogLog",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				attrs := [numDatadogAttributes]string{DatadogSource: "go"}
+				testcases := []struct {
+					msg    string
+					result string
+				}{
+					{msg: `{"a":1}`, result: `{"ddsource":"go","status":"info","a":1}`},
+					{msg: ` { } `, result: `{"ddsource":"go","status":"info"}`},
+					{msg: `level=info msg="text"`, result: `{"ddsource":"go","status":"info","message":"level=info msg=\"text\""}`},
+					{msg: `{not json}`, result: `{"ddsource":"go","status":"info","message":"{not json}"}`},
+					{msg: `["array"]`, result: `{"ddsource":"go","status":"info","message":"[\"array\"]"}`},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.msg, func(t *testing.T) {
+						// ACT
+						result := encodeDatadogLog(attrs, "info", []byte(tc.msg))
+
+						// ASSERT
+						test.That(t, string(result)).Equals(tc.result)
+						test.IsTrue(t, json.Valid(result), "valid json")
+					})
+				}
+			},
+		},
+
+		// end-to-end
+		{scenario: "sends logs to datadog",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					apikey string
+					body   []byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					apikey = r.Header.Get("DD-API-KEY")
+					body, _ = io.ReadAll(r.Body)
+					w.WriteHeader(http.StatusAccepted)
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(JSONFormatter()),
+							TargetTransport(DatadogTransport(
+								DatadogAPIKey("key"),
+								DatadogEndpoint(srv.URL),
+								DatadogAttributes(map[DatadogAttributeId]string{
+									DatadogHostname: "",
+									DatadogSource:   "go",
+								}),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("entry 1")
+				logger.Warn("entry 2")
+				closelog()
+
+				// ASSERT
+				logs := []map[string]any{}
+				test.Error(t, json.Unmarshal(body, &logs)).IsNil()
+				test.That(t, apikey).Equals("key")
+				test.That(t, len(logs), "logs").Equals(2)
+				test.That(t, logs[1]["ddsource"]).Equals(any("go"))
+				test.That(t, logs[1]["status"]).Equals(any("warning"))
+				test.That(t, logs[1]["message"]).Equals(any("entry 2"))
+				_, hashostname := logs[1]["hostname"]
+				test.IsFalse(t, hashostname, "hostname")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
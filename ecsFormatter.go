@@ -0,0 +1,154 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ECSFormatterOption func(*ecsfmt) error // ECSFormatterOption is a function for configuring an ECS formatter
+
+// ecsVersion is the version of the Elastic Common Schema to which logs
+// conform.
+const ecsVersion = "8.11.0"
+
+// ecsLevel is the log.level for each Level.
+var ecsLevel = [numLevels]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
+// ECSFormatter returns a function that configures a formatter that writes
+// log entries as JSON documents conforming to the Elastic Common Schema
+// (ECS), with specified configuration options applied.
+//
+// Each document has the following fields:
+//
+//   - @timestamp is the time of the entry (RFC 3339, UTC)
+//   - log.level is the Level of the entry (trace, debug, info, warn, error
+//     or fatal)
+//   - message is the message of the entry
+//   - ecs.version identifies the version of ECS
+//
+// If the entry has a callsite, log.origin.file.name, log.origin.file.line
+// and log.origin.function identify the file, line and function.
+//
+// Each field of the entry is written at the ECS path to which the field
+// is mapped (see: ECSFieldMap) or, if not mapped, at the path given by the
+// name of the field.  Dotted paths are written as nested objects, e.g. a
+// field mapped to "user.name" is written as {"user":{"name":...}}.
+//
+// An error-valued field is written as an ECS error object, with
+// error.message (the error string), error.type (the type of the error) and
+// error.stack_trace (if formatting the error with %+v provides more detail
+// than the error string, as for errors that capture a stack trace).  An
+// error-valued field that is not mapped is written at "error", unless some
+// other field is written at "error"; if an entry has more than one such
+// field, the first (in order of name) is written at "error" and any others
+// at the path given by their name.
+//
+// The fields above (@timestamp, log.level etc) take precedence over any
+// field of the entry written at the same path.
+func ECSFormatter(opts ...ECSFormatterOption) FormatterFactory {
+	return func() (Formatter, error) {
+		ef := &ecsfmt{paths: map[string][]string{}}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(ef))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		return ef, nil
+	}
+}
+
+type ecsfmt struct {
+	paths map[string][]string // the ECS path of mapped fields, by field name, split into path segments
+}
+
+// ecsError returns the ECS error object for an error.
+func ecsError(err error) map[string]any {
+	obj := map[string]any{
+		"message": err.Error(),
+		"type":    fmt.Sprintf("%T", err),
+	}
+	if st := fmt.Sprintf("%+v", err); st != err.Error() {
+		obj["stack_trace"] = st
+	}
+	return obj
+}
+
+// ecsSet sets the value at a specified path in a document, creating any
+// intermediate objects required.  Any non-object value at an intermediate
+// path is replaced.
+func ecsSet(doc map[string]any, path []string, v any) {
+	for _, k := range path[:len(path)-1] {
+		obj, ok := doc[k].(map[string]any)
+		if !ok {
+			obj = map[string]any{}
+			doc[k] = obj
+		}
+		doc = obj
+	}
+	doc[path[len(path)-1]] = v
+}
+
+// Format implements a Formatter that writes log entries as ECS documents.
+func (w *ecsfmt) Format(id int, e entry, b ByteWriter) {
+	doc := map[string]any{}
+
+	if e.logcontext != nil && e.fields != nil {
+		names := make([]string, 0, len(e.fields.m))
+		for k := range e.fields.m {
+			names = append(names, k)
+		}
+		slices.Sort(names)
+
+		// an unmapped error is written at "error" only if no other field
+		// is written there
+		errorWritten := false
+		for _, k := range names {
+			if path, mapped := w.paths[k]; (mapped && len(path) == 1 && path[0] == "error") || (!mapped && k == "error") {
+				errorWritten = true
+			}
+		}
+
+		for _, k := range names {
+			v := e.fields.m[k]
+			path, mapped := w.paths[k]
+			if !mapped {
+				path = strings.Split(k, ".")
+			}
+			if err, ok := v.(error); ok {
+				if !mapped && !errorWritten {
+					path = []string{"error"}
+					errorWritten = true
+				}
+				v = ecsError(err)
+			}
+			ecsSet(doc, path, v)
+		}
+	}
+
+	doc["@timestamp"] = e.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = e.Message
+	ecsSet(doc, []string{"log", "level"}, ecsLevel[e.Level])
+	ecsSet(doc, []string{"ecs", "version"}, ecsVersion)
+
+	if e.callsite != nil {
+		ecsSet(doc, []string{"log", "origin", "file", "name"}, e.callsite.file)
+		ecsSet(doc, []string{"log", "origin", "file", "line"}, e.callsite.line)
+		ecsSet(doc, []string{"log", "origin", "function"}, e.callsite.function)
+	}
+
+	writeJSONObject(b, doc, "ECS")
+}
@@ -0,0 +1,33 @@
+package ulog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ECSFieldMap configures the ECS paths at which fields are written, as a
+// map of field names to dotted ECS paths, e.g.:
+//
+//	ulog.ECSFieldMap(map[string]string{
+//	    "user":       "user.name",
+//	    "request_id": "http.request.id",
+//	    "cause":      "error",
+//	})
+//
+// Fields that are not mapped are written at the path given by their name.
+// This option may be specified multiple times; mappings are accumulated,
+// with any field mapped more than once taking the most recently configured
+// path.
+func ECSFieldMap(paths map[string]string) ECSFormatterOption {
+	return func(ef *ecsfmt) error {
+		for k, v := range paths {
+			path := strings.Split(v, ".")
+			if slices.Contains(path, "") {
+				return fmt.Errorf("%w: ECSFieldMap: %q: invalid path: %q", ErrInvalidConfiguration, k, v)
+			}
+			ef.paths[k] = path
+		}
+		return nil
+	}
+}
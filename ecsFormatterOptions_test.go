@@ -0,0 +1,55 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestECSFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *ecsfmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ECSFieldMap",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := ECSFieldMap(map[string]string{"user": "user.name", "id": "trace.id"})(sut)
+				err2 := ECSFieldMap(map[string]string{"id": "http.request.id"})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, sut.paths).Equals(map[string][]string{
+					"user": {"user", "name"},
+					"id":   {"http", "request", "id"},
+				})
+			},
+		},
+		{scenario: "ECSFieldMap/invalid path",
+			exec: func(t *testing.T) {
+				for _, path := range []string{"", "user.", ".name", "user..name"} {
+					t.Run(path, func(t *testing.T) {
+						// ACT
+						err := ECSFieldMap(map[string]string{"user": path})(sut)
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidConfiguration)
+					})
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &ecsfmt{paths: map[string][]string{}}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
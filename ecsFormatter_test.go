@@ -0,0 +1,234 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// stackError is an error that provides additional detail when formatted
+// with %+v, as for errors that capture a stack trace.
+type stackError struct{}
+
+func (stackError) Error() string { return "failed" }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = s.Write([]byte("failed\n\tat main.go:42"))
+		return
+	}
+	_, _ = s.Write([]byte(e.Error()))
+}
+
+func TestECSFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.FixedZone("CEST", 2*60*60))
+
+	// format returns the document formatted for an entry, decoded into a map
+	format := func(t *testing.T, sut *ecsfmt, e entry) map[string]any {
+		buf := &bytes.Buffer{}
+		sut.Format(0, e, buf)
+		m := map[string]any{}
+		test.Error(t, json.Unmarshal(buf.Bytes(), &m)).IsNil()
+		return m
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// ECSFormatter tests
+		{scenario: "ECSFormatter",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := ECSFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*ecsfmt)).Equals(&ecsfmt{paths: map[string][]string{}})
+			},
+		},
+		{scenario: "ECSFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := ECSFormatter(func(*ecsfmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// ecsError tests
+		{scenario: "ecsError",
+			exec: func(t *testing.T) {
+				// ACT
+				result := ecsError(errors.New("failed"))
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"message": "failed",
+					"type":    "*errors.errorString",
+				})
+			},
+		},
+		{scenario: "ecsError/stack trace",
+			exec: func(t *testing.T) {
+				// ACT
+				result := ecsError(stackError{})
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"message":     "failed",
+					"type":        "ulog.stackError",
+					"stack_trace": "failed\n\tat main.go:42",
+				})
+			},
+		},
+
+		// Format tests
+		{scenario: "Format",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &ecsfmt{}
+				e := entry{Time: tm, Level: WarnLevel, Message: "message"}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"@timestamp": "2010-09-08T05:06:05.4321Z",
+					"message":    "message",
+					"log":        map[string]any{"level": "warn"},
+					"ecs":        map[string]any{"version": ecsVersion},
+				})
+			},
+		},
+		{scenario: "Format/unsupported field value",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &ecsfmt{}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"ratio": math.NaN()})},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["ratio"]).Equals(any("ECS_ERROR: marshalling error: json: unsupported value: NaN"))
+			},
+		},
+		{scenario: "Format/callsite",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &ecsfmt{}
+				e := entry{
+					Time:     tm,
+					Level:    InfoLevel,
+					callsite: &callsite{file: "file.go", line: 42, function: "pkg.fn"},
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["log"]).Equals(any(map[string]any{
+					"level": "info",
+					"origin": map[string]any{
+						"file":     map[string]any{"name": "file.go", "line": float64(42)},
+						"function": "pkg.fn",
+					},
+				}))
+			},
+		},
+		{scenario: "Format/fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &ecsfmt{paths: map[string][]string{
+					"user":  {"user", "name"},
+					"id":    {"http", "request", "id"},
+					"cause": {"error"},
+				}}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"user":          "alice",
+						"id":            "abc",
+						"service.name":  "svc",
+						"cause":         errors.New("cause"),
+						"other":         errors.New("other"),
+						"message":       "overridden",
+						"log":           "replaced",
+						"custom":        42,
+						"ecs.version":   "overridden",
+						"http.method":   "GET",
+						"labels.region": "eu",
+					})},
+					Time:    tm,
+					Level:   ErrorLevel,
+					Message: "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"@timestamp": "2010-09-08T05:06:05.4321Z",
+					"message":    "message",
+					"log":        map[string]any{"level": "error"},
+					"ecs":        map[string]any{"version": ecsVersion},
+					"user":       map[string]any{"name": "alice"},
+					"http": map[string]any{
+						"method":  "GET",
+						"request": map[string]any{"id": "abc"},
+					},
+					"service": map[string]any{"name": "svc"},
+					"labels":  map[string]any{"region": "eu"},
+					"custom":  float64(42),
+					"error":   map[string]any{"message": "cause", "type": "*errors.errorString"},
+					"other":   map[string]any{"message": "other", "type": "*errors.errorString"},
+				})
+			},
+		},
+		{scenario: "Format/unmapped errors",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &ecsfmt{}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"b": errors.New("b"),
+						"a": errors.New("a"),
+					})},
+					Time:  tm,
+					Level: ErrorLevel,
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["error"]).Equals(any(map[string]any{"message": "a", "type": "*errors.errorString"}))
+				test.That(t, result["b"]).Equals(any(map[string]any{"message": "b", "type": "*errors.errorString"}))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	esAuthorization = cfgkey("elasticsearch.authorization")
+	esDataStream    = cfgkey("elasticsearch.dataStream")
+	esEndpoint      = cfgkey("elasticsearch.endpoint")
+	esHeaders       = cfgkey("elasticsearch.headers")
+	esHTTPClient    = cfgkey("elasticsearch.httpClient")
+	esRetry         = cfgkey("elasticsearch.retry")
+	esTimeout       = cfgkey("elasticsearch.timeout")
+)
+
+// elasticsearchBatchHandler is a batch handler that sends batches of
+// records to the Elasticsearch _bulk api.
+type elasticsearchBatchHandler struct {
+	httpSender
+	endpoint      string
+	authorization string
+	op            string // the bulk action: "index" (or "create" for data streams)
+}
+
+// esBulkResponse is the response to a _bulk request.  Only the properties
+// required to identify failed documents are decoded.
+type esBulkResponse struct {
+	Errors bool                    `json:"errors"`
+	Items  []map[string]esBulkItem `json:"items"`
+}
+
+// esBulkItem is the result of an individual action in a _bulk request.
+type esBulkItem struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// newElasticsearchBatchHandler creates a new, initialised elasticsearch
+// batch handler.
+func newElasticsearchBatchHandler() *elasticsearchBatchHandler {
+	return &elasticsearchBatchHandler{
+		httpSender: newHTTPSender(),
+		op:         "index",
+	}
+}
+
+// configure applies configuration to the elasticsearch batch handler.
+func (h *elasticsearchBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case esAuthorization:
+		h.authorization = value.(string)
+	case esDataStream:
+		h.op = "index"
+		if value.(bool) {
+			h.op = "create"
+		}
+	case esEndpoint:
+		h.endpoint = value.(string)
+	case esHeaders:
+		h.setHeaders(value.(map[string]string))
+	case esHTTPClient:
+		h.client = value.(*http.Client)
+	case esRetry:
+		cfg := value.(retryConfig)
+		h.setRetry(cfg.n, cfg.backoff)
+	case esTimeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrElasticsearchConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// encode returns the body of a _bulk request for a batch of records.  Each
+// record provides an action line identifying the index, followed by the
+// document:
+//
+//	{"index":{"_index":"<index>"}}
+//	<document>
+func (h *elasticsearchBatchHandler) encode(batch *Batch) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, batch.size+batch.len*(len(h.op)+20)))
+	for _, rec := range batch.entries {
+		index, doc := decodeElasticsearchRecord(rec)
+		_, _ = buf.WriteString(`{"` + h.op + `":{"_index":"`)
+		_, _ = buf.Write(index)
+		_, _ = buf.WriteString("\"}}\n")
+		_, _ = buf.Write(doc)
+		_ = buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// failed returns the indices of the documents in a bulk response that
+// were not indexed but may succeed if sent again (i.e. those with a
+// 408, 429 or 5xx status).  Documents that were rejected for any other
+// reason (e.g. a mapping error) are discarded.
+func (h *elasticsearchBatchHandler) failed(response *esBulkResponse) []int {
+	failed := []int{}
+	for i, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= 200 && result.Status <= 299 {
+				continue
+			}
+			if (httpStatusError{result.Status}).retryable() {
+				failed = append(failed, i)
+				continue
+			}
+			reason := "no reason given"
+			if result.Error != nil {
+				reason = result.Error.Type + ": " + result.Error.Reason
+			}
+			tracef("elasticsearch: send: document %d discarded: %d %s", i, result.Status, reason)
+		}
+	}
+	return failed
+}
+
+// headerLen implements the recordHandler interface, returning the length
+// of the index name preceding the document of a record.
+func (h *elasticsearchBatchHandler) headerLen(rec []byte) int {
+	sz, n := binary.Uvarint(rec)
+	return n + int(sz)
+}
+
+// Send sends a batch of records to the Elasticsearch _bulk api.
+//
+// If the request fails or the response has a status indicating that
+// the request may succeed if sent again (e.g. 429 or 5xx), an error is
+// returned and the batch is retained.  Any other unsuccessful response
+// results in the batch being discarded.
+//
+// If the request succeeds but some documents were not indexed, the batch
+// is reduced to those documents that may succeed if sent again and an
+// error is returned so that they are retained.
+func (h *elasticsearchBatchHandler) Send(batch *Batch) error {
+	tracef("elasticsearch: send: sending %d entries", batch.len)
+
+	rq, err := h.newRequest(http.MethodPost, h.endpoint, h.encode(batch))
+	if err != nil {
+		trace("elasticsearch: send: error initialising request: " + err.Error())
+		return err
+	}
+	rq.Header.Set("Content-Type", "application/x-ndjson")
+	if h.authorization != "" {
+		rq.Header.Set("Authorization", h.authorization)
+	}
+
+	body, err := h.do(rq)
+	if err != nil {
+		trace("elasticsearch: send: error sending request: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("elasticsearch: send: %d entries discarded", batch.len)
+		return nil
+	}
+
+	response := &esBulkResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		trace("elasticsearch: send: error decoding response: " + err.Error())
+		return nil
+	}
+	if !response.Errors {
+		return nil
+	}
+
+	failed := h.failed(response)
+	if len(failed) == 0 {
+		return nil
+	}
+	batch.Retain(failed)
+	tracef("elasticsearch: send: %d entries retained", batch.len)
+
+	return fmt.Errorf("%w: %d documents not indexed", ErrUnexpectedResponse, len(failed))
+}
@@ -0,0 +1,284 @@
+package ulog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestElasticsearchBatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut   *elasticsearchBatchHandler
+		batch = func(recs ...[]byte) *Batch {
+			b := &Batch{}
+			for _, rec := range recs {
+				b.entries = append(b.entries, rec)
+				b.size += len(rec)
+				b.len++
+			}
+			return b
+		}
+		server = func(status int, response string) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(response))
+			}))
+		}
+		doc1 = encodeElasticsearchRecord("logs", []byte(`{"message":"1"}`))
+		doc2 = encodeElasticsearchRecord("logs", []byte(`{"message":"2"}`))
+		doc3 = encodeElasticsearchRecord("logs", []byte(`{"message":"3"}`))
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// headerLen tests
+		{scenario: "headerLen/truncated record",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rec := encodeElasticsearchRecord("logs", []byte(`{"message":"1"}`))
+				b := &Batch{max: 10, maxEntryBytes: 9, oversize: TruncateOversizeEntries, handler: sut}
+
+				// ACT
+				b.Add(rec)
+
+				// ASSERT
+				test.That(t, sut.headerLen(rec)).Equals(5)
+				index, doc := decodeElasticsearchRecord(b.entries[0])
+				test.That(t, string(index)).Equals("logs")
+				test.That(t, string(doc)).Equals(`{"me`)
+			},
+		},
+		{scenario: "headerLen/limit less than header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &Batch{max: 10, maxEntryBytes: 3, oversize: TruncateOversizeEntries, handler: sut}
+
+				// ACT
+				b.Add(doc1)
+
+				// ASSERT
+				test.That(t, b.len).Equals(0)
+			},
+		},
+
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				errs := []error{
+					sut.configure(esAuthorization, "ApiKey key"),
+					sut.configure(esDataStream, true),
+					sut.configure(esEndpoint, "http://localhost"),
+					sut.configure(esHeaders, map[string]string{"X-Custom": "value"}),
+					sut.configure(esHTTPClient, client),
+					sut.configure(esRetry, retryConfig{3, time.Second}),
+					sut.configure(esTimeout, time.Minute),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.authorization).Equals("ApiKey key")
+				test.That(t, sut.op).Equals("create")
+				test.That(t, sut.endpoint).Equals("http://localhost")
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "configure/data stream disabled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.op = "create"
+
+				// ACT
+				err := sut.configure(esDataStream, false)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.op).Equals("index")
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrElasticsearchConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// encode tests
+		{scenario: "encode",
+			exec: func(t *testing.T) {
+				// ACT
+				result := sut.encode(batch(doc1, encodeElasticsearchRecord("other", []byte(`{"message":"2"}`))))
+
+				// ASSERT
+				test.That(t, string(result)).Equals(
+					`{"index":{"_index":"logs"}}` + "\n" +
+						`{"message":"1"}` + "\n" +
+						`{"index":{"_index":"other"}}` + "\n" +
+						`{"message":"2"}` + "\n")
+			},
+		},
+		{scenario: "encode/data stream",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.op = "create"
+
+				// ACT
+				result := sut.encode(batch(doc1))
+
+				// ASSERT
+				test.That(t, string(result)).Equals(`{"create":{"_index":"logs"}}` + "\n" + `{"message":"1"}` + "\n")
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					authheader  string
+					contenttype string
+					body        []byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authheader = r.Header.Get("Authorization")
+					contenttype = r.Header.Get("Content-Type")
+					body, _ = io.ReadAll(r.Body)
+					_, _ = w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+				sut.authorization = "ApiKey key"
+				b := batch(doc1)
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, authheader).Equals("ApiKey key")
+				test.That(t, contenttype).Equals("application/x-ndjson")
+				test.That(t, string(body)).Equals(`{"index":{"_index":"logs"}}` + "\n" + `{"message":"1"}` + "\n")
+			},
+		},
+		{scenario: "send/some documents failed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusOK, `{"errors":true,"items":[`+
+					`{"index":{"status":503,"error":{"type":"unavailable_shards_exception","reason":"primary shard not active"}}},`+
+					`{"index":{"status":201}},`+
+					`{"index":{"status":429}}`+
+					`]}`)
+				defer srv.Close()
+				sut.endpoint = srv.URL
+				b := batch(doc1, doc2, doc3)
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, b.len).Equals(2)
+				test.That(t, b.entries).Equals([][]byte{doc1, doc3})
+			},
+		},
+		{scenario: "send/failed documents not retryable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusOK, `{"errors":true,"items":[`+
+					`{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}},`+
+					`{"index":{"status":409}}`+
+					`]}`)
+				defer srv.Close()
+				sut.endpoint = srv.URL
+				b := batch(doc1, doc2)
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, b.len).Equals(2)
+			},
+		},
+		{scenario: "send/invalid response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusOK, `not json`)
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch(doc1))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusServiceUnavailable, "")
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch(doc1))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := server(http.StatusUnauthorized, "")
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch(doc1))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/invalid endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.endpoint = "\n"
+
+				// ACT
+				err := sut.Send(batch(doc1))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newElasticsearchBatchHandler()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ElasticsearchOption = func(*elasticsearch) error // ElasticsearchOption is a function that configures an elasticsearch transport
+
+// ElasticsearchTransport returns a transport factory function to create
+// and configure a transport that sends log entries to an Elasticsearch
+// (or OpenSearch) cluster using the _bulk api, with specified
+// configuration options applied.
+//
+// An endpoint must be configured using the ElasticsearchEndpoint option
+// and an index (or data stream) using either ElasticsearchIndex or
+// ElasticsearchDataStream.
+//
+// Each entry is formatted by the target Formatter to provide the document
+// to be indexed; the Formatter must produce a JSON object on a single line,
+// e.g. the JSONFormatter.
+func ElasticsearchTransport(opts ...ElasticsearchOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newElasticsearchBatchHandler()
+
+		t := &elasticsearch{
+			batchTransport: batchTransport{
+				name:  "elasticsearch",
+				ch:    make(chan []byte, 100),
+				batch: &Batch{maxLatency: 10 * time.Second},
+			},
+		}
+		t.batch.init(bh, 100)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if bh.endpoint == "" {
+			return nil, fmt.Errorf("%w: %w: no endpoint configured", ErrElasticsearchConfiguration, ErrInvalidConfiguration)
+		}
+		if t.index == nil {
+			return nil, fmt.Errorf("%w: %w: no index or data stream configured", ErrElasticsearchConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// esIndexSegment is a segment of an index name pattern; either a literal
+// string or a time layout to be formatted using the time of an entry.
+type esIndexSegment struct {
+	s      string
+	layout bool
+}
+
+// elasticsearch implements a transport that sends log entries to an
+// Elasticsearch cluster.
+type elasticsearch struct {
+	batchTransport
+	index []esIndexSegment // the (parsed) index name pattern
+}
+
+// indexName returns the name of the index for an entry with a specified
+// time.  Time layouts are formatted using the UTC time.
+func (t *elasticsearch) indexName(tm time.Time) string {
+	if len(t.index) == 1 && !t.index[0].layout {
+		return t.index[0].s
+	}
+
+	sb := strings.Builder{}
+	for _, seg := range t.index {
+		if seg.layout {
+			_, _ = sb.WriteString(strings.ToLower(tm.UTC().Format(seg.s)))
+			continue
+		}
+		_, _ = sb.WriteString(seg.s)
+	}
+	return sb.String()
+}
+
+// logEntry implements the entryTransport interface.  The name of the index
+// for the entry is determined from the time of the entry and encoded with
+// the formatted entry in a record that is sent to the transport channel.
+func (t *elasticsearch) logEntry(e entry, format func(entry) []byte) {
+	t.ch <- encodeElasticsearchRecord(t.indexName(e.Time), format(e))
+}
+
+// encodeElasticsearchRecord encodes the index name and document of an
+// entry in a record to be added to a batch.  The record is encoded as:
+//
+//	uvarint    length of the index name
+//	[]byte     index name
+//	[]byte     document
+//
+// Any trailing newline in the document is removed.
+func encodeElasticsearchRecord(index string, doc []byte) []byte {
+	if n := len(doc); n > 0 && doc[n-1] == '\n' {
+		doc = doc[:n-1]
+	}
+
+	rec := make([]byte, 0, binary.MaxVarintLen16+len(index)+len(doc))
+	rec = binary.AppendUvarint(rec, uint64(len(index)))
+	rec = append(rec, index...)
+	return append(rec, doc...)
+}
+
+// decodeElasticsearchRecord decodes a record encoded by
+// encodeElasticsearchRecord, returning the index name and document.
+func decodeElasticsearchRecord(rec []byte) ([]byte, []byte) {
+	sz, n := binary.Uvarint(rec)
+	rec = rec[n:]
+	return rec[:sz], rec[sz:]
+}
@@ -0,0 +1,207 @@
+package ulog
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// esInvalidIndexChars are the characters that may not appear in the name
+// of an Elasticsearch index or data stream.
+const esInvalidIndexChars = `\/*?"<>| ,#:`
+
+// parseElasticsearchIndex parses an index name pattern, returning the
+// segments of the pattern.  Any part of the pattern enclosed in braces
+// is a time layout, e.g. "logs-{2006.01.02}".
+func parseElasticsearchIndex(pattern string) ([]esIndexSegment, error) {
+	if pattern == "" {
+		return nil, errors.New("name is empty")
+	}
+	if strings.ContainsAny(pattern[:1], "-_+") {
+		return nil, fmt.Errorf("%q: name may not start with '-', '_' or '+'", pattern)
+	}
+
+	segs := []esIndexSegment{}
+	s := pattern
+	for s != "" {
+		open := strings.IndexByte(s, '{')
+		if open == -1 {
+			segs = append(segs, esIndexSegment{s: s})
+			break
+		}
+		close := strings.IndexByte(s[open:], '}')
+		if close == -1 {
+			return nil, fmt.Errorf("%q: unterminated time layout", pattern)
+		}
+		close += open
+
+		if open > 0 {
+			segs = append(segs, esIndexSegment{s: s[:open]})
+		}
+		if layout := s[open+1 : close]; layout != "" {
+			segs = append(segs, esIndexSegment{s: layout, layout: true})
+		}
+		s = s[close+1:]
+	}
+
+	for _, seg := range segs {
+		if strings.ContainsAny(seg.s, esInvalidIndexChars+"{}") {
+			return nil, fmt.Errorf("%q: name contains invalid characters", pattern)
+		}
+		if !seg.layout && strings.ToLower(seg.s) != seg.s {
+			return nil, fmt.Errorf("%q: name must be lowercase", pattern)
+		}
+	}
+	return segs, nil
+}
+
+// ElasticsearchAPIKey configures the transport to authenticate requests
+// using an API key.  The key is the base64 encoded credentials returned
+// (as "encoded") when the key is created.
+//
+// The key may be specified as the name of an environment variable or file
+// from which to read the key (see: LogtailSourceToken).
+func ElasticsearchAPIKey(key string) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		return t.batch.configure(esAuthorization, "ApiKey "+readSecret(key))
+	}
+}
+
+// ElasticsearchBasicAuth configures the transport to authenticate requests
+// using basic authentication with a specified username and password.
+//
+// The password may be specified as the name of an environment variable or
+// file from which to read the password (see: LogtailSourceToken).
+func ElasticsearchBasicAuth(username, password string) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		creds := username + ":" + readSecret(password)
+		return t.batch.configure(esAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(creds)))
+	}
+}
+
+// ElasticsearchBatching applies batch options to configure the batching of
+// log entries sent by the transport.  By default a batch is sent when it
+// contains 100 entries or after 10 seconds.
+func ElasticsearchBatching(opts ...BatchOption) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// ElasticsearchDataStream configures the transport to write entries to a
+// data stream with a specified name.  Documents are written to a data
+// stream using the bulk "create" action.
+//
+// The documents written to a data stream must have an @timestamp field;
+// when using the JSONFormatter this may be achieved by configuring the
+// name of the TimeField using the JSONFieldNames option.
+//
+// This option replaces any index configured using ElasticsearchIndex.
+func ElasticsearchDataStream(name string) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		if strings.ContainsAny(name, "{}") {
+			return fmt.Errorf("%w: ElasticsearchDataStream: %q: name may not contain a time layout", ErrInvalidConfiguration, name)
+		}
+		segs, err := parseElasticsearchIndex(name)
+		if err != nil {
+			return fmt.Errorf("%w: ElasticsearchDataStream: %w", ErrInvalidConfiguration, err)
+		}
+		t.index = segs
+		return t.batch.configure(esDataStream, true)
+	}
+}
+
+// ElasticsearchEndpoint configures the url of the Elasticsearch cluster.
+// If the url does not specify a path, the path of the bulk api (/_bulk)
+// is used.
+func ElasticsearchEndpoint(s string) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: ElasticsearchEndpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: ElasticsearchEndpoint: %q: scheme must be http or https", ErrInvalidConfiguration, s)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/_bulk"
+		}
+		return t.batch.configure(esEndpoint, u.String())
+	}
+}
+
+// ElasticsearchHeaders configures additional headers to be sent with each
+// request.  This option may be specified multiple times; headers are
+// accumulated, with any header specified more than once taking the most
+// recently configured value.
+func ElasticsearchHeaders(h map[string]string) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		return t.batch.configure(esHeaders, h)
+	}
+}
+
+// ElasticsearchHTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func ElasticsearchHTTPClient(c *http.Client) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		if c == nil {
+			return fmt.Errorf("%w: ElasticsearchHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(esHTTPClient, c)
+	}
+}
+
+// ElasticsearchIndex configures the name of the index to which entries are
+// written.  The name may include time layouts enclosed in braces, which are
+// formatted using the (UTC) time of each entry.  For example, the pattern
+// "logs-{2006.01.02}" writes an entry logged at 10:00 on 1st March 2024 to
+// the index "logs-2024.03.01".
+//
+// This option replaces any data stream configured using
+// ElasticsearchDataStream.
+func ElasticsearchIndex(pattern string) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		segs, err := parseElasticsearchIndex(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: ElasticsearchIndex: %w", ErrInvalidConfiguration, err)
+		}
+		t.index = segs
+		return t.batch.configure(esDataStream, false)
+	}
+}
+
+// ElasticsearchRetry configures the number of times a request is retried
+// if it fails with an error that may succeed if retried (e.g. a network
+// error or a 429 or 5xx response), and the delay before the first retry.
+// The delay is doubled for each subsequent retry.
+//
+// By default requests are not retried; a batch that cannot be sent is
+// retained and sent again when next flushed.  Similarly, documents in a
+// batch that are not indexed but may succeed if sent again are retained
+// when other documents in the batch are indexed successfully.
+func ElasticsearchRetry(n int, backoff time.Duration) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: ElasticsearchRetry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(esRetry, retryConfig{n, backoff})
+	}
+}
+
+// ElasticsearchTimeout configures the timeout for requests.  The default
+// is 5 seconds.
+func ElasticsearchTimeout(d time.Duration) ElasticsearchOption {
+	return func(t *elasticsearch) error {
+		return t.batch.configure(esTimeout, d)
+	}
+}
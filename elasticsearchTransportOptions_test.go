@@ -0,0 +1,222 @@
+package ulog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestElasticsearchTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		bh *elasticsearchBatchHandler
+		es *elasticsearch
+	)
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "ElasticsearchAPIKey",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchAPIKey("key")(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("ApiKey key")
+			},
+		},
+		{scenario: "ElasticsearchBasicAuth",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchBasicAuth("user", "password")(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("Basic dXNlcjpwYXNzd29yZA==")
+			},
+		},
+		{scenario: "ElasticsearchBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchBatching(BatchMaxEntries(42))(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, es.batch.max).Equals(42)
+			},
+		},
+		{scenario: "ElasticsearchDataStream",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchDataStream("logs-app-default")(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, es.index).Equals([]esIndexSegment{{s: "logs-app-default"}})
+				test.That(t, bh.op).Equals("create")
+			},
+		},
+		{scenario: "ElasticsearchDataStream/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					ElasticsearchDataStream("logs-{2006}")(es),
+					ElasticsearchDataStream("Logs")(es),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ElasticsearchEndpoint/with path",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchEndpoint("https://es.example.com/logs/_bulk")(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("https://es.example.com/logs/_bulk")
+			},
+		},
+		{scenario: "ElasticsearchEndpoint/without path",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchEndpoint("http://localhost:9200")(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("http://localhost:9200/_bulk")
+			},
+		},
+		{scenario: "ElasticsearchEndpoint/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					ElasticsearchEndpoint("\n")(es),
+					ElasticsearchEndpoint("localhost:9200")(es),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ElasticsearchHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchHeaders(map[string]string{"X-Custom": "value"})(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "ElasticsearchHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := ElasticsearchHTTPClient(client)(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "ElasticsearchHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchHTTPClient(nil)(es)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ElasticsearchIndex",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				bh.op = "create"
+
+				// ACT
+				err := ElasticsearchIndex("logs-{2006.01}.{02}{}")(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, es.index).Equals([]esIndexSegment{
+					{s: "logs-"},
+					{s: "2006.01", layout: true},
+					{s: "."},
+					{s: "02", layout: true},
+				})
+				test.That(t, bh.op).Equals("index")
+			},
+		},
+		{scenario: "ElasticsearchIndex/invalid",
+			exec: func(t *testing.T) {
+				testcases := []string{
+					"",
+					"_logs",
+					"logs-{2006",
+					"logs/app",
+					"logs-{15:04}",
+					"Logs",
+				}
+				for _, tc := range testcases {
+					t.Run(tc, func(t *testing.T) {
+						// ACT
+						err := ElasticsearchIndex(tc)(es)
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidConfiguration)
+					})
+				}
+			},
+		},
+		{scenario: "ElasticsearchRetry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchRetry(3, time.Second)(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.retries).Equals(3)
+				test.That(t, bh.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "ElasticsearchRetry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchRetry(1, -time.Second)(es)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "ElasticsearchTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := ElasticsearchTimeout(time.Minute)(es)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newElasticsearchBatchHandler()
+			es = &elasticsearch{batchTransport: batchTransport{batch: &Batch{}}}
+			es.batch.init(bh, 16)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
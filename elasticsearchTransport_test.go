@@ -0,0 +1,211 @@
+package ulog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestElasticsearchTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// ElasticsearchTransport tests
+		{scenario: "ElasticsearchTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*elasticsearch) error { return opterr }
+
+				// ACT
+				result, err := ElasticsearchTransport(ElasticsearchEndpoint("http://localhost:9200"), opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "ElasticsearchTransport/no endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := ElasticsearchTransport(ElasticsearchIndex("logs"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrElasticsearchConfiguration)
+			},
+		},
+		{scenario: "ElasticsearchTransport/no index",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := ElasticsearchTransport(ElasticsearchEndpoint("http://localhost:9200"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrElasticsearchConfiguration)
+			},
+		},
+		{scenario: "ElasticsearchTransport/with endpoint and index",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := ElasticsearchTransport(
+					ElasticsearchEndpoint("http://localhost:9200"),
+					ElasticsearchIndex("logs"),
+				)()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*elasticsearch](t, result); ok {
+					test.That(t, result.batch.max, "batch capacity").Equals(100)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(10 * time.Second)
+					if handler, ok := test.IsType[*elasticsearchBatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.endpoint, "endpoint").Equals("http://localhost:9200/_bulk")
+						test.That(t, handler.op, "op").Equals("index")
+					}
+				}
+			},
+		},
+
+		// indexName tests
+		{scenario: "indexName/literal",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &elasticsearch{index: []esIndexSegment{{s: "logs"}}}
+
+				// ACT
+				result := sut.indexName(tm)
+
+				// ASSERT
+				test.That(t, result).Equals("logs")
+			},
+		},
+		{scenario: "indexName/with time layouts",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &elasticsearch{index: []esIndexSegment{
+					{s: "logs-"},
+					{s: "2006.01.02", layout: true},
+					{s: "-"},
+					{s: "Jan", layout: true},
+				}}
+
+				// ACT
+				result := sut.indexName(tm)
+
+				// ASSERT
+				test.That(t, result).Equals("logs-2010.09.08-sep")
+			},
+		},
+		{scenario: "indexName/non-UTC time",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &elasticsearch{index: []esIndexSegment{
+					{s: "logs-"},
+					{s: "2006.01.02", layout: true},
+				}}
+				tm := time.Date(2010, 9, 8, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+
+				// ACT
+				result := sut.indexName(tm)
+
+				// ASSERT
+				test.That(t, result).Equals("logs-2010.09.09")
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &elasticsearch{
+					batchTransport: batchTransport{ch: make(chan []byte, 1)},
+					index:          []esIndexSegment{{s: "logs-"}, {s: "2006", layout: true}},
+				}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(`{"message":"` + e.Message + "\"}\n") })
+
+				// ASSERT
+				index, doc := decodeElasticsearchRecord(<-sut.ch)
+				test.That(t, string(index)).Equals("logs-2010")
+				test.That(t, string(doc)).Equals(`{"message":"message"}`)
+			},
+		},
+
+		// end-to-end
+		{scenario: "retries failed documents",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					mu       sync.Mutex
+					requests [][]byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					mu.Lock()
+					defer mu.Unlock()
+					requests = append(requests, body)
+					if len(requests) == 1 {
+						_, _ = w.Write([]byte(`{"errors":true,"items":[` +
+							`{"index":{"status":201}},` +
+							`{"index":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}},` +
+							`{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}` +
+							`]}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(JSONFormatter()),
+							TargetTransport(ElasticsearchTransport(
+								ElasticsearchEndpoint(srv.URL),
+								ElasticsearchIndex("logs-{2006.01.02}"),
+								ElasticsearchBatching(BatchMaxEntries(3), BatchMaxLatency(10*time.Millisecond)),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("entry 1")
+				logger.Info("entry 2")
+				logger.Info("entry 3")
+				time.Sleep(50 * time.Millisecond)
+				closelog()
+
+				// ASSERT
+				mu.Lock()
+				defer mu.Unlock()
+				test.That(t, len(requests), "requests").Equals(2)
+				test.That(t, bytes.Count(requests[0], []byte("\n")), "lines in first request").Equals(6)
+				test.That(t, bytes.Count(requests[1], []byte("\n")), "lines in second request").Equals(2)
+				test.IsTrue(t, bytes.Contains(requests[0], []byte(`{"index":{"_index":"logs-`)), "action line")
+				test.IsTrue(t, bytes.Contains(requests[1], []byte(`"message":"entry 2"`)), "retried document")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
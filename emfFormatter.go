@@ -0,0 +1,192 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type EMFFormatterOption func(*emffmt) error // EMFFormatterOption is a function for configuring a CloudWatch EMF formatter
+
+// EMFUnit is the unit of a CloudWatch metric.
+type EMFUnit string
+
+const (
+	EMFNone         EMFUnit = "None"         // EMFNone indicates a metric with no unit
+	EMFCount        EMFUnit = "Count"        // EMFCount indicates a metric that is a count
+	EMFPercent      EMFUnit = "Percent"      // EMFPercent indicates a metric that is a percentage
+	EMFSeconds      EMFUnit = "Seconds"      // EMFSeconds indicates a metric in seconds
+	EMFMilliseconds EMFUnit = "Milliseconds" // EMFMilliseconds indicates a metric in milliseconds
+	EMFMicroseconds EMFUnit = "Microseconds" // EMFMicroseconds indicates a metric in microseconds
+	EMFBytes        EMFUnit = "Bytes"        // EMFBytes indicates a metric in bytes
+	EMFKilobytes    EMFUnit = "Kilobytes"    // EMFKilobytes indicates a metric in kilobytes
+	EMFMegabytes    EMFUnit = "Megabytes"    // EMFMegabytes indicates a metric in megabytes
+	EMFCountPerSec  EMFUnit = "Count/Second" // EMFCountPerSec indicates a metric that is a rate, per second
+)
+
+// emfDurationUnits is the duration of one unit of each time unit, used to
+// convert time.Duration metric values.
+var emfDurationUnits = map[EMFUnit]time.Duration{
+	EMFSeconds:      time.Second,
+	EMFMilliseconds: time.Millisecond,
+	EMFMicroseconds: time.Microsecond,
+}
+
+// EMFFormatter returns a function that configures a formatter that writes
+// log entries as JSON using the CloudWatch Embedded Metric Format (EMF),
+// with specified configuration options applied.  When written to stdout
+// on AWS Lambda or ECS (or sent to CloudWatch Logs by the CloudWatch
+// agent) metrics are extracted from the logs by CloudWatch.
+//
+// Fields that provide metric values are configured with their units (see:
+// EMFMetrics).  When an entry has one or more metric fields with a numeric
+// value, the log includes an _aws metadata object with a
+// CloudWatchMetrics directive identifying the namespace (see:
+// EMFNamespace), the metrics present in the entry and the dimension sets
+// (see: EMFDimensions) for which the entry has a value for every dimension.
+// A time.Duration metric value is converted to the unit of the metric if
+// that unit is Seconds, Milliseconds or Microseconds.
+//
+// Each log has the following fields:
+//
+//   - level is the Level of the entry (trace, debug, info, warn, error or
+//     fatal)
+//   - message is the message of the entry
+//   - the fields of the entry, with error values written as the error
+//     string and dimension values written as strings
+//   - any static properties (see: EMFProperties)
+//   - _aws, if the entry has metric values
+//
+// The default namespace is the name of the executable.  An entry with no
+// metric values is written as a JSON log with no _aws metadata.
+func EMFFormatter(opts ...EMFFormatterOption) FormatterFactory {
+	return func() (Formatter, error) {
+		ef := &emffmt{
+			namespace: filepath.Base(os.Args[0]),
+			metrics:   map[string]EMFUnit{},
+		}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(ef))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		return ef, nil
+	}
+}
+
+type emffmt struct {
+	namespace  string             // the CloudWatch namespace of metrics
+	metrics    map[string]EMFUnit // the unit of each metric, by field name
+	order      []string           // the names of metric fields, in the order in which they were configured
+	dimensions [][]string         // dimension sets
+	properties map[string]any     // static properties added to all logs
+}
+
+// emfMetric is a metric definition in a CloudWatchMetrics directive.
+type emfMetric struct {
+	Name string  `json:"Name"`
+	Unit EMFUnit `json:"Unit"`
+}
+
+// emfDirective is a CloudWatchMetrics directive.
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+// emfMetadata is the _aws metadata object of an EMF log.
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// emfValue returns the numeric value of a metric in a specified unit and
+// true, or nil and false if the value is not numeric or is not finite (NaN
+// or ±Inf cannot be encoded as JSON).
+func emfValue(v any, unit EMFUnit) (any, bool) {
+	if d, ok := v.(time.Duration); ok {
+		if per, ok := emfDurationUnits[unit]; ok {
+			return float64(d) / float64(per), true
+		}
+		return int64(d), true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v, true
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Format implements a Formatter that writes log entries as EMF logs.
+func (w *emffmt) Format(id int, e entry, b ByteWriter) {
+	log := make(map[string]any, len(w.properties)+2)
+	for k, v := range w.properties {
+		log[k] = v
+	}
+
+	log["level"] = strings.ToLower(e.Level.String())
+	log["message"] = e.Message
+
+	var flds map[string]any
+	if e.logcontext != nil && e.fields != nil {
+		flds = e.fields.m
+	}
+	for k, v := range flds {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		log[k] = v
+	}
+
+	directive := emfDirective{Namespace: w.namespace, Dimensions: [][]string{}}
+	for _, k := range w.order {
+		v, ok := flds[k]
+		if !ok {
+			continue
+		}
+		unit := w.metrics[k]
+		if v, ok = emfValue(v, unit); ok {
+			log[k] = v
+			directive.Metrics = append(directive.Metrics, emfMetric{Name: k, Unit: unit})
+		}
+	}
+
+	if len(directive.Metrics) > 0 {
+	sets:
+		for _, set := range w.dimensions {
+			for _, dim := range set {
+				v, ok := log[dim]
+				if !ok {
+					continue sets
+				}
+				if _, ok := v.(string); !ok {
+					log[dim] = fmt.Sprintf("%v", v)
+				}
+			}
+			directive.Dimensions = append(directive.Dimensions, set)
+		}
+		log["_aws"] = emfMetadata{
+			Timestamp:         e.Time.UnixMilli(),
+			CloudWatchMetrics: []emfDirective{directive},
+		}
+	}
+
+	writeJSONObject(b, log, "EMF")
+}
@@ -0,0 +1,86 @@
+package ulog
+
+import (
+	"fmt"
+	"slices"
+)
+
+// EMFDimensions configures a dimension set: the names of fields (or static
+// properties) whose values identify the dimensions of metrics.  Metrics are
+// published for the dimension set only if every dimension has a value.
+// This option may be specified multiple times to configure multiple
+// dimension sets.
+//
+// By default no dimension sets are configured; metrics are published with
+// no dimensions.
+func EMFDimensions(names ...string) EMFFormatterOption {
+	return func(ef *emffmt) error {
+		if len(names) == 0 {
+			return fmt.Errorf("%w: EMFDimensions: at least one dimension is required", ErrInvalidConfiguration)
+		}
+		if len(names) > 30 {
+			return fmt.Errorf("%w: EMFDimensions: %d dimensions: a dimension set may have at most 30 dimensions", ErrInvalidConfiguration, len(names))
+		}
+		ef.dimensions = append(ef.dimensions, slices.Clone(names))
+		return nil
+	}
+}
+
+// EMFMetrics configures the fields that provide metric values, with the
+// unit of each metric, e.g.:
+//
+//	ulog.EMFMetrics(map[string]ulog.EMFUnit{
+//	    "latency":  ulog.EMFMilliseconds,
+//	    "requests": ulog.EMFCount,
+//	})
+//
+// This option may be specified multiple times; metrics are accumulated,
+// with any metric specified more than once taking the most recently
+// configured unit.
+func EMFMetrics(metrics map[string]EMFUnit) EMFFormatterOption {
+	return func(ef *emffmt) error {
+		names := make([]string, 0, len(metrics))
+		for k := range metrics {
+			names = append(names, k)
+		}
+		slices.Sort(names)
+
+		for _, k := range names {
+			if _, ok := ef.metrics[k]; !ok {
+				ef.order = append(ef.order, k)
+			}
+			ef.metrics[k] = metrics[k]
+		}
+		return nil
+	}
+}
+
+// EMFNamespace configures the CloudWatch namespace of metrics.  The default
+// is the name of the executable.
+func EMFNamespace(s string) EMFFormatterOption {
+	return func(ef *emffmt) error {
+		if s == "" {
+			return fmt.Errorf("%w: EMFNamespace: namespace is required", ErrInvalidConfiguration)
+		}
+		ef.namespace = s
+		return nil
+	}
+}
+
+// EMFProperties configures static properties that are added to all logs,
+// e.g. to provide the value of a dimension common to all metrics (such as
+// a service name).  A field of an entry with the same name as a property
+// replaces the property.  This option may be specified multiple times;
+// properties are accumulated, with any property specified more than once
+// taking the most recently configured value.
+func EMFProperties(props map[string]any) EMFFormatterOption {
+	return func(ef *emffmt) error {
+		if ef.properties == nil {
+			ef.properties = make(map[string]any, len(props))
+		}
+		for k, v := range props {
+			ef.properties[k] = v
+		}
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestEMFFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *emffmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "EMFDimensions",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := EMFDimensions("service")(sut)
+				err2 := EMFDimensions("service", "route")(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, sut.dimensions).Equals([][]string{{"service"}, {"service", "route"}})
+			},
+		},
+		{scenario: "EMFDimensions/none",
+			exec: func(t *testing.T) {
+				// ACT
+				err := EMFDimensions()(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "EMFDimensions/too many",
+			exec: func(t *testing.T) {
+				// ACT
+				err := EMFDimensions(make([]string, 31)...)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "EMFMetrics",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := EMFMetrics(map[string]EMFUnit{"requests": EMFCount, "latency": EMFSeconds})(sut)
+				err2 := EMFMetrics(map[string]EMFUnit{"latency": EMFMilliseconds, "bytes": EMFBytes})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Map(t, sut.metrics).Equals(map[string]EMFUnit{
+					"requests": EMFCount,
+					"latency":  EMFMilliseconds,
+					"bytes":    EMFBytes,
+				})
+				test.Slice(t, sut.order).Equals([]string{"latency", "requests", "bytes"})
+			},
+		},
+		{scenario: "EMFNamespace",
+			exec: func(t *testing.T) {
+				// ACT
+				err := EMFNamespace("ns")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.namespace).Equals("ns")
+			},
+		},
+		{scenario: "EMFNamespace/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := EMFNamespace("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "EMFProperties",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := EMFProperties(map[string]any{"a": 1})(sut)
+				err2 := EMFProperties(map[string]any{"b": "2"})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Map(t, sut.properties).Equals(map[string]any{"a": 1, "b": "2"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &emffmt{metrics: map[string]EMFUnit{}}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
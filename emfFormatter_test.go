@@ -0,0 +1,209 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestEMFFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	// format returns the log formatted for an entry, decoded into a map
+	format := func(t *testing.T, sut *emffmt, e entry) map[string]any {
+		buf := &bytes.Buffer{}
+		sut.Format(0, e, buf)
+		m := map[string]any{}
+		test.Error(t, json.Unmarshal(buf.Bytes(), &m)).IsNil()
+		return m
+	}
+	withFields := func(m map[string]any) entry {
+		return entry{
+			logcontext: &logcontext{fields: newFields(1).merge(m)},
+			Time:       tm,
+			Level:      InfoLevel,
+			Message:    "message",
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// EMFFormatter tests
+		{scenario: "EMFFormatter",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := EMFFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*emffmt)).Equals(&emffmt{
+					namespace: filepath.Base(os.Args[0]),
+					metrics:   map[string]EMFUnit{},
+				})
+			},
+		},
+		{scenario: "EMFFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := EMFFormatter(func(*emffmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// emfValue tests
+		{scenario: "emfValue",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name  string
+					value any
+					unit  EMFUnit
+					ok    bool
+					want  any
+				}{
+					{name: "int", value: 42, unit: EMFCount, ok: true, want: 42},
+					{name: "uint8", value: uint8(7), unit: EMFCount, ok: true, want: uint8(7)},
+					{name: "float", value: 1.5, unit: EMFPercent, ok: true, want: 1.5},
+					{name: "duration/milliseconds", value: 1500 * time.Microsecond, unit: EMFMilliseconds, ok: true, want: 1.5},
+					{name: "duration/seconds", value: 2 * time.Second, unit: EMFSeconds, ok: true, want: 2.0},
+					{name: "duration/other unit", value: time.Microsecond, unit: EMFNone, ok: true, want: int64(1000)},
+					{name: "string", value: "42", unit: EMFCount},
+					{name: "nil", value: nil, unit: EMFCount},
+					{name: "NaN", value: math.NaN(), unit: EMFCount},
+					{name: "+Inf", value: math.Inf(1), unit: EMFCount},
+					{name: "-Inf", value: float32(math.Inf(-1)), unit: EMFCount},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result, ok := emfValue(tc.value, tc.unit)
+
+						// ASSERT
+						test.That(t, ok).Equals(tc.ok)
+						test.That(t, result).Equals(tc.want)
+					})
+				}
+			},
+		},
+
+		// Format tests
+		{scenario: "Format/no metrics",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &emffmt{namespace: "ns", metrics: map[string]EMFUnit{"latency": EMFMilliseconds}, order: []string{"latency"}}
+
+				// ACT
+				result := format(t, sut, withFields(map[string]any{"key": "value", "err": errors.New("failed")}))
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"level":   "info",
+					"message": "message",
+					"key":     "value",
+					"err":     "failed",
+				})
+			},
+		},
+		{scenario: "Format/metrics",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &emffmt{
+					namespace: "ns",
+					metrics: map[string]EMFUnit{
+						"latency":  EMFMilliseconds,
+						"requests": EMFCount,
+						"size":     EMFBytes,
+					},
+					order:      []string{"latency", "requests", "size"},
+					dimensions: [][]string{{"service"}, {"service", "status"}, {"service", "route"}},
+					properties: map[string]any{"service": "api"},
+				}
+				e := withFields(map[string]any{
+					"latency":  250 * time.Millisecond,
+					"requests": 1,
+					"size":     "not a number",
+					"status":   200,
+				})
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"level":    "info",
+					"message":  "message",
+					"service":  "api",
+					"latency":  float64(250),
+					"requests": float64(1),
+					"size":     "not a number",
+					"status":   "200",
+					"_aws": map[string]any{
+						"Timestamp": float64(tm.UnixMilli()),
+						"CloudWatchMetrics": []any{
+							map[string]any{
+								"Namespace": "ns",
+								"Dimensions": []any{
+									[]any{"service"},
+									[]any{"service", "status"},
+								},
+								"Metrics": []any{
+									map[string]any{"Name": "latency", "Unit": "Milliseconds"},
+									map[string]any{"Name": "requests", "Unit": "Count"},
+								},
+							},
+						},
+					},
+				})
+			},
+		},
+		{scenario: "Format/metrics/not finite",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &emffmt{namespace: "ns", metrics: map[string]EMFUnit{"ratio": EMFPercent}, order: []string{"ratio"}}
+
+				// ACT
+				result := format(t, sut, withFields(map[string]any{"ratio": math.NaN()}))
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"level":   "info",
+					"message": "message",
+					"ratio":   "EMF_ERROR: marshalling error: json: unsupported value: NaN",
+				})
+			},
+		},
+		{scenario: "Format/metrics/no dimensions",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &emffmt{namespace: "ns", metrics: map[string]EMFUnit{"count": EMFCount}, order: []string{"count"}}
+
+				// ACT
+				buf := &bytes.Buffer{}
+				sut.Format(0, withFields(map[string]any{"count": 3}), buf)
+
+				// ASSERT
+				test.That(t, buf.String()).Equals(`{"_aws":{"Timestamp":1283929565432,"CloudWatchMetrics":[{"Namespace":"ns","Dimensions":[],"Metrics":[{"Name":"count","Unit":"Count"}]}]},"count":3,"level":"info","message":"message"}`)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -3,15 +3,29 @@ package ulog
 import "errors"
 
 var (
-	ErrBackendNotConfigured    = errors.New("a backend must be configured first")
-	ErrFormatAlreadyRegistered = errors.New("a format with this id is already registered")
-	ErrInvalidConfiguration    = errors.New("invalid configuration")
-	ErrInvalidFormatReference  = errors.New("invalid type for format; must be a Formatter or the (string) id of a Formatter previously added to the mux")
-	ErrKeyNotSupported         = errors.New("key not supported")
-	ErrLogtailConfiguration    = errors.New("logtail transport configuration")
-	ErrNoLoggerInContext       = errors.New("no logger in context")
-	ErrNotImplemented          = errors.New("not implemented")
-	ErrUnknownFormat           = errors.New("unknown format")
+	ErrBackendNotConfigured       = errors.New("a backend must be configured first")
+	ErrDatadogConfiguration       = errors.New("datadog transport configuration")
+	ErrElasticsearchConfiguration = errors.New("elasticsearch transport configuration")
+	ErrFileConfiguration          = errors.New("file transport configuration")
+	ErrFluentConfiguration        = errors.New("fluent transport configuration")
+	ErrFormatAlreadyRegistered    = errors.New("a format with this id is already registered")
+	ErrGELFConfiguration          = errors.New("gelf transport configuration")
+	ErrHTTPConfiguration          = errors.New("http transport configuration")
+	ErrInvalidConfiguration       = errors.New("invalid configuration")
+	ErrInvalidFormatReference     = errors.New("invalid type for format; must be a Formatter or the (string) id of a Formatter previously added to the mux")
+	ErrKeyNotSupported            = errors.New("key not supported")
+	ErrLogtailConfiguration       = errors.New("logtail transport configuration")
+	ErrLokiConfiguration          = errors.New("loki transport configuration")
+	ErrNetConfiguration           = errors.New("net transport configuration")
+	ErrNoLoggerInContext          = errors.New("no logger in context")
+	ErrNotImplemented             = errors.New("not implemented")
+	ErrOTLPConfiguration          = errors.New("otlp transport configuration")
+	ErrS3Configuration            = errors.New("s3 transport configuration")
+	ErrSplunkConfiguration        = errors.New("splunk transport configuration")
+	ErrSyslogConfiguration        = errors.New("syslog transport configuration")
+	ErrUnexpectedResponse         = errors.New("unexpected response")
+	ErrUnknownFormat              = errors.New("unknown format")
+	ErrWebhookConfiguration       = errors.New("webhook transport configuration")
 
 	// errors returns by the mock listener when expectations are not met
 	ErrExpectationsNotMet      = errors.New("expectations were not met")
@@ -80,6 +80,43 @@ func (f *fields) merge(with map[string]any) *fields {
 	return cpy
 }
 
+// without returns a copy of the fields with the specified keys removed.
+// If none of the keys are present, the original fields are returned.
+// If all fields are removed, nil is returned.
+//
+// The copy does not inherit any formatted bytes cached by the original
+// fields.
+func (f *fields) without(keys ...string) *fields {
+	if f == nil {
+		return nil
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	n := 0
+	for _, k := range keys {
+		if _, ok := f.m[k]; ok {
+			n++
+		}
+	}
+	if n == 0 {
+		return f
+	}
+
+	cpy := newFields(len(f.m) - n)
+	if cpy == nil {
+		return nil
+	}
+	for k, v := range f.m {
+		cpy.m[k] = v
+	}
+	for _, k := range keys {
+		delete(cpy.m, k)
+	}
+	return cpy
+}
+
 // getFormattedBytes returns the bytes.Buffer for the specified key or
 // a new *bytes.Buffer if no buffer exists for the key.
 //
@@ -137,6 +137,59 @@ func Test_fields(t *testing.T) {
 			},
 		},
 
+		// without tests
+		{scenario: "without(keys)/nil receiver",
+			exec: func(t *testing.T) {
+				// ACT
+				result := ((*fields)(nil)).without("k1")
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "without(keys)/no keys present",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.m = map[string]any{"k1": "v1"}
+
+				// ACT
+				result := sut.without("k2")
+
+				// ASSERT
+				IsSyncSafe(t, false, mx)
+				test.Value(t, result).Equals(sut)
+			},
+		},
+		{scenario: "without(keys)/some keys present",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.m = map[string]any{"k1": "v1", "k2": "v2", "k3": "v3"}
+				sut.b = map[int][]byte{0: []byte("cached")}
+
+				// ACT
+				result := sut.without("k1", "k3", "k4")
+
+				// ASSERT
+				IsSyncSafe(t, false, mx)
+				test.Value(t, result).DoesNotEqual(sut)
+				test.That(t, result.m).Equals(map[string]any{"k2": "v2"})
+				test.That(t, len(result.b)).Equals(0)
+				test.That(t, len(sut.m), "original fields").Equals(3)
+			},
+		},
+		{scenario: "without(keys)/all keys present",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.m = map[string]any{"k1": "v1"}
+
+				// ACT
+				result := sut.without("k1")
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+
 		// getFormattedBytes tests
 		{scenario: "getFormattedBytes(id)/nil receiver",
 			exec: func(t *testing.T) {
@@ -0,0 +1,368 @@
+package ulog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FileOption = func(*file) error // FileOption is a function that configures a file transport
+
+// fileTimeFormat is the format of the timestamp in the name of a rotated
+// file.
+const fileTimeFormat = "2006-01-02T15-04-05.000"
+
+// FileTransport returns a transport factory function to create and
+// configure a transport that writes log entries to a file, with specified
+// configuration options applied.  The file (and any directories in the
+// path) are created if they do not exist; entries are appended to an
+// existing file.
+//
+// Each entry is formatted by the target Formatter and written to the file
+// followed by a newline.
+//
+// The file is rotated when writing an entry would exceed a maximum size
+// (see: FileMaxSize) or when the entry falls in a different period to the
+// first entry written to the file (see: FileRotateEvery).  A rotated file
+// is renamed with the time of rotation inserted before the extension:
+//
+//	/var/log/app.log  =>  /var/log/app-2006-01-02T15-04-05.000.log
+//
+// Rotated files may be compressed (see: FileCompress) and are removed when
+// they exceed a maximum number (see: FileMaxBackups) or age (see:
+// FileMaxAge).  Compression and removal of rotated files is performed in
+// the background and is completed before the transport is stopped.
+//
+// The transport may be configured to close and re-open the file when the
+// process receives a signal (e.g. SIGHUP), for compatibility with external
+// tools such as logrotate (see: FileReopenOnSIGHUP and FileReopenSignals).
+// By default, no signals are handled.  A file rotated by logrotate (using
+// a postrotate script that sends SIGHUP to the process) is re-opened by a
+// transport configured as:
+//
+//	FileTransport("/var/log/app.log", FileReopenOnSIGHUP())
+func FileTransport(path string, opts ...FileOption) TransportFactory {
+	return func() (transport, error) {
+		if path == "" {
+			return nil, fmt.Errorf("%w: %w: path is required", ErrFileConfiguration, ErrInvalidConfiguration)
+		}
+
+		t := &file{
+			ch:   make(chan []byte, 100),
+			path: path,
+			mode: 0o644,
+		}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		if err := t.open(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFileConfiguration, err)
+		}
+		return t, nil
+	}
+}
+
+// file implements a transport that writes log entries to a file.
+type file struct {
+	ch          chan []byte    // channel over which entries are received
+	path        string         // the path of the file
+	mode        os.FileMode    // the permissions of a created file
+	maxSize     int64          // the maximum size of the file; 0 if not rotated by size
+	period      time.Duration  // the period of rotation; 0 if not rotated by time
+	maxBackups  int            // the maximum number of rotated files to retain; 0 to retain all
+	maxAge      time.Duration  // the maximum age of rotated files to retain; 0 to retain all
+	compress    bool           // true if rotated files are compressed
+	signals     []os.Signal    // the signals that cause the file to be re-opened
+	f           *os.File       // the open file
+	size        int64          // the current size of the file
+	opened      time.Time      // the start of the period in which the file was opened
+	housekeepCh chan struct{}  // channel used to request housekeeping of rotated files
+	wg          sync.WaitGroup // waits for housekeeping to complete
+}
+
+// open opens (or creates) the file for appending, creating any directories
+// in the path.
+func (t *file) open() error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, t.mode)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	t.f = f
+	t.size = info.Size()
+	t.opened = t.periodStart(now())
+	if t.size > 0 {
+		t.opened = t.periodStart(info.ModTime())
+	}
+	return nil
+}
+
+// close closes the file, if open.
+func (t *file) close() {
+	if t.f == nil {
+		return
+	}
+	if err := t.f.Close(); err != nil {
+		trace("file: error closing file: " + err.Error())
+	}
+	t.f = nil
+}
+
+// reopen closes and re-opens the file.
+func (t *file) reopen() {
+	tracef("file: re-opening %s", t.path)
+	t.close()
+	if err := t.open(); err != nil {
+		trace("file: error opening file: " + err.Error())
+	}
+}
+
+// periodStart returns the start of the rotation period containing a
+// specified time.  If the file is not rotated by time, the zero time
+// is returned.
+func (t *file) periodStart(tm time.Time) time.Time {
+	if t.period == 0 {
+		return time.Time{}
+	}
+	return tm.Truncate(t.period)
+}
+
+// backupName returns the name of a rotated file for a specified time.  If
+// a rotated file (or compressed file) with that name already exists, the
+// time is advanced by a millisecond until the name is unique.
+func (t *file) backupName(tm time.Time) string {
+	ext := filepath.Ext(t.path)
+	base := strings.TrimSuffix(t.path, ext)
+	for {
+		name := base + "-" + tm.UTC().Format(fileTimeFormat) + ext
+		_, err := os.Stat(name)
+		_, gzerr := os.Stat(name + ".gz")
+		if err != nil && gzerr != nil {
+			return name
+		}
+		tm = tm.Add(time.Millisecond)
+	}
+}
+
+// rotate closes the file, renames it and opens a new file.  Housekeeping
+// of rotated files is then requested.
+func (t *file) rotate() {
+	t.close()
+
+	if err := os.Rename(t.path, t.backupName(now())); err != nil {
+		trace("file: error rotating file: " + err.Error())
+	}
+	if err := t.open(); err != nil {
+		trace("file: error opening file: " + err.Error())
+	}
+
+	select {
+	case t.housekeepCh <- struct{}{}:
+	default:
+		// housekeeping is already pending
+	}
+}
+
+// shouldRotate returns true if writing a specified number of bytes to the
+// file requires that the file be rotated first.
+func (t *file) shouldRotate(n int) bool {
+	switch {
+	case t.size == 0:
+		return false
+	case t.maxSize > 0 && t.size+int64(n) > t.maxSize:
+		return true
+	case t.period > 0 && !t.periodStart(now()).Equal(t.opened):
+		return true
+	}
+	return false
+}
+
+// write writes an entry to the file, rotating the file first if required.
+// If the file is not open (because a previous attempt to open it failed)
+// an attempt is made to open it before writing.
+func (t *file) write(b []byte) error {
+	if t.f == nil {
+		if err := t.open(); err != nil {
+			return err
+		}
+	}
+	if t.shouldRotate(len(b)) {
+		t.rotate()
+		if t.f == nil {
+			return errors.New("file is not open")
+		}
+	}
+
+	n, err := t.f.Write(b)
+	t.size += int64(n)
+	return err
+}
+
+// fileBackup identifies a rotated file and the time of rotation.
+type fileBackup struct {
+	path string
+	time time.Time
+}
+
+// backups returns the rotated files of the transport, most recent first.
+func (t *file) backups() ([]fileBackup, error) {
+	ext := filepath.Ext(t.path)
+	prefix := filepath.Base(strings.TrimSuffix(t.path, ext)) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(t.path))
+	if err != nil {
+		return nil, err
+	}
+
+	result := []fileBackup{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimPrefix(name, prefix)
+		ts = strings.TrimSuffix(ts, ".gz")
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		tm, err := time.Parse(fileTimeFormat, strings.TrimSuffix(ts, ext))
+		if err != nil {
+			continue
+		}
+		result = append(result, fileBackup{filepath.Join(filepath.Dir(t.path), name), tm})
+	}
+	slices.SortFunc(result, func(a, b fileBackup) int { return b.time.Compare(a.time) })
+	return result, nil
+}
+
+// housekeep removes rotated files exceeding the maximum number or age
+// of files to be retained and compresses any remaining files (if
+// configured).
+func (t *file) housekeep() {
+	backups, err := t.backups()
+	if err != nil {
+		trace("file: housekeeping failed: " + err.Error())
+		return
+	}
+
+	cutoff := now().Add(-t.maxAge)
+	for i, b := range backups {
+		if (t.maxBackups > 0 && i >= t.maxBackups) || (t.maxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil {
+				trace("file: error removing rotated file: " + err.Error())
+			}
+			continue
+		}
+		if t.compress && !strings.HasSuffix(b.path, ".gz") {
+			if err := compressFile(b.path, t.mode); err != nil {
+				trace("file: error compressing rotated file: " + err.Error())
+			}
+		}
+	}
+}
+
+// compressFile compresses a file using gzip, removing the original file.
+func compressFile(path string, mode os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	err = errors.Join(err, gz.Close(), dst.Close())
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// log implements the transport interface.  A copy of the formatted entry,
+// terminated by a newline, is sent to the transport channel.
+func (t *file) log(b []byte) {
+	rec := make([]byte, len(b), len(b)+1)
+	copy(rec, b)
+	t.ch <- append(rec, '\n')
+}
+
+// stop closes the channel over which entries are received.
+func (t *file) stop() {
+	tracef("file: transport requested to stop...")
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  Entries are written
+// to the file as they are received and the file is re-opened when any
+// of the configured signals are received.
+//
+// The run loop terminates when the channel over which entries are
+// received is closed, after any remaining entries have been written and
+// any pending housekeeping of rotated files has completed.  The file is
+// then closed.
+func (t *file) run() {
+	t.housekeepCh = make(chan struct{}, 1)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for range t.housekeepCh {
+			t.housekeep()
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	if len(t.signals) > 0 {
+		signal.Notify(sig, t.signals...)
+	}
+
+	defer func() {
+		signal.Stop(sig)
+		close(t.housekeepCh)
+		t.wg.Wait()
+		t.close()
+		tracef("file: transport stopped")
+	}()
+
+	for {
+		select {
+		case <-sig:
+			t.reopen()
+
+		case b, ok := <-t.ch:
+			if !ok {
+				return
+			}
+			if err := t.write(b); err != nil {
+				trace("file: entry discarded: " + err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ulog
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// FileCompress configures whether rotated files are compressed using gzip.
+// Compressed files have a ".gz" extension appended to the name of the
+// rotated file.  The default is false.
+func FileCompress(b bool) FileOption {
+	return func(t *file) error {
+		t.compress = b
+		return nil
+	}
+}
+
+// FileMaxAge configures the maximum age of rotated files to be retained,
+// based on the time of rotation in the name of each file.  The default
+// is 0 (rotated files are not removed based on their age).
+func FileMaxAge(d time.Duration) FileOption {
+	return func(t *file) error {
+		if d < 0 {
+			return fmt.Errorf("%w: FileMaxAge: must be >= 0", ErrInvalidConfiguration)
+		}
+		t.maxAge = d
+		return nil
+	}
+}
+
+// FileMaxBackups configures the maximum number of rotated files to be
+// retained; the oldest files are removed first.  The default is 0 (all
+// rotated files are retained, subject to any FileMaxAge).
+func FileMaxBackups(n int) FileOption {
+	return func(t *file) error {
+		if n < 0 {
+			return fmt.Errorf("%w: FileMaxBackups: must be >= 0", ErrInvalidConfiguration)
+		}
+		t.maxBackups = n
+		return nil
+	}
+}
+
+// FileMaxSize configures the maximum size of the file, in bytes.  The file
+// is rotated when writing an entry would cause the file to exceed this size.
+// An entry larger than the maximum size is written to a new file on its own.
+// The default is 0 (the file is not rotated based on its size).
+func FileMaxSize(n int64) FileOption {
+	return func(t *file) error {
+		if n < 0 {
+			return fmt.Errorf("%w: FileMaxSize: must be >= 0", ErrInvalidConfiguration)
+		}
+		t.maxSize = n
+		return nil
+	}
+}
+
+// FilePermissions configures the permissions of the file (and of any
+// compressed rotated files) when created.  The default is 0644.
+func FilePermissions(mode os.FileMode) FileOption {
+	return func(t *file) error {
+		if mode&^os.ModePerm != 0 {
+			return fmt.Errorf("%w: FilePermissions: %v: must specify permission bits only", ErrInvalidConfiguration, mode)
+		}
+		t.mode = mode
+		return nil
+	}
+}
+
+// FileReopenOnSIGHUP configures the file to be closed and re-opened when
+// the process receives SIGHUP, for compatibility with logrotate.  It is
+// equivalent to FileReopenSignals(syscall.SIGHUP) and is subject to the
+// same process-wide effect (see: FileReopenSignals).
+func FileReopenOnSIGHUP() FileOption {
+	return FileReopenSignals(syscall.SIGHUP)
+}
+
+// FileReopenSignals configures the signals that cause the file to be closed
+// and re-opened, e.g. SIGHUP for compatibility with logrotate.  By default
+// no signals are handled.
+//
+// The signals are handled using signal.Notify, which affects the whole
+// process: a signal that would otherwise terminate the process (such as
+// SIGHUP) no longer does so while the transport is running.
+func FileReopenSignals(sigs ...os.Signal) FileOption {
+	return func(t *file) error {
+		t.signals = sigs
+		return nil
+	}
+}
+
+// FileRotateEvery configures the file to be rotated periodically.  Periods
+// are aligned to the zero time (UTC), so a period of 24 hours rotates the
+// file at the first entry written after midnight UTC.  The default is 0
+// (the file is not rotated periodically).
+func FileRotateEvery(d time.Duration) FileOption {
+	return func(t *file) error {
+		if d < 0 {
+			return fmt.Errorf("%w: FileRotateEvery: must be >= 0", ErrInvalidConfiguration)
+		}
+		t.period = d
+		return nil
+	}
+}
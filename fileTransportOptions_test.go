@@ -0,0 +1,168 @@
+package ulog
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestFileTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *file
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "FileCompress",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileCompress(true)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, sut.compress)
+			},
+		},
+		{scenario: "FileMaxAge",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileMaxAge(24 * time.Hour)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.maxAge).Equals(24 * time.Hour)
+			},
+		},
+		{scenario: "FileMaxAge/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileMaxAge(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FileMaxBackups",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileMaxBackups(5)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.maxBackups).Equals(5)
+			},
+		},
+		{scenario: "FileMaxBackups/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileMaxBackups(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FileMaxSize",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileMaxSize(1 << 20)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.maxSize).Equals(int64(1 << 20))
+			},
+		},
+		{scenario: "FileMaxSize/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileMaxSize(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FilePermissions",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FilePermissions(0o600)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.mode).Equals(os.FileMode(0o600))
+			},
+		},
+		{scenario: "FilePermissions/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FilePermissions(os.ModeDir | 0o755)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FileReopenOnSIGHUP",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileReopenOnSIGHUP()(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, sut.signals).Equals([]os.Signal{syscall.SIGHUP})
+			},
+		},
+		{scenario: "FileReopenSignals",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileReopenSignals(syscall.SIGHUP, syscall.SIGTERM)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, sut.signals).Equals([]os.Signal{syscall.SIGHUP, syscall.SIGTERM})
+			},
+		},
+		{scenario: "FileReopenSignals/none",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.signals = []os.Signal{syscall.SIGHUP}
+
+				// ACT
+				err := FileReopenSignals()(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(sut.signals)).Equals(0)
+			},
+		},
+		{scenario: "FileRotateEvery",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileRotateEvery(time.Hour)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.period).Equals(time.Hour)
+			},
+		},
+		{scenario: "FileRotateEvery/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FileRotateEvery(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &file{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,521 @@
+package ulog
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestFileTransport(t *testing.T) {
+	// ARRANGE
+	var dir string
+
+	// files returns the names of the files in the test directory
+	files := func(t *testing.T) []string {
+		t.Helper()
+		entries, err := os.ReadDir(dir)
+		test.Error(t, err).IsNil()
+		result := []string{}
+		for _, e := range entries {
+			result = append(result, e.Name())
+		}
+		slices.Sort(result)
+		return result
+	}
+
+	// content returns the content of a file in the test directory,
+	// decompressing any gzip file
+	content := func(t *testing.T, name string) string {
+		t.Helper()
+		f, err := os.Open(filepath.Join(dir, name))
+		test.Error(t, err).IsNil()
+		defer f.Close()
+
+		var r io.Reader = f
+		if filepath.Ext(name) == ".gz" {
+			gz, err := gzip.NewReader(f)
+			test.Error(t, err).IsNil()
+			r = gz
+		}
+		b, err := io.ReadAll(r)
+		test.Error(t, err).IsNil()
+		return string(b)
+	}
+
+	// touch creates a file in the test directory with specified content
+	touch := func(t *testing.T, name string, content string) {
+		t.Helper()
+		test.Error(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)).IsNil()
+	}
+
+	// tm is the time of rotation used in tests
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 4000000, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// FileTransport tests
+		{scenario: "FileTransport",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(dir, "logs", "app.log")
+
+				// ACT
+				result, err := FileTransport(path)()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*file](t, result); ok {
+					defer result.close()
+					test.That(t, result.path, "path").Equals(path)
+					test.That(t, result.mode, "mode").Equals(os.FileMode(0o644))
+					test.That(t, len(result.signals), "signals").Equals(0)
+					test.That(t, result.f, "file").IsNotNil()
+				}
+				_, err = os.Stat(path)
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "FileTransport/no path",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := FileTransport("")()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrFileConfiguration)
+			},
+		},
+		{scenario: "FileTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*file) error { return opterr }
+
+				// ACT
+				result, err := FileTransport(filepath.Join(dir, "app.log"), opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "FileTransport/open error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				touch(t, "file", "")
+
+				// ACT
+				result, err := FileTransport(filepath.Join(dir, "file", "app.log"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrFileConfiguration)
+			},
+		},
+
+		// open tests
+		{scenario: "open/existing file",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				touch(t, "app.log", "existing\n")
+				sut := &file{path: filepath.Join(dir, "app.log"), mode: 0o644, period: time.Hour}
+				mtime := time.Now().Add(-2 * time.Hour)
+				test.Error(t, os.Chtimes(sut.path, mtime, mtime)).IsNil()
+
+				// ACT
+				err := sut.open()
+				defer sut.close()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.size, "size").Equals(int64(9))
+				test.That(t, sut.opened, "period").Equals(mtime.Truncate(time.Hour))
+			},
+		},
+
+		// backupName tests
+		{scenario: "backupName",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app.log")}
+
+				// ACT
+				result := sut.backupName(tm)
+
+				// ASSERT
+				test.That(t, result).Equals(filepath.Join(dir, "app-2010-09-08T07-06-05.004.log"))
+			},
+		},
+		{scenario: "backupName/no extension",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app")}
+
+				// ACT
+				result := sut.backupName(tm)
+
+				// ASSERT
+				test.That(t, result).Equals(filepath.Join(dir, "app-2010-09-08T07-06-05.004"))
+			},
+		},
+		{scenario: "backupName/already exists",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app.log")}
+				touch(t, "app-2010-09-08T07-06-05.004.log", "")
+				touch(t, "app-2010-09-08T07-06-05.005.log.gz", "")
+
+				// ACT
+				result := sut.backupName(tm)
+
+				// ASSERT
+				test.That(t, result).Equals(filepath.Join(dir, "app-2010-09-08T07-06-05.006.log"))
+			},
+		},
+
+		// shouldRotate tests
+		{scenario: "shouldRotate",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&now, func() time.Time { return tm })()
+
+				testcases := []struct {
+					name   string
+					sut    *file
+					n      int
+					result bool
+				}{
+					{name: "empty file", sut: &file{maxSize: 10, size: 0}, n: 20, result: false},
+					{name: "within max size", sut: &file{maxSize: 10, size: 5}, n: 5, result: false},
+					{name: "exceeds max size", sut: &file{maxSize: 10, size: 5}, n: 6, result: true},
+					{name: "no max size", sut: &file{size: 5}, n: 100, result: false},
+					{name: "same period", sut: &file{period: time.Hour, opened: tm.Truncate(time.Hour), size: 5}, n: 1, result: false},
+					{name: "new period", sut: &file{period: time.Hour, opened: tm.Add(-time.Hour).Truncate(time.Hour), size: 5}, n: 1, result: true},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := tc.sut.shouldRotate(tc.n)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// write tests
+		{scenario: "write/rotates",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&now, func() time.Time { return tm })()
+				sut := &file{path: filepath.Join(dir, "app.log"), mode: 0o644, maxSize: 10}
+				defer sut.close()
+
+				// ACT
+				errs := []error{
+					sut.write([]byte("entry 1\n")),
+					sut.write([]byte("entry 2\n")),
+				}
+
+				// ASSERT
+				test.Error(t, errors.Join(errs...)).IsNil()
+				test.Slice(t, files(t)).Equals([]string{"app-2010-09-08T07-06-05.004.log", "app.log"})
+				test.That(t, content(t, "app-2010-09-08T07-06-05.004.log")).Equals("entry 1\n")
+				test.That(t, content(t, "app.log")).Equals("entry 2\n")
+			},
+		},
+		{scenario: "write/not open",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				touch(t, "file", "")
+				sut := &file{path: filepath.Join(dir, "file", "app.log"), mode: 0o644}
+
+				// ACT
+				err := sut.write([]byte("entry\n"))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "write/rotation fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app.log"), mode: 0o644, maxSize: 10}
+				test.Error(t, sut.open()).IsNil()
+				sut.size = 10
+				test.Error(t, os.RemoveAll(dir)).IsNil()
+				test.Error(t, os.WriteFile(dir, nil, 0o644)).IsNil()
+				defer os.Remove(dir)
+
+				// ACT
+				err := sut.write([]byte("entry\n"))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+				test.That(t, sut.f).IsNil()
+			},
+		},
+
+		// backups tests
+		{scenario: "backups",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app.log")}
+				touch(t, "app.log", "")
+				touch(t, "app-2010-09-08T07-06-05.004.log", "")
+				touch(t, "app-2010-09-09T07-06-05.004.log.gz", "")
+				touch(t, "app-2010-09-07T07-06-05.004.txt", "")
+				touch(t, "app-invalid.log", "")
+				touch(t, "other-2010-09-08T07-06-05.004.log", "")
+				test.Error(t, os.Mkdir(filepath.Join(dir, "app-dir"), 0o755)).IsNil()
+
+				// ACT
+				result, err := sut.backups()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, result).Equals([]fileBackup{
+					{path: filepath.Join(dir, "app-2010-09-09T07-06-05.004.log.gz"), time: tm.Add(24 * time.Hour)},
+					{path: filepath.Join(dir, "app-2010-09-08T07-06-05.004.log"), time: tm},
+				})
+			},
+		},
+
+		// housekeep tests
+		{scenario: "housekeep/max backups",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app.log"), maxBackups: 2}
+				touch(t, "app-2010-09-06T07-06-05.004.log", "")
+				touch(t, "app-2010-09-07T07-06-05.004.log", "")
+				touch(t, "app-2010-09-08T07-06-05.004.log", "")
+
+				// ACT
+				sut.housekeep()
+
+				// ASSERT
+				test.Slice(t, files(t)).Equals([]string{
+					"app-2010-09-07T07-06-05.004.log",
+					"app-2010-09-08T07-06-05.004.log",
+				})
+			},
+		},
+		{scenario: "housekeep/max age",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&now, func() time.Time { return tm })()
+				sut := &file{path: filepath.Join(dir, "app.log"), maxAge: 36 * time.Hour}
+				touch(t, "app-2010-09-06T07-06-05.004.log", "")
+				touch(t, "app-2010-09-07T07-06-05.004.log", "")
+				touch(t, "app-2010-09-08T07-06-05.004.log", "")
+
+				// ACT
+				sut.housekeep()
+
+				// ASSERT
+				test.Slice(t, files(t)).Equals([]string{
+					"app-2010-09-07T07-06-05.004.log",
+					"app-2010-09-08T07-06-05.004.log",
+				})
+			},
+		},
+		{scenario: "housekeep/compress",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{path: filepath.Join(dir, "app.log"), mode: 0o644, compress: true}
+				touch(t, "app-2010-09-07T07-06-05.004.log.gz", "")
+				touch(t, "app-2010-09-08T07-06-05.004.log", "entry\n")
+
+				// ACT
+				sut.housekeep()
+
+				// ASSERT
+				test.Slice(t, files(t)).Equals([]string{
+					"app-2010-09-07T07-06-05.004.log.gz",
+					"app-2010-09-08T07-06-05.004.log.gz",
+				})
+				test.That(t, content(t, "app-2010-09-08T07-06-05.004.log.gz")).Equals("entry\n")
+			},
+		},
+		{scenario: "housekeep/errors",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+
+				sut := &file{path: filepath.Join(dir, "missing", "app.log")}
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.housekeep()
+				})
+
+				// ASSERT
+				stderr.Contains("file: housekeeping failed")
+			},
+		},
+
+		// compressFile tests
+		{scenario: "compressFile/no file",
+			exec: func(t *testing.T) {
+				// ACT
+				err := compressFile(filepath.Join(dir, "missing.log"), 0o644)
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "compressFile/cannot create",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				touch(t, "app.log", "entry\n")
+				test.Error(t, os.Mkdir(filepath.Join(dir, "app.log.gz"), 0o755)).IsNil()
+
+				// ACT
+				err := compressFile(filepath.Join(dir, "app.log"), 0o644)
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+				test.That(t, content(t, "app.log")).Equals("entry\n")
+			},
+		},
+
+		// log tests
+		{scenario: "log",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &file{ch: make(chan []byte, 1)}
+				b := []byte("entry")
+
+				// ACT
+				sut.log(b)
+				b[0] = 'E'
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("entry\n")
+			},
+		},
+
+		// run tests
+		{scenario: "run/reopen on signal",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(dir, "app.log")
+				tr, err := FileTransport(path, FileReopenOnSIGHUP())()
+				test.Error(t, err).IsNil()
+				sut := tr.(*file)
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					sut.run()
+				}()
+
+				// ACT
+				sut.log([]byte("entry 1"))
+				sut.log([]byte("entry 2")) // ensures entry 1 has been written before the file is moved
+				for len(sut.ch) > 0 {
+					time.Sleep(time.Millisecond)
+				}
+				time.Sleep(10 * time.Millisecond)
+				test.Error(t, os.Rename(path, filepath.Join(dir, "moved.log"))).IsNil()
+				proc, err := os.FindProcess(os.Getpid())
+				test.Error(t, err).IsNil()
+				test.Error(t, proc.Signal(syscall.SIGHUP)).IsNil()
+				for {
+					if _, err := os.Stat(path); err == nil {
+						break
+					}
+					time.Sleep(time.Millisecond)
+				}
+				sut.log([]byte("entry 3"))
+				sut.stop()
+				<-done
+
+				// ASSERT
+				test.That(t, content(t, "moved.log")).Equals("entry 1\nentry 2\n")
+				test.That(t, content(t, "app.log")).Equals("entry 3\n")
+			},
+		},
+		{scenario: "run/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+
+				touch(t, "file", "")
+				sut := &file{ch: make(chan []byte, 1), path: filepath.Join(dir, "file", "app.log")}
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.log([]byte("entry"))
+					sut.stop()
+					sut.run()
+				})
+
+				// ASSERT
+				stderr.Contains("file: entry discarded")
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "rotates, compresses and removes backups",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetTransport(FileTransport(filepath.Join(dir, "app.log"),
+								FileMaxSize(1),
+								FileMaxBackups(2),
+								FileCompress(true),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				for _, s := range []string{"one", "two", "three", "four"} {
+					logger.Info(s)
+				}
+				closelog()
+
+				// ASSERT
+				result := files(t)
+				test.That(t, len(result)).Equals(3)
+				test.That(t, result[2]).Equals("app.log")
+				test.That(t, filepath.Ext(result[0])).Equals(".gz")
+				test.That(t, filepath.Ext(result[1])).Equals(".gz")
+				test.IsTrue(t, strings.Contains(content(t, result[0]), `message="two"`))
+				test.IsTrue(t, strings.Contains(content(t, result[1]), `message="three"`))
+				test.IsTrue(t, strings.Contains(content(t, "app.log"), `message="four"`))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			dir = t.TempDir()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
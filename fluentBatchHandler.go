@@ -0,0 +1,266 @@
+package ulog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/blugnu/msgpack"
+)
+
+const (
+	fluentAck         = cfgkey("fluent.ack")
+	fluentDialTimeout = cfgkey("fluent.dialTimeout")
+	fluentNetwork     = cfgkey("fluent.network")
+	fluentReconnect   = cfgkey("fluent.reconnect")
+	fluentTag         = cfgkey("fluent.tag")
+	fluentTLS         = cfgkey("fluent.tls")
+)
+
+// errFluentAck is returned when a chunk is not correctly acknowledged.
+var errFluentAck = errors.New("chunk not acknowledged")
+
+// fluentAddress is the network and address of a fluent forward input.
+type fluentAddress struct {
+	network string
+	address string
+}
+
+// fluentBackoff is the reconnection backoff configuration of a fluent
+// batch handler.
+type fluentBackoff struct {
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// fluentBatchHandler is a batch handler that sends batches of events to
+// a fluent forward input.
+type fluentBatchHandler struct {
+	conn        *netconn      // the connection to the forward input
+	network     string        // the network of the forward input ("tcp" or "unix")
+	address     string        // the address of the forward input
+	tls         *tls.Config   // tls configuration for a tcp connection; nil if tls is not used
+	dialTimeout time.Duration // timeout for establishing a connection
+	tag         string        // the tag of events
+	ackTimeout  time.Duration // the time to wait for a chunk to be acknowledged; 0 = acknowledgements not requested
+}
+
+// newFluentBatchHandler creates a new, initialised fluent batch handler.
+func newFluentBatchHandler() *fluentBatchHandler {
+	h := &fluentBatchHandler{
+		dialTimeout: 5 * time.Second,
+		conn: &netconn{
+			name:       "fluent",
+			minBackoff: 100 * time.Millisecond,
+			maxBackoff: 30 * time.Second,
+		},
+	}
+	h.conn.dial = h.dial
+	return h
+}
+
+// configure applies configuration to the fluent batch handler.
+func (h *fluentBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case fluentAck:
+		h.ackTimeout = value.(time.Duration)
+	case fluentDialTimeout:
+		h.dialTimeout = value.(time.Duration)
+	case fluentNetwork:
+		cfg := value.(fluentAddress)
+		h.network = cfg.network
+		h.address = cfg.address
+	case fluentReconnect:
+		cfg := value.(fluentBackoff)
+		h.conn.minBackoff = cfg.backoff
+		h.conn.maxBackoff = cfg.maxBackoff
+	case fluentTag:
+		h.tag = value.(string)
+	case fluentTLS:
+		h.tls = value.(*tls.Config)
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrFluentConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// dial establishes a connection to the configured network and address.
+func (h *fluentBatchHandler) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: h.dialTimeout}
+	if h.tls != nil {
+		return tls.DialWithDialer(d, h.network, h.address, h.tls)
+	}
+	return d.Dial(h.network, h.address)
+}
+
+// newFluentChunk returns a new (random) chunk id.
+func newFluentChunk() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// encode returns a Forward mode message for the events in a batch, with
+// a specified chunk id (if not empty):
+//
+//	[tag, [event, ...], {"size": n, "chunk": id}]
+func (h *fluentBatchHandler) encode(batch *Batch, chunk string) []byte {
+	b := make([]byte, 0, batch.size+len(h.tag)+64)
+	b = append(b, 0x93)
+	b = append(b, msgpack.EncodeString(h.tag)...)
+	b = appendMsgpackArrayHeader(b, batch.len)
+	for _, e := range batch.entries {
+		b = append(b, e...)
+	}
+
+	if chunk == "" {
+		b = append(b, 0x81)
+	} else {
+		b = append(b, 0x82)
+		b = append(b, msgpack.EncodeString("chunk")...)
+		b = append(b, msgpack.EncodeString(chunk)...)
+	}
+	b = append(b, msgpack.EncodeString("size")...)
+	b = append(b, 0xce)
+	return binary.BigEndian.AppendUint32(b, uint32(batch.len))
+}
+
+// Send sends a batch to the forward input.  If acknowledgements are
+// enabled the handler waits for the chunk to be acknowledged.
+//
+// Any error is returned (and the batch retained) since the batch may be
+// successfully sent when retried.
+func (h *fluentBatchHandler) Send(batch *Batch) error {
+	var chunk string
+	if h.ackTimeout > 0 {
+		chunk = newFluentChunk()
+	}
+
+	if err := h.conn.write(h.encode(batch, chunk)); err != nil {
+		tracef("fluent: batch not sent: %s", err)
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	if err := h.awaitAck(chunk); err != nil {
+		tracef("fluent: batch not acknowledged: %s", err)
+		h.conn.disconnect()
+		return err
+	}
+	return nil
+}
+
+// awaitAck reads the response to a message sent with a specified chunk
+// id, returning an error if the response is not received within the ack
+// timeout or does not acknowledge the chunk.
+func (h *fluentBatchHandler) awaitAck(chunk string) error {
+	conn := h.conn.conn
+	_ = conn.SetReadDeadline(now().Add(h.ackTimeout))
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+
+	ack, err := readFluentAck(bufio.NewReader(conn))
+	if err != nil {
+		return err
+	}
+	if ack != chunk {
+		return fmt.Errorf("%w: expected %q, got %q", errFluentAck, chunk, ack)
+	}
+	return nil
+}
+
+// readFluentAck reads a response from a forward input, returning the
+// value of the "ack" key.  The response is a msgpack map with string
+// keys and values:
+//
+//	{"ack": chunk}
+func readFluentAck(r *bufio.Reader) (string, error) {
+	readLen := func(n int) (int, error) {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, err
+		}
+		switch n {
+		case 1:
+			return int(b[0]), nil
+		case 2:
+			return int(binary.BigEndian.Uint16(b)), nil
+		default:
+			return int(binary.BigEndian.Uint32(b)), nil
+		}
+	}
+
+	readString := func() (string, error) {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		var n int
+		switch {
+		case c&0xe0 == 0xa0:
+			n = int(c & 0x1f)
+		case c == 0xd9 || c == 0xc4:
+			n, err = readLen(1)
+		case c == 0xda || c == 0xc5:
+			n, err = readLen(2)
+		case c == 0xdb || c == 0xc6:
+			n, err = readLen(4)
+		default:
+			return "", fmt.Errorf("%w: unexpected response: 0x%02x is not a string", errFluentAck, c)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	var n int
+	switch {
+	case c&0xf0 == 0x80:
+		n = int(c & 0x0f)
+	case c == 0xde:
+		n, err = readLen(2)
+	case c == 0xdf:
+		n, err = readLen(4)
+	default:
+		return "", fmt.Errorf("%w: unexpected response: 0x%02x is not a map", errFluentAck, c)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var ack string
+	for i := 0; i < n; i++ {
+		k, err := readString()
+		if err != nil {
+			return "", err
+		}
+		v, err := readString()
+		if err != nil {
+			return "", err
+		}
+		if k == "ack" {
+			ack = v
+		}
+	}
+	return ack, nil
+}
@@ -0,0 +1,272 @@
+package ulog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blugnu/msgpack"
+	"github.com/blugnu/test"
+)
+
+func TestFluentBatchHandler(t *testing.T) {
+	// ARRANGE
+	batch := func(entries ...string) *Batch {
+		b := &Batch{}
+		b.init(nil, 10)
+		for _, e := range entries {
+			b.entries = append(b.entries, []byte(e))
+			b.size += len(e)
+			b.len++
+		}
+		return b
+	}
+
+	// ackResponse returns an encoded ack response for a specified chunk
+	ackResponse := func(chunk string) []byte {
+		return append([]byte{0x81, 0xa3, 'a', 'c', 'k'}, msgpack.EncodeString(chunk)...)
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := newFluentBatchHandler()
+				cfg := &tls.Config{}
+
+				// ACT
+				errs := []error{
+					sut.configure(fluentAck, time.Second),
+					sut.configure(fluentDialTimeout, time.Minute),
+					sut.configure(fluentNetwork, fluentAddress{"tcp", "localhost:24224"}),
+					sut.configure(fluentReconnect, fluentBackoff{time.Second, time.Minute}),
+					sut.configure(fluentTag, "tag"),
+					sut.configure(fluentTLS, cfg),
+				}
+
+				// ASSERT
+				test.Error(t, errors.Join(errs...)).IsNil()
+				test.That(t, sut.ackTimeout, "ack timeout").Equals(time.Second)
+				test.That(t, sut.dialTimeout, "dial timeout").Equals(time.Minute)
+				test.That(t, sut.network, "network").Equals("tcp")
+				test.That(t, sut.address, "address").Equals("localhost:24224")
+				test.That(t, sut.conn.minBackoff, "min backoff").Equals(time.Second)
+				test.That(t, sut.conn.maxBackoff, "max backoff").Equals(time.Minute)
+				test.That(t, sut.tag, "tag").Equals("tag")
+				test.That(t, sut.tls, "tls").Equals(cfg)
+			},
+		},
+		{scenario: "configure/unsupported key",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := newFluentBatchHandler()
+
+				// ACT
+				err := sut.configure("unsupported", nil)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrFluentConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// dial tests
+		{scenario: "dial/tls",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewTLSServer(http.NotFoundHandler())
+				defer srv.Close()
+				sut := newFluentBatchHandler()
+				sut.network = "tcp"
+				sut.address = srv.Listener.Addr().String()
+				sut.tls = srv.Client().Transport.(*http.Transport).TLSClientConfig
+
+				// ACT
+				conn, err := sut.dial()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if conn, ok := test.IsType[*tls.Conn](t, conn); ok {
+					test.Error(t, conn.Handshake()).IsNil()
+					conn.Close()
+				}
+			},
+		},
+
+		// newFluentChunk tests
+		{scenario: "newFluentChunk",
+			exec: func(t *testing.T) {
+				// ACT
+				a := newFluentChunk()
+				b := newFluentChunk()
+
+				// ASSERT
+				id, err := base64.StdEncoding.DecodeString(a)
+				test.Error(t, err).IsNil()
+				test.That(t, len(id), "id bytes").Equals(16)
+				test.IsTrue(t, a != b, "unique")
+			},
+		},
+
+		// encode tests
+		{scenario: "encode",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &fluentBatchHandler{tag: "tag"}
+
+				// ACT
+				result := sut.encode(batch("\x01", "\x02"), "")
+
+				// ASSERT
+				v, _, _ := mpDecode(result)
+				test.That(t, v).Equals(any([]any{"tag", []any{int64(1), int64(2)}, map[string]any{"size": int64(2)}}))
+			},
+		},
+		{scenario: "encode/with chunk",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &fluentBatchHandler{tag: "tag"}
+
+				// ACT
+				result := sut.encode(batch("\x01"), "chunk")
+
+				// ASSERT
+				v, _, _ := mpDecode(result)
+				test.That(t, v.([]any)[2]).Equals(any(map[string]any{"chunk": "chunk", "size": int64(1)}))
+			},
+		},
+
+		// send tests
+		{scenario: "send/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dialErr := errors.New("dial error")
+				sut := newFluentBatchHandler()
+				sut.conn.dial = func() (net.Conn, error) { return nil, dialErr }
+
+				// ACT
+				err := sut.Send(batch("\x01"))
+
+				// ASSERT
+				test.Error(t, err).Is(dialErr)
+			},
+		},
+		{scenario: "send/ack",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client, server := net.Pipe()
+				defer server.Close()
+				go func() {
+					b := make([]byte, 1024)
+					n, _ := server.Read(b)
+					v, _, _ := mpDecode(b[:n])
+					_, _ = server.Write(ackResponse(v.([]any)[2].(map[string]any)["chunk"].(string)))
+				}()
+				sut := newFluentBatchHandler()
+				sut.ackTimeout = time.Second
+				sut.conn.dial = func() (net.Conn, error) { return client, nil }
+
+				// ACT
+				err := sut.Send(batch("\x01"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.conn.conn, "connection").Equals(net.Conn(client))
+			},
+		},
+		{scenario: "send/ack/wrong chunk",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client, server := net.Pipe()
+				defer server.Close()
+				go func() {
+					_, _ = server.Read(make([]byte, 1024))
+					_, _ = server.Write(ackResponse("wrong"))
+				}()
+				sut := newFluentBatchHandler()
+				sut.ackTimeout = time.Second
+				sut.conn.dial = func() (net.Conn, error) { return client, nil }
+
+				// ACT
+				err := sut.Send(batch("\x01"))
+
+				// ASSERT
+				test.Error(t, err).Is(errFluentAck)
+				test.That(t, sut.conn.conn, "connection").IsNil()
+			},
+		},
+		{scenario: "send/ack/timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client, server := net.Pipe()
+				defer server.Close()
+				go func() { _, _ = server.Read(make([]byte, 1024)) }()
+				sut := newFluentBatchHandler()
+				sut.ackTimeout = 10 * time.Millisecond
+				sut.conn.dial = func() (net.Conn, error) { return client, nil }
+
+				// ACT
+				err := sut.Send(batch("\x01"))
+
+				// ASSERT
+				test.Error(t, err).Is(os.ErrDeadlineExceeded)
+				test.That(t, sut.conn.conn, "connection").IsNil()
+			},
+		},
+
+		// readFluentAck tests
+		{scenario: "readFluentAck",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name   string
+					input  []byte
+					result string
+					err    error
+				}{
+					{name: "fixmap", input: []byte("\x81\xa3ack\xa5chunk"), result: "chunk"},
+					{name: "map16/str8", input: []byte("\xde\x00\x02\xa5other\xd9\x01x\xa3ack\xd9\x05chunk"), result: "chunk"},
+					{name: "map32/str16", input: []byte("\xdf\x00\x00\x00\x01\xda\x00\x03ack\xda\x00\x05chunk"), result: "chunk"},
+					{name: "bin", input: []byte("\x81\xc4\x03ack\xc6\x00\x00\x00\x05chunk"), result: "chunk"},
+					{name: "str32/bin16", input: []byte("\x81\xdb\x00\x00\x00\x03ack\xc5\x00\x05chunk"), result: "chunk"},
+					{name: "no ack", input: []byte("\x80"), result: ""},
+					{name: "not a map", input: []byte("\x91\xa3ack"), err: errFluentAck},
+					{name: "not a string", input: []byte("\x81\xa3ack\x01"), err: errFluentAck},
+					{name: "empty", input: []byte{}, err: io.EOF},
+					{name: "truncated map header", input: []byte("\xde\x00"), err: io.ErrUnexpectedEOF},
+					{name: "truncated key", input: []byte("\x81"), err: io.EOF},
+					{name: "truncated string header", input: []byte("\x81\xd9"), err: io.EOF},
+					{name: "truncated string", input: []byte("\x81\xa3ac"), err: io.ErrUnexpectedEOF},
+					{name: "truncated value", input: []byte("\x81\xa3ack"), err: io.EOF},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result, err := readFluentAck(bufio.NewReader(bytes.NewReader(tc.input)))
+
+						// ASSERT
+						test.Error(t, err).Is(tc.err)
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
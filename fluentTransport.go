@@ -0,0 +1,141 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blugnu/msgpack"
+)
+
+type FluentOption = func(*fluent) error // FluentOption is a function that configures a fluent transport
+
+// FluentTransport returns a transport factory function to create and
+// configure a transport that sends log entries to Fluentd or Fluent Bit
+// using the forward protocol, with specified configuration options
+// applied.  The network and address of the forward input must be
+// configured using the FluentNetwork option.
+//
+// Entries are sent in batches as Forward mode messages:
+//
+//	[tag, [[time, record], ...], {"size": n}]
+//
+// where time is the time of the entry encoded as an EventTime and record
+// is the entry formatted by the target Formatter, which should be a
+// MsgpackFormatter.  If the formatted entry is not a msgpack map, the
+// record is a map with the formatted entry as the value of a "message"
+// key.
+//
+// If acknowledgements are enabled (see: FluentAck) each message includes
+// a unique chunk id and the transport waits for the chunk to be
+// acknowledged.  A batch that is not acknowledged (or could not be sent)
+// is retained and sent again, providing at-least-once delivery.
+//
+// By default a batch is sent when it contains 100 entries or after
+// 1 second.
+func FluentTransport(opts ...FluentOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newFluentBatchHandler()
+		bh.tag = filepath.Base(os.Args[0])
+
+		t := &fluent{
+			batchTransport: batchTransport{
+				name:  "fluent",
+				ch:    make(chan []byte, 100),
+				batch: &Batch{maxLatency: time.Second},
+			},
+			conn: bh.conn,
+		}
+		t.batch.init(bh, 100)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		if t.batch.oversize == TruncateOversizeEntries {
+			return nil, fmt.Errorf("%w: %w: TruncateOversizeEntries is not supported", ErrFluentConfiguration, ErrInvalidConfiguration)
+		}
+		switch {
+		case bh.network == "":
+			return nil, fmt.Errorf("%w: %w: network and address are required", ErrFluentConfiguration, ErrInvalidConfiguration)
+		case bh.tls != nil && bh.network != "tcp":
+			return nil, fmt.Errorf("%w: %w: tls requires a tcp network", ErrFluentConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// fluent implements a transport that sends log entries to a fluent
+// forward input.
+type fluent struct {
+	batchTransport
+	conn *netconn // the connection used by the batch handler
+}
+
+// logEntry implements the entryTransport interface.  The entry is
+// formatted to provide the record of an event, which is encoded with the
+// time of the entry and sent to the transport channel.
+func (t *fluent) logEntry(e entry, format func(entry) []byte) {
+	t.ch <- encodeFluentEvent(e.Time, format(e))
+}
+
+// run is the goroutine run loop for the transport.  Entries are batched
+// and sent until the transport is stopped, when any established
+// connection is closed.
+func (t *fluent) run() {
+	t.batchTransport.run()
+	t.conn.disconnect()
+}
+
+// isMsgpackMap returns true if a byte slice starts with a msgpack map
+// header.
+func isMsgpackMap(b []byte) bool {
+	return len(b) > 0 && (b[0]&0xf0 == 0x80 || b[0] == 0xde || b[0] == 0xdf)
+}
+
+// appendMsgpackArrayHeader appends a msgpack array header for an array
+// with a specified number of elements.
+func appendMsgpackArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+// encodeFluentEvent encodes the time and record of an event as a msgpack
+// array of two elements:
+//
+//	[EventTime, record]
+//
+// The EventTime is a msgpack extension (type 0) of 8 bytes, holding the
+// seconds and nanoseconds of the time as big-endian 32-bit integers.  A
+// record that is not a msgpack map is encoded as a map with the record
+// as the (string) value of a "message" key.
+func encodeFluentEvent(tm time.Time, record []byte) []byte {
+	b := make([]byte, 0, 12+len(record)+16)
+	b = append(b, 0x92, 0xd7, 0x00)
+	b = binary.BigEndian.AppendUint32(b, uint32(tm.Unix()))
+	b = binary.BigEndian.AppendUint32(b, uint32(tm.Nanosecond()))
+
+	if isMsgpackMap(record) {
+		return append(b, record...)
+	}
+
+	if n := len(record); n > 0 && record[n-1] == '\n' {
+		record = record[:n-1]
+	}
+	b = append(b, 0x81)
+	b = append(b, msgpack.EncodeString("message")...)
+	return append(b, msgpack.EncodeString(string(record))...)
+}
@@ -0,0 +1,101 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FluentAck configures the transport to request acknowledgement of each
+// message, waiting up to a specified time for the acknowledgement.  A
+// message that is not acknowledged is sent again.
+//
+// Fluentd and Fluent Bit forward inputs acknowledge any message that
+// requests acknowledgement.
+func FluentAck(timeout time.Duration) FluentOption {
+	return func(t *fluent) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: FluentAck: timeout must be > 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(fluentAck, timeout)
+	}
+}
+
+// FluentBatching applies batch options to configure the batching of log
+// entries sent by the transport.  By default a batch is sent when it
+// contains 100 entries or after 1 second.
+func FluentBatching(opts ...BatchOption) FluentOption {
+	return func(t *fluent) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// FluentDialTimeout configures the timeout for establishing a connection.
+// The default is 5 seconds.
+func FluentDialTimeout(d time.Duration) FluentOption {
+	return func(t *fluent) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: FluentDialTimeout: must be > 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(fluentDialTimeout, d)
+	}
+}
+
+// FluentNetwork configures the network and address of the forward input
+// to which messages are sent.  The network must be "tcp" or "unix".
+//
+// e.g. FluentNetwork("tcp", "localhost:24224")
+func FluentNetwork(network, address string) FluentOption {
+	return func(t *fluent) error {
+		switch network {
+		case "tcp", "unix":
+		default:
+			return fmt.Errorf("%w: FluentNetwork: %q: network must be tcp or unix", ErrInvalidConfiguration, network)
+		}
+		if address == "" {
+			return fmt.Errorf("%w: FluentNetwork: address is required", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(fluentNetwork, fluentAddress{network, address})
+	}
+}
+
+// FluentReconnect configures the delay before attempting to reconnect
+// after a connection fails, and the maximum delay.  The delay is doubled
+// after each consecutive failure, up to the maximum.  The defaults are
+// 100ms and 30s respectively.
+func FluentReconnect(backoff, maxBackoff time.Duration) FluentOption {
+	return func(t *fluent) error {
+		if backoff <= 0 || maxBackoff < backoff {
+			return fmt.Errorf("%w: FluentReconnect: backoff must be > 0 and <= max backoff", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(fluentReconnect, fluentBackoff{backoff, maxBackoff})
+	}
+}
+
+// FluentTag configures the tag of events.  The default is the base name
+// of the executable.
+func FluentTag(s string) FluentOption {
+	return func(t *fluent) error {
+		if s == "" {
+			return fmt.Errorf("%w: FluentTag: tag is required", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(fluentTag, s)
+	}
+}
+
+// FluentTLS configures the transport to connect to a tcp network using
+// TLS, with a specified configuration.  A nil configuration uses the
+// default configuration.
+func FluentTLS(cfg *tls.Config) FluentOption {
+	return func(t *fluent) error {
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		return t.batch.configure(fluentTLS, cfg)
+	}
+}
@@ -0,0 +1,173 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestFluentTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		sut *fluent
+		bh  *fluentBatchHandler
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "FluentAck",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentAck(time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.ackTimeout).Equals(time.Second)
+			},
+		},
+		{scenario: "FluentAck/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentAck(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FluentBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentBatching(BatchMaxEntries(10), BatchMaxLatency(time.Minute))(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.batch.max).Equals(10)
+				test.That(t, sut.batch.maxLatency).Equals(time.Minute)
+			},
+		},
+		{scenario: "FluentDialTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentDialTimeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.dialTimeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "FluentDialTimeout/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentDialTimeout(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FluentNetwork",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentNetwork("unix", "/tmp/fluent.sock")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.network).Equals("unix")
+				test.That(t, bh.address).Equals("/tmp/fluent.sock")
+			},
+		},
+		{scenario: "FluentNetwork/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					FluentNetwork("udp", "localhost:24224")(sut),
+					FluentNetwork("tcp", "")(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FluentReconnect",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentReconnect(time.Second, time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.conn.minBackoff).Equals(time.Second)
+				test.That(t, bh.conn.maxBackoff).Equals(time.Minute)
+			},
+		},
+		{scenario: "FluentReconnect/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					FluentReconnect(0, time.Minute)(sut),
+					FluentReconnect(time.Minute, time.Second)(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FluentTag",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentTag("app.logs")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.tag).Equals("app.logs")
+			},
+		},
+		{scenario: "FluentTag/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentTag("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FluentTLS",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cfg := &tls.Config{ServerName: "fluent"}
+
+				// ACT
+				err := FluentTLS(cfg)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.tls).Equals(cfg)
+			},
+		},
+		{scenario: "FluentTLS/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := FluentTLS(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.tls).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newFluentBatchHandler()
+			sut = &fluent{batchTransport: batchTransport{batch: &Batch{}}, conn: bh.conn}
+			sut.batch.init(bh, 100)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package ulog
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/msgpack"
+	"github.com/blugnu/test"
+)
+
+// mpExt is a msgpack extension value decoded by mpDecode.
+type mpExt struct {
+	typ  int8
+	data []byte
+}
+
+// mpDecode decodes the first msgpack value in a byte slice, returning the
+// value and the number of bytes consumed.  If the slice does not contain
+// a complete value, ok is false.
+//
+// Maps are decoded as map[string]any, arrays as []any, integers as int64,
+// floats as float64, strings and binary values as string and extensions
+// as mpExt.
+func mpDecode(b []byte) (v any, n int, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			v, n, ok = nil, 0, false
+		}
+	}()
+
+	uint := func(i, sz int) int {
+		u := 0
+		for _, c := range b[i : i+sz] {
+			u = u<<8 | int(c)
+		}
+		return u
+	}
+	str := func(i, sz int) (any, int, bool) {
+		return string(b[i : i+sz]), i + sz, true
+	}
+	array := func(i, sz int) (any, int, bool) {
+		a := make([]any, 0, sz)
+		for j := 0; j < sz; j++ {
+			v, n, ok := mpDecode(b[i:])
+			if !ok {
+				return nil, 0, false
+			}
+			a = append(a, v)
+			i += n
+		}
+		return a, i, true
+	}
+	mapping := func(i, sz int) (any, int, bool) {
+		m := make(map[string]any, sz)
+		for j := 0; j < sz; j++ {
+			k, n, ok := mpDecode(b[i:])
+			if !ok {
+				return nil, 0, false
+			}
+			i += n
+			v, n, ok := mpDecode(b[i:])
+			if !ok {
+				return nil, 0, false
+			}
+			i += n
+			m[k.(string)] = v
+		}
+		return m, i, true
+	}
+	ext := func(i, sz int) (any, int, bool) {
+		return mpExt{typ: int8(b[i]), data: b[i+1 : i+1+sz]}, i + 1 + sz, true
+	}
+
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), 1, true
+	case c >= 0xe0:
+		return int64(int8(c)), 1, true
+	case c&0xf0 == 0x80:
+		return mapping(1, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return array(1, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return str(1, int(c&0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, 1, true
+	case 0xc2, 0xc3:
+		return c == 0xc3, 1, true
+	case 0xc4, 0xd9:
+		return str(2, uint(1, 1))
+	case 0xc5, 0xda:
+		return str(3, uint(1, 2))
+	case 0xc6, 0xdb:
+		return str(5, uint(1, 4))
+	case 0xc7:
+		return ext(2, uint(1, 1))
+	case 0xc8:
+		return ext(3, uint(1, 2))
+	case 0xc9:
+		return ext(5, uint(1, 4))
+	case 0xca:
+		return float64(math.Float32frombits(uint32(uint(1, 4)))), 5, true
+	case 0xcb:
+		return math.Float64frombits(uint64(uint(1, 8))), 9, true
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		sz := 1 << (c - 0xcc)
+		return int64(uint(1, sz)), 1 + sz, true
+	case 0xd0:
+		return int64(int8(uint(1, 1))), 2, true
+	case 0xd1:
+		return int64(int16(uint(1, 2))), 3, true
+	case 0xd2:
+		return int64(int32(uint(1, 4))), 5, true
+	case 0xd3:
+		return int64(uint(1, 8)), 9, true
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return ext(1, 1<<(c-0xd4))
+	case 0xdc:
+		return array(3, uint(1, 2))
+	case 0xdd:
+		return array(5, uint(1, 4))
+	case 0xde:
+		return mapping(3, uint(1, 2))
+	case 0xdf:
+		return mapping(5, uint(1, 4))
+	}
+	return nil, 0, false
+}
+
+// fluentServer accepts connections on a listener, decoding Forward mode
+// messages and sending them to the returned channel.  If ack is true, a
+// message requesting acknowledgement is acknowledged.
+func fluentServer(l net.Listener, ack bool) chan []any {
+	received := make(chan []any, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				b := []byte{}
+				buf := make([]byte, 4096)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					b = append(b, buf[:n]...)
+					for {
+						v, n, ok := mpDecode(b)
+						if !ok {
+							break
+						}
+						b = b[n:]
+						msg := v.([]any)
+						received <- msg
+						if opts, ok := msg[2].(map[string]any); ok && ack && opts["chunk"] != nil {
+							_, _ = conn.Write(append([]byte{0x81, 0xa3, 'a', 'c', 'k'}, msgpack.EncodeString(opts["chunk"].(string))...))
+						}
+					}
+				}
+			}()
+		}
+	}()
+	return received
+}
+
+func TestFluentTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	// logto returns a logger that logs msgpack entries to a fluent transport
+	// configured with specified options
+	logto := func(t *testing.T, opts ...FluentOption) (Logger, func()) {
+		logger, closelog, err := NewLogger(context.Background(),
+			Mux(
+				MuxTarget(
+					TargetLevel(InfoLevel),
+					TargetFormat(MsgpackFormatter()),
+					TargetTransport(FluentTransport(opts...)),
+				),
+			),
+		)
+		test.Error(t, err).IsNil()
+		return logger, closelog
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// FluentTransport tests
+		{scenario: "FluentTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*fluent) error { return opterr }
+
+				// ACT
+				result, err := FluentTransport(opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "FluentTransport/no network",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := FluentTransport()()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrFluentConfiguration)
+			},
+		},
+		{scenario: "FluentTransport/tls without tcp",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := FluentTransport(FluentNetwork("unix", "/tmp/fluent.sock"), FluentTLS(nil))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrFluentConfiguration)
+			},
+		},
+		{scenario: "FluentTransport/truncate not supported",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := FluentTransport(
+					FluentNetwork("tcp", "localhost:24224"),
+					FluentBatching(BatchOversizeEntries(TruncateOversizeEntries)),
+				)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrFluentConfiguration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "FluentTransport/defaults",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := FluentTransport(FluentNetwork("tcp", "localhost:24224"))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*fluent](t, result); ok {
+					test.That(t, result.batch.max, "batch capacity").Equals(100)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(time.Second)
+					if handler, ok := test.IsType[*fluentBatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.tag, "tag").Equals(filepath.Base(os.Args[0]))
+						test.That(t, handler.ackTimeout, "ack timeout").Equals(time.Duration(0))
+						test.That(t, handler.dialTimeout, "dial timeout").Equals(5 * time.Second)
+						test.That(t, handler.conn, "connection").Equals(result.conn)
+					}
+				}
+			},
+		},
+
+		// isMsgpackMap tests
+		{scenario: "isMsgpackMap",
+			exec: func(t *testing.T) {
+				// ASSERT
+				test.IsTrue(t, isMsgpackMap([]byte{0x80}), "fixmap")
+				test.IsTrue(t, isMsgpackMap([]byte{0xde}), "map16")
+				test.IsTrue(t, isMsgpackMap([]byte{0xdf}), "map32")
+				test.IsFalse(t, isMsgpackMap([]byte{0x90}), "fixarray")
+				test.IsFalse(t, isMsgpackMap([]byte("{}")), "json")
+				test.IsFalse(t, isMsgpackMap(nil), "empty")
+			},
+		},
+
+		// appendMsgpackArrayHeader tests
+		{scenario: "appendMsgpackArrayHeader",
+			exec: func(t *testing.T) {
+				// ASSERT
+				test.That(t, appendMsgpackArrayHeader(nil, 15), "fixarray").Equals([]byte{0x9f})
+				test.That(t, appendMsgpackArrayHeader(nil, 16), "array16").Equals([]byte{0xdc, 0x00, 0x10})
+				test.That(t, appendMsgpackArrayHeader(nil, 0x10000), "array32").Equals([]byte{0xdd, 0x00, 0x01, 0x00, 0x00})
+			},
+		},
+
+		// encodeFluentEvent tests
+		{scenario: "encodeFluentEvent",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				record := append([]byte{0x81, 0xa1, 'k'}, msgpack.EncodeString("value")...)
+
+				// ACT
+				result := encodeFluentEvent(tm, record)
+
+				// ASSERT
+				v, n, ok := mpDecode(result)
+				test.IsTrue(t, ok, "decoded")
+				test.That(t, n, "decoded bytes").Equals(len(result))
+				test.That(t, v).Equals(any([]any{
+					mpExt{typ: 0, data: []byte{0x4c, 0x87, 0x35, 0xdd, 0x19, 0xc1, 0x52, 0xa0}},
+					map[string]any{"k": "value"},
+				}))
+			},
+		},
+		{scenario: "encodeFluentEvent/not a msgpack map",
+			exec: func(t *testing.T) {
+				// ACT
+				result := encodeFluentEvent(tm, []byte("message\n"))
+
+				// ASSERT
+				v, _, _ := mpDecode(result)
+				test.That(t, v.([]any)[1]).Equals(any(map[string]any{"message": "message"}))
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &fluent{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(e.Message) })
+
+				// ASSERT
+				test.That(t, <-sut.ch).Equals(encodeFluentEvent(tm, []byte("message")))
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "sends over tcp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := fluentServer(l, false)
+
+				logger, closelog := logto(t, FluentNetwork("tcp", l.Addr().String()), FluentTag("app.logs"))
+
+				// ACT
+				logger.Info("message 1")
+				logger.WithField("key", "value").Warn("message 2")
+				closelog()
+
+				// ASSERT
+				select {
+				case msg := <-received:
+					test.That(t, msg[0], "tag").Equals(any("app.logs"))
+					events := msg[1].([]any)
+					test.That(t, len(events), "events").Equals(2)
+					ev := events[1].([]any)
+					test.That(t, ev[0].(mpExt).typ, "EventTime").Equals(int8(0))
+					record := ev[1].(map[string]any)
+					test.That(t, record["message"], "message").Equals(any("message 2"))
+					test.That(t, record["key"], "field").Equals(any("value"))
+					test.That(t, msg[2], "options").Equals(any(map[string]any{"size": int64(2)}))
+				case <-time.After(time.Second):
+					t.Fatal("timed out")
+				}
+			},
+		},
+		{scenario: "sends over unix socket with ack",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dir, err := os.MkdirTemp("", "ulog")
+				test.Error(t, err).IsNil()
+				defer os.RemoveAll(dir)
+				path := filepath.Join(dir, "fluent.sock")
+
+				l, err := net.Listen("unix", path)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := fluentServer(l, true)
+
+				logger, closelog := logto(t,
+					FluentNetwork("unix", path),
+					FluentAck(time.Second),
+					FluentBatching(BatchMaxEntries(1)),
+				)
+
+				// ACT
+				logger.Info("message 1")
+				logger.Info("message 2")
+				closelog()
+
+				// ASSERT
+				for _, s := range []string{"message 1", "message 2"} {
+					select {
+					case msg := <-received:
+						record := msg[1].([]any)[0].([]any)[1].(map[string]any)
+						test.That(t, record["message"], "message").Equals(any(s))
+						test.IsTrue(t, msg[2].(map[string]any)["chunk"] != nil, "chunk")
+					case <-time.After(time.Second):
+						t.Fatal("timed out")
+					}
+				}
+			},
+		},
+		{scenario: "retains batch until acknowledged",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				// the first connection receives a message without acknowledging
+				// it; subsequent connections are acknowledged
+				unacked := make(chan struct{})
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					_, _ = conn.Read(make([]byte, 4096))
+					<-unacked
+				}()
+
+				logger, closelog := logto(t,
+					FluentNetwork("tcp", l.Addr().String()),
+					FluentAck(50*time.Millisecond),
+					FluentReconnect(time.Millisecond, time.Millisecond),
+					FluentBatching(BatchMaxEntries(1)),
+				)
+
+				// ACT
+				logger.Info("message 1")
+				time.Sleep(100 * time.Millisecond)
+				close(unacked)
+				received := fluentServer(l, true)
+				time.Sleep(10 * time.Millisecond)
+				logger.Info("message 2")
+				closelog()
+
+				// ASSERT
+				messages := []string{}
+				for len(received) > 0 {
+					msg := <-received
+					for _, ev := range msg[1].([]any) {
+						messages = append(messages, ev.([]any)[1].(map[string]any)["message"].(string))
+					}
+				}
+				test.That(t, strings.Join(messages, ",")).Equals("message 1,message 2")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Framing identifies how formatted entries are delimited when written
+// to a stream.
+//
+// NewlineFraming is appropriate for text formats (e.g. logfmt or JSON)
+// that do not contain unescaped newlines.  Binary formats (e.g. msgpack)
+// may contain newline bytes and require a length prefix or, for formats
+// that are self-delimiting, no framing (NoFraming).
+type Framing int
+
+const (
+	NewlineFraming      Framing = iota // NewlineFraming terminates each entry with a newline
+	LengthPrefixFraming                // LengthPrefixFraming precedes each entry with its length as a 4-byte big-endian unsigned integer
+	NoFraming                          // NoFraming does not delimit entries; the format must be self-delimiting (e.g. msgpack or CBOR)
+	VarintFraming                      // VarintFraming precedes each entry with its length as an unsigned varint (as used by protobuf delimited streams)
+)
+
+// MsgpackFraming does not delimit entries.
+//
+// Deprecated: use NoFraming.
+const MsgpackFraming = NoFraming
+
+// String implements the Stringer interface for Framing.
+func (f Framing) String() string {
+	switch f {
+	case NewlineFraming:
+		return "newline"
+	case LengthPrefixFraming:
+		return "length-prefix"
+	case NoFraming:
+		return "none"
+	case VarintFraming:
+		return "varint"
+	}
+	return fmt.Sprintf("<invalid framing (%d)>", int(f))
+}
+
+// isValid returns true if the Framing is a defined value.
+func (f Framing) isValid() bool {
+	return f >= NewlineFraming && f <= VarintFraming
+}
+
+// appendFrame appends a formatted entry, with the framing applied, to a
+// specified slice, returning the extended slice.
+func (f Framing) appendFrame(dst, b []byte) []byte {
+	switch f {
+	case LengthPrefixFraming:
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(b)))
+		return append(dst, b...)
+	case VarintFraming:
+		dst = binary.AppendUvarint(dst, uint64(len(b)))
+		return append(dst, b...)
+	case NoFraming:
+		return append(dst, b...)
+	default:
+		dst = append(dst, b...)
+		return append(dst, '\n')
+	}
+}
+
+// frame returns a new slice containing a formatted entry with the framing
+// applied.
+func (f Framing) frame(b []byte) []byte {
+	return f.appendFrame(make([]byte, 0, len(b)+binary.MaxVarintLen32), b)
+}
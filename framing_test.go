@@ -0,0 +1,92 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestFraming(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "String",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					Framing
+					result string
+				}{
+					{Framing: NewlineFraming, result: "newline"},
+					{Framing: LengthPrefixFraming, result: "length-prefix"},
+					{Framing: NoFraming, result: "none"},
+					{Framing: VarintFraming, result: "varint"},
+					{Framing: Framing(-1), result: "<invalid framing (-1)>"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.result, func(t *testing.T) {
+						// ACT
+						result := tc.String()
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "isValid",
+			exec: func(t *testing.T) {
+				// ASSERT
+				test.IsTrue(t, NewlineFraming.isValid(), "newline")
+				test.IsTrue(t, VarintFraming.isValid(), "varint")
+				test.IsFalse(t, Framing(-1).isValid(), "-1")
+				test.IsFalse(t, (VarintFraming + 1).isValid(), "varint + 1")
+			},
+		},
+		{scenario: "frame",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					Framing
+					result []byte
+				}{
+					{Framing: NewlineFraming, result: []byte("entry\n")},
+					{Framing: LengthPrefixFraming, result: []byte("\x00\x00\x00\x05entry")},
+					{Framing: NoFraming, result: []byte("entry")},
+					{Framing: VarintFraming, result: []byte("\x05entry")},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.String(), func(t *testing.T) {
+						// ARRANGE
+						b := []byte("entry")
+
+						// ACT
+						result := tc.frame(b)
+						b[0] = 'E'
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "appendFrame",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dst := []byte("prefix:")
+
+				// ACT
+				result := VarintFraming.appendFrame(dst, make([]byte, 300))
+
+				// ASSERT
+				test.That(t, result[:9]).Equals([]byte("prefix:\xac\x02"))
+				test.That(t, len(result)).Equals(309)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package ulog
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type GCPFormatterOption func(*gcpfmt) error // GCPFormatterOption is a function for configuring a Google Cloud Logging formatter
+
+// GCPTraceContextFunc is a function that returns the trace id and span id
+// of a span in a specified context.  If the context does not contain a
+// valid span the function returns ok == false.
+//
+// The signature is the same as an OTLPTraceContextFunc; the same function
+// may be used to configure both.
+type GCPTraceContextFunc = func(context.Context) (traceID [16]byte, spanID [8]byte, ok bool)
+
+// the special fields of a structured log recognised by Cloud Logging
+const (
+	gcpLabelsKey         = "logging.googleapis.com/labels"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpSpanIDKey         = "logging.googleapis.com/spanId"
+	gcpTraceKey          = "logging.googleapis.com/trace"
+)
+
+// gcpSeverity is the Cloud Logging LogSeverity for each Level.
+var gcpSeverity = [numLevels]string{
+	TraceLevel: "DEBUG",
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO",
+	WarnLevel:  "WARNING",
+	ErrorLevel: "ERROR",
+	FatalLevel: "CRITICAL",
+}
+
+// GCPFormatter returns a function that configures a formatter that writes
+// log entries as structured JSON logs recognised by Google Cloud Logging,
+// e.g. when written to stdout on GKE, Cloud Run or Cloud Functions, with
+// specified configuration options applied.
+//
+// Each log has the following fields:
+//
+//   - severity is the LogSeverity corresponding to the Level of the entry
+//     (TraceLevel and DebugLevel are both DEBUG; FatalLevel is CRITICAL)
+//   - message is the message of the entry
+//   - time is the time of the entry (RFC 3339, with nanoseconds)
+//
+// If the entry has a callsite, logging.googleapis.com/sourceLocation
+// identifies the file, line and function.
+//
+// If a trace context function is configured (see: GCPTraceContext) and
+// the context of the entry contains a valid span, logging.googleapis.com/trace
+// and logging.googleapis.com/spanId identify the span; the trace is
+// qualified by the project id if configured (see: GCPProjectID).
+//
+// logging.googleapis.com/labels contains any static labels (see: GCPLabels)
+// and the values of any fields configured as labels (see: GCPLabelFields).
+//
+// All other fields of the entry are added to the log (becoming fields of
+// the jsonPayload of the LogEntry), with error values written as the
+// error string.  A field with the same name as any of the special fields
+// above is omitted.
+func GCPFormatter(opts ...GCPFormatterOption) FormatterFactory {
+	return func() (Formatter, error) {
+		gf := &gcpfmt{}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(gf))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		return gf, nil
+	}
+}
+
+type gcpfmt struct {
+	projectID    string              // the project id used to qualify trace ids; "" if not configured
+	labels       map[string]string   // static labels added to all logs
+	labelFields  []string            // names of fields written as labels
+	traceContext GCPTraceContextFunc // function returning the trace context of an entry; nil if not configured
+}
+
+// isLabelField returns true if a specified field is configured as a label.
+func (w *gcpfmt) isLabelField(k string) bool {
+	for _, f := range w.labelFields {
+		if f == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements a Formatter that writes log entries as Google Cloud
+// Logging structured logs.
+func (w *gcpfmt) Format(id int, e entry, b ByteWriter) {
+	log := map[string]any{}
+
+	labels := make(map[string]string, len(w.labels)+len(w.labelFields))
+	for k, v := range w.labels {
+		labels[k] = v
+	}
+
+	if e.logcontext != nil && e.fields != nil {
+		for k, v := range e.fields.m {
+			if w.isLabelField(k) {
+				labels[k] = fmt.Sprintf("%v", v)
+				continue
+			}
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			log[k] = v
+		}
+	}
+
+	log["severity"] = gcpSeverity[e.Level]
+	log["message"] = e.Message
+	log["time"] = e.Time.Format(time.RFC3339Nano)
+
+	delete(log, gcpLabelsKey)
+	if len(labels) > 0 {
+		log[gcpLabelsKey] = labels
+	}
+
+	delete(log, gcpSourceLocationKey)
+	if e.callsite != nil {
+		log[gcpSourceLocationKey] = map[string]string{
+			"file":     e.callsite.file,
+			"line":     strconv.Itoa(e.callsite.line),
+			"function": e.callsite.function,
+		}
+	}
+
+	delete(log, gcpTraceKey)
+	delete(log, gcpSpanIDKey)
+	if w.traceContext != nil && e.logcontext != nil && e.ctx != nil {
+		if traceID, spanID, ok := w.traceContext(e.ctx); ok {
+			trace := hex.EncodeToString(traceID[:])
+			if w.projectID != "" {
+				trace = "projects/" + w.projectID + "/traces/" + trace
+			}
+			log[gcpTraceKey] = trace
+			log[gcpSpanIDKey] = hex.EncodeToString(spanID[:])
+		}
+	}
+
+	writeJSONObject(b, log, "GCP")
+}
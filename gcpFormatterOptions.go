@@ -0,0 +1,62 @@
+package ulog
+
+import "fmt"
+
+// GCPLabelFields configures the names of fields whose values are written
+// as labels (in logging.googleapis.com/labels) rather than as fields of
+// the log.  Label values are written as strings.  This option may be
+// specified multiple times; field names are accumulated.
+func GCPLabelFields(names ...string) GCPFormatterOption {
+	return func(gf *gcpfmt) error {
+		gf.labelFields = append(gf.labelFields, names...)
+		return nil
+	}
+}
+
+// GCPLabels configures static labels that are added to all logs.  A
+// field configured as a label (see: GCPLabelFields) with the same name
+// as a static label replaces the static label.  This option may be
+// specified multiple times; labels are accumulated, with any label
+// specified more than once taking the most recently configured value.
+func GCPLabels(labels map[string]string) GCPFormatterOption {
+	return func(gf *gcpfmt) error {
+		if gf.labels == nil {
+			gf.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			gf.labels[k] = v
+		}
+		return nil
+	}
+}
+
+// GCPProjectID configures the id of the Google Cloud project used to
+// qualify trace ids, i.e. projects/<project id>/traces/<trace id>, as
+// required to correlate logs with traces in Cloud Trace.
+//
+// By default, trace ids are not qualified.
+func GCPProjectID(s string) GCPFormatterOption {
+	return func(gf *gcpfmt) error {
+		if s == "" {
+			return fmt.Errorf("%w: GCPProjectID: project id is required", ErrInvalidConfiguration)
+		}
+		gf.projectID = s
+		return nil
+	}
+}
+
+// GCPTraceContext configures a function used to obtain the trace id and
+// span id of logs from the context of each entry.
+//
+// ulog does not depend on any tracing library; the function provides the
+// trace context using whichever library is used by the application (see:
+// GCPTraceContextFunc).
+func GCPTraceContext(fn GCPTraceContextFunc) GCPFormatterOption {
+	return func(gf *gcpfmt) error {
+		if fn == nil {
+			return fmt.Errorf("%w: GCPTraceContext: function is nil", ErrInvalidConfiguration)
+		}
+		gf.traceContext = fn
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package ulog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestGCPFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *gcpfmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "GCPLabelFields",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := GCPLabelFields("a")(sut)
+				err2 := GCPLabelFields("b", "c")(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Slice(t, sut.labelFields).Equals([]string{"a", "b", "c"})
+			},
+		},
+		{scenario: "GCPLabels",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := GCPLabels(map[string]string{"a": "1"})(sut)
+				err2 := GCPLabels(map[string]string{"b": "2"})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Map(t, sut.labels).Equals(map[string]string{"a": "1", "b": "2"})
+			},
+		},
+		{scenario: "GCPProjectID",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GCPProjectID("project")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.projectID).Equals("project")
+			},
+		},
+		{scenario: "GCPProjectID/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GCPProjectID("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "GCPTraceContext",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fn := func(context.Context) ([16]byte, [8]byte, bool) { return [16]byte{}, [8]byte{}, false }
+
+				// ACT
+				err := GCPTraceContext(fn)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.traceContext).IsNotNil()
+			},
+		},
+		{scenario: "GCPTraceContext/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GCPTraceContext(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &gcpfmt{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package ulog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestGCPFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+	traceID := [16]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	spanID := [8]byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}
+
+	type ctxkey struct{}
+	traceContext := func(ctx context.Context) ([16]byte, [8]byte, bool) {
+		if ctx.Value(ctxkey{}) == nil {
+			return [16]byte{}, [8]byte{}, false
+		}
+		return traceID, spanID, true
+	}
+
+	// format returns the log formatted for an entry, decoded into a map
+	format := func(t *testing.T, sut *gcpfmt, e entry) map[string]any {
+		buf := &bytes.Buffer{}
+		sut.Format(0, e, buf)
+		m := map[string]any{}
+		test.Error(t, json.Unmarshal(buf.Bytes(), &m)).IsNil()
+		return m
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// GCPFormatter tests
+		{scenario: "GCPFormatter",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := GCPFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*gcpfmt)).Equals(&gcpfmt{})
+			},
+		},
+		{scenario: "GCPFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := GCPFormatter(func(*gcpfmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// Format tests
+		{scenario: "Format",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{}
+				e := entry{Time: tm, Level: WarnLevel, Message: "message"}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"severity": "WARNING",
+					"message":  "message",
+					"time":     "2010-09-08T07:06:05.4321Z",
+				})
+			},
+		},
+		{scenario: "Format/severity",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{}
+				testcases := []struct {
+					level    Level
+					severity string
+				}{
+					{level: TraceLevel, severity: "DEBUG"},
+					{level: DebugLevel, severity: "DEBUG"},
+					{level: InfoLevel, severity: "INFO"},
+					{level: WarnLevel, severity: "WARNING"},
+					{level: ErrorLevel, severity: "ERROR"},
+					{level: FatalLevel, severity: "CRITICAL"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.level.String(), func(t *testing.T) {
+						// ACT
+						result := format(t, sut, entry{Time: tm, Level: tc.level})
+
+						// ASSERT
+						test.That(t, result["severity"]).Equals(any(tc.severity))
+					})
+				}
+			},
+		},
+		{scenario: "Format/callsite",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{}
+				e := entry{
+					Time:     tm,
+					Level:    InfoLevel,
+					Message:  "message",
+					callsite: &callsite{file: "file.go", line: 42, function: "pkg.fn"},
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result[gcpSourceLocationKey]).Equals(any(map[string]any{
+					"file":     "file.go",
+					"line":     "42",
+					"function": "pkg.fn",
+				}))
+			},
+		},
+		{scenario: "Format/fields and labels",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{
+					labels:      map[string]string{"env": "prod", "region": "eu"},
+					labelFields: []string{"region", "tenant"},
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{
+						"key":       "value",
+						"err":       errors.New("failed"),
+						"region":    "us",
+						"tenant":    42,
+						"severity":  "overridden",
+						gcpTraceKey: "overridden",
+					})},
+					Time:    tm,
+					Level:   InfoLevel,
+					Message: "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"severity": "INFO",
+					"message":  "message",
+					"time":     "2010-09-08T07:06:05.4321Z",
+					"key":      "value",
+					"err":      "failed",
+					gcpLabelsKey: map[string]any{
+						"env":    "prod",
+						"region": "us",
+						"tenant": "42",
+					},
+				})
+			},
+		},
+		{scenario: "Format/unsupported field value",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"ratio": math.NaN()})},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["ratio"]).Equals(any("GCP_ERROR: marshalling error: json: unsupported value: NaN"))
+			},
+		},
+		{scenario: "Format/trace context",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{traceContext: traceContext}
+				e := entry{
+					logcontext: &logcontext{ctx: context.WithValue(context.Background(), ctxkey{}, true)},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result[gcpTraceKey]).Equals(any("0102030405060708090a0b0c0d0e0f10"))
+				test.That(t, result[gcpSpanIDKey]).Equals(any("1112131415161718"))
+			},
+		},
+		{scenario: "Format/trace context/project id",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{projectID: "project", traceContext: traceContext}
+				e := entry{
+					logcontext: &logcontext{ctx: context.WithValue(context.Background(), ctxkey{}, true)},
+					Time:       tm,
+					Level:      InfoLevel,
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result[gcpTraceKey]).Equals(any("projects/project/traces/0102030405060708090a0b0c0d0e0f10"))
+			},
+		},
+		{scenario: "Format/trace context/no span",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gcpfmt{traceContext: traceContext}
+				e := entry{
+					logcontext: &logcontext{ctx: context.Background()},
+					Time:       tm,
+					Level:      InfoLevel,
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				_, hasTrace := result[gcpTraceKey]
+				_, hasSpan := result[gcpSpanIDKey]
+				test.IsFalse(t, hasTrace)
+				test.IsFalse(t, hasSpan)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package ulog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+type GELFFormatterOption func(*gelffmt) error // GELFFormatterOption is a function for configuring a GELF formatter
+
+// GELFFormatter returns a function that configures a formatter that
+// writes log entries as GELF 1.1 (Graylog Extended Log Format) JSON
+// messages, with specified configuration options applied.
+//
+// Each message has the following fields:
+//
+//   - version is "1.1"
+//   - host identifies the host (see: GELFHost)
+//   - short_message is the first line of the message of the entry
+//   - full_message is the complete message of the entry (if the message
+//     has more than one line)
+//   - timestamp is the time of the entry, in seconds since the epoch
+//     (with milliseconds)
+//   - level is the syslog severity corresponding to the Level of the entry
+//
+// If the entry has a callsite, the file, line and function are added as
+// the additional fields _file, _line and _function.
+//
+// Each field of the entry is added as an additional field, with the name
+// of the field prefixed with '_' and any characters other than letters,
+// digits, '_', '.' and '-' replaced by '_'.  A field named "id" (which GELF
+// reserves) is added as "_id_".  Numeric values are written as numbers; all
+// other values are written as strings, with struct values written as JSON.
+//
+// The GELF formatter is intended for use with a GELFTransport.
+func GELFFormatter(opts ...GELFFormatterOption) FormatterFactory {
+	return func() (Formatter, error) {
+		hostname, _ := os.Hostname()
+
+		gf := &gelffmt{host: hostname}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(gf))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		return gf, nil
+	}
+}
+
+type gelffmt struct {
+	host   string         // the host field of messages
+	static map[string]any // additional fields added to all messages
+}
+
+// gelfFieldName returns the name of the additional field for a specified
+// field name.
+func gelfFieldName(s string) string {
+	if s == "id" {
+		return "_id_"
+	}
+	b := []byte(s)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			b[i] = '_'
+		}
+	}
+	return "_" + string(b)
+}
+
+// gelfValue returns a value for an additional field.  GELF permits only
+// string and numeric values; numeric values are returned unchanged and
+// all other values are returned as strings.
+func gelfValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Struct || (rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct) {
+		j, err := jsonMarshal(v)
+		if err != nil {
+			return fmt.Sprintf("GELF_ERROR: marshalling error: %v", err)
+		}
+		return string(j)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// Format implements a Formatter that writes log entries as GELF messages.
+func (w *gelffmt) Format(id int, e entry, b ByteWriter) {
+	msg := map[string]any{
+		"version":   "1.1",
+		"host":      w.host,
+		"timestamp": json.Number(fmt.Sprintf("%d.%03d", e.Time.Unix(), e.Time.Nanosecond()/1e6)),
+		"level":     syslogSeverity[e.Level],
+	}
+
+	msg["short_message"] = e.Message
+	if short, _, multiline := strings.Cut(e.Message, "\n"); multiline {
+		msg["short_message"] = short
+		msg["full_message"] = e.Message
+	}
+
+	for k, v := range w.static {
+		msg[gelfFieldName(k)] = gelfValue(v)
+	}
+
+	if e.callsite != nil {
+		msg["_file"] = e.callsite.file
+		msg["_line"] = e.callsite.line
+		msg["_function"] = e.callsite.function
+	}
+
+	if e.logcontext != nil && e.fields != nil {
+		for k, v := range e.fields.m {
+			msg[gelfFieldName(k)] = gelfValue(v)
+		}
+	}
+
+	writeJSONObject(b, msg, "GELF")
+}
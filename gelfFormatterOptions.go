@@ -0,0 +1,30 @@
+package ulog
+
+import "fmt"
+
+// GELFAdditionalFields configures additional fields that are added to
+// all messages.  A field of an entry with the same name as a configured
+// field replaces the configured field.
+func GELFAdditionalFields(fields map[string]any) GELFFormatterOption {
+	return func(gf *gelffmt) error {
+		if gf.static == nil {
+			gf.static = make(map[string]any, len(fields))
+		}
+		for k, v := range fields {
+			gf.static[k] = v
+		}
+		return nil
+	}
+}
+
+// GELFHost configures the host field of messages.  The default is the
+// host name reported by the kernel.
+func GELFHost(s string) GELFFormatterOption {
+	return func(gf *gelffmt) error {
+		if s == "" {
+			return fmt.Errorf("%w: GELFHost: host is required", ErrInvalidConfiguration)
+		}
+		gf.host = s
+		return nil
+	}
+}
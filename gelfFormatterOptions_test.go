@@ -0,0 +1,58 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestGELFFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *gelffmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "GELFAdditionalFields",
+			exec: func(t *testing.T) {
+				// ACT
+				err1 := GELFAdditionalFields(map[string]any{"a": 1})(sut)
+				err2 := GELFAdditionalFields(map[string]any{"b": "2"})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Map(t, sut.static).Equals(map[string]any{"a": 1, "b": "2"})
+			},
+		},
+		{scenario: "GELFHost",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFHost("host")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.host).Equals("host")
+			},
+		},
+		{scenario: "GELFHost/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFHost("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &gelffmt{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	// format returns the GELF message formatted for an entry, decoded
+	// into a map
+	format := func(t *testing.T, sut *gelffmt, e entry) map[string]any {
+		buf := &bytes.Buffer{}
+		sut.Format(0, e, buf)
+		m := map[string]any{}
+		test.Error(t, json.Unmarshal(buf.Bytes(), &m)).IsNil()
+		return m
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// GELFFormatter tests
+		{scenario: "GELFFormatter",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hostname, _ := os.Hostname()
+
+				// ACT
+				result, err := GELFFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*gelffmt)).Equals(&gelffmt{host: hostname})
+			},
+		},
+		{scenario: "GELFFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := GELFFormatter(func(*gelffmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// gelfFieldName tests
+		{scenario: "gelfFieldName",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name   string
+					result string
+				}{
+					{name: "key", result: "_key"},
+					{name: "request.id", result: "_request.id"},
+					{name: "a-b_c", result: "_a-b_c"},
+					{name: "a b/c", result: "_a_b_c"},
+					{name: "id", result: "_id_"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := gelfFieldName(tc.name)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// gelfValue tests
+		{scenario: "gelfValue",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				type st struct {
+					A int `json:"a"`
+				}
+				testcases := []struct {
+					name   string
+					value  any
+					result any
+				}{
+					{name: "string", value: "s", result: "s"},
+					{name: "error", value: errors.New("failed"), result: "failed"},
+					{name: "int", value: 42, result: 42},
+					{name: "float", value: 1.5, result: 1.5},
+					{name: "bool", value: true, result: "true"},
+					{name: "struct", value: st{A: 1}, result: `{"a":1}`},
+					{name: "struct ptr", value: &st{A: 2}, result: `{"a":2}`},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := gelfValue(tc.value)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "gelfValue/marshalling error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&jsonMarshal, func(v any) ([]byte, error) { return nil, errors.New("marshalling error") })()
+
+				// ACT
+				result := gelfValue(struct{}{})
+
+				// ASSERT
+				test.That(t, result).Equals("GELF_ERROR: marshalling error: marshalling error")
+			},
+		},
+
+		// Format tests
+		{scenario: "Format",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelffmt{host: "host"}
+				e := entry{Time: tm, Level: WarnLevel, Message: "message"}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.Map(t, result).Equals(map[string]any{
+					"version":       "1.1",
+					"host":          "host",
+					"short_message": "message",
+					"timestamp":     1283929565.432,
+					"level":         4.0,
+				})
+			},
+		},
+		{scenario: "Format/unsupported field value",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelffmt{host: "host"}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"ratio": math.NaN()})},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["_ratio"]).Equals(any("GELF_ERROR: marshalling error: json: unsupported value: NaN"))
+			},
+		},
+		{scenario: "Format/multi-line message",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelffmt{host: "host"}
+				e := entry{Time: tm, Level: ErrorLevel, Message: "message\nline 2"}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["short_message"]).Equals("message")
+				test.That(t, result["full_message"]).Equals("message\nline 2")
+			},
+		},
+		{scenario: "Format/callsite and fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelffmt{host: "host", static: map[string]any{"env": "test", "key": "static"}}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(2).merge(map[string]any{
+						"key":   "value",
+						"count": 42,
+					})},
+					callsite: &callsite{function: "main.main", file: "main.go", line: 10},
+					Time:     tm,
+					Level:    InfoLevel,
+					Message:  "message",
+				}
+
+				// ACT
+				result := format(t, sut, e)
+
+				// ASSERT
+				test.That(t, result["_env"], "static field").Equals("test")
+				test.That(t, result["_key"], "field").Equals("value")
+				test.That(t, result["_count"], "numeric field").Equals(42.0)
+				test.That(t, result["_file"], "file").Equals("main.go")
+				test.That(t, result["_line"], "line").Equals(10.0)
+				test.That(t, result["_function"], "function").Equals("main.main")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package ulog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+type GELFOption = func(*gelf) error // GELFOption is a function that configures a GELF transport
+
+// GELFCompressionMethod identifies the method used to compress messages
+// sent over udp.
+type GELFCompressionMethod int
+
+const (
+	GELFUncompressed GELFCompressionMethod = iota // GELFUncompressed sends messages without compression
+	GELFGzip                                      // GELFGzip compresses messages using gzip
+	GELFZlib                                      // GELFZlib compresses messages using zlib
+)
+
+const (
+	gelfChunkHeaderSize = 12  // the size of the header of a chunk
+	gelfMaxChunks       = 128 // the maximum number of chunks in a message
+)
+
+// gelfChunkMagic are the magic bytes identifying a chunked message.
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// GELFTransport returns a transport factory function to create and
+// configure a transport that sends log entries to a Graylog (or other
+// GELF compatible) input, with specified configuration options applied.
+// The network and address of the input must be configured using the
+// GELFNetwork option.
+//
+// Each entry is formatted by the target Formatter, which should be a
+// GELFFormatter.
+//
+// Messages sent over udp may be compressed (see: GELFCompression).  A
+// message that is larger than the chunk size (see: GELFChunkSize) is
+// sent in chunks; a message requiring more than 128 chunks is discarded.
+//
+// Messages sent over tcp are terminated by a null byte and may not be
+// compressed.  If the connection to the input fails, the transport
+// reconnects with a backoff; messages logged while not connected are
+// discarded.
+func GELFTransport(opts ...GELFOption) TransportFactory {
+	return func() (transport, error) {
+		t := &gelf{
+			ch:          make(chan []byte, 100),
+			chunkSize:   1420,
+			dialTimeout: 5 * time.Second,
+			conn: &netconn{
+				name:       "gelf",
+				minBackoff: 100 * time.Millisecond,
+				maxBackoff: 30 * time.Second,
+			},
+		}
+		t.conn.dial = t.dial
+		t.conn.frame = t.frame
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case t.network == "":
+			return nil, fmt.Errorf("%w: network and address are required", ErrGELFConfiguration)
+		case t.tls != nil && t.network != "tcp":
+			return nil, fmt.Errorf("%w: %w: tls requires a tcp network", ErrGELFConfiguration, ErrInvalidConfiguration)
+		case t.compression != GELFUncompressed && t.network != "udp":
+			return nil, fmt.Errorf("%w: %w: compression requires a udp network", ErrGELFConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// gelf implements a transport that sends log entries to a GELF input.
+type gelf struct {
+	ch          chan []byte           // channel over which messages are received
+	conn        *netconn              // the connection to the input
+	network     string                // the network of the input ("udp" or "tcp")
+	address     string                // the address of the input
+	tls         *tls.Config           // tls configuration for a tcp connection; nil if tls is not used
+	dialTimeout time.Duration         // timeout for establishing a connection
+	compression GELFCompressionMethod // the compression applied to udp messages
+	chunkSize   int                   // the maximum size of a udp datagram
+}
+
+// dial establishes a connection to the configured network and address.
+func (t *gelf) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: t.dialTimeout}
+	if t.tls != nil {
+		return tls.DialWithDialer(d, t.network, t.address, t.tls)
+	}
+	return d.Dial(t.network, t.address)
+}
+
+// frame applies the framing required for a connection to a message.
+// Messages sent over a tcp connection are terminated by a null byte;
+// datagrams are not framed.
+func (t *gelf) frame(conn net.Conn, msg []byte) []byte {
+	switch conn.RemoteAddr().Network() {
+	case "tcp", "tcp4", "tcp6":
+		return append(msg, 0)
+	}
+	return msg
+}
+
+// compress returns a message compressed using the configured method.
+func (t *gelf) compress(msg []byte) []byte {
+	var w io.WriteCloser
+	buf := bytes.NewBuffer(make([]byte, 0, len(msg)/2))
+	switch t.compression {
+	case GELFGzip:
+		w = gzip.NewWriter(buf)
+	case GELFZlib:
+		w = zlib.NewWriter(buf)
+	default:
+		return msg
+	}
+	_, _ = w.Write(msg)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+// chunk returns the chunks required to send a message that is larger than
+// the chunk size.  Each chunk has a 12 byte header:
+//
+//	<magic: 0x1e 0x0f><message id: 8 bytes><sequence number><sequence count>
+func (t *gelf) chunk(msg []byte) ([][]byte, error) {
+	size := t.chunkSize - gelfChunkHeaderSize
+	n := (len(msg) + size - 1) / size
+	if n > gelfMaxChunks {
+		return nil, fmt.Errorf("message too large: %d bytes requires %d chunks (max %d)", len(msg), n, gelfMaxChunks)
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	chunks := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		data := msg[i*size : min((i+1)*size, len(msg))]
+		c := make([]byte, 0, gelfChunkHeaderSize+len(data))
+		c = append(c, gelfChunkMagic...)
+		c = append(c, id...)
+		c = append(c, byte(i), byte(n))
+		chunks = append(chunks, append(c, data...))
+	}
+	return chunks, nil
+}
+
+// log sends a formatted log entry to the transport.
+func (t *gelf) log(b []byte) {
+	// the slice is owned by the target and will be re-used, so the
+	// contents must be copied before sending to the transport channel
+	msg := make([]byte, len(b), len(b)+1)
+	copy(msg, b)
+
+	t.ch <- msg
+}
+
+// write sends a message to the input.  A udp message is compressed (if
+// configured) and sent in chunks if larger than the chunk size.
+func (t *gelf) write(msg []byte) error {
+	if t.network != "udp" {
+		return t.conn.write(msg)
+	}
+
+	msg = t.compress(msg)
+	if len(msg) <= t.chunkSize {
+		return t.conn.write(msg)
+	}
+
+	chunks, err := t.chunk(msg)
+	if err != nil {
+		return err
+	}
+	if err := t.conn.connect(); err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if err := t.conn.send(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stop closes the channel over which messages are received.
+func (t *gelf) stop() {
+	tracef("gelf: transport requested to stop...")
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  The run loop
+// terminates when the channel over which messages are received is
+// closed, closing any established connection.
+func (t *gelf) run() {
+	for msg := range t.ch {
+		if err := t.write(msg); err != nil {
+			trace("gelf: message discarded: " + err.Error())
+		}
+	}
+	t.conn.disconnect()
+	tracef("gelf: transport stopped")
+}
@@ -0,0 +1,95 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+)
+
+// GELFChunkSize configures the maximum size of a udp datagram, including
+// the 12 byte header of a chunk.  A message larger than this is sent in
+// chunks.  The default is 1420 bytes; a larger size (up to 8192 bytes)
+// may be appropriate on a local network.
+func GELFChunkSize(n int) GELFOption {
+	return func(t *gelf) error {
+		if n <= gelfChunkHeaderSize || n > 8192 {
+			return fmt.Errorf("%w: GELFChunkSize: %d: must be > %d and <= 8192", ErrInvalidConfiguration, n, gelfChunkHeaderSize)
+		}
+		t.chunkSize = n
+		return nil
+	}
+}
+
+// GELFCompression configures the compression applied to messages sent
+// over udp.  The default is GELFUncompressed.
+func GELFCompression(m GELFCompressionMethod) GELFOption {
+	return func(t *gelf) error {
+		switch m {
+		case GELFUncompressed, GELFGzip, GELFZlib:
+			t.compression = m
+			return nil
+		default:
+			return fmt.Errorf("%w: GELFCompression: invalid method (%d)", ErrInvalidConfiguration, m)
+		}
+	}
+}
+
+// GELFDialTimeout configures the timeout for establishing a connection.
+// The default is 5 seconds.
+func GELFDialTimeout(d time.Duration) GELFOption {
+	return func(t *gelf) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: GELFDialTimeout: must be > 0", ErrInvalidConfiguration)
+		}
+		t.dialTimeout = d
+		return nil
+	}
+}
+
+// GELFNetwork configures the network and address of the input to which
+// messages are sent.  The network must be "udp" or "tcp".
+//
+// e.g. GELFNetwork("udp", "graylog.example.com:12201")
+func GELFNetwork(network, address string) GELFOption {
+	return func(t *gelf) error {
+		switch network {
+		case "udp", "tcp":
+		default:
+			return fmt.Errorf("%w: GELFNetwork: %q: network must be udp or tcp", ErrInvalidConfiguration, network)
+		}
+		if address == "" {
+			return fmt.Errorf("%w: GELFNetwork: address is required", ErrInvalidConfiguration)
+		}
+		t.network = network
+		t.address = address
+		return nil
+	}
+}
+
+// GELFReconnect configures the delay before attempting to reconnect
+// after a connection fails, and the maximum delay.  The delay is doubled
+// after each consecutive failure, up to the maximum.  The defaults are
+// 100ms and 30s respectively.
+func GELFReconnect(backoff, maxBackoff time.Duration) GELFOption {
+	return func(t *gelf) error {
+		if backoff <= 0 || maxBackoff < backoff {
+			return fmt.Errorf("%w: GELFReconnect: backoff must be > 0 and <= max backoff", ErrInvalidConfiguration)
+		}
+		t.conn.minBackoff = backoff
+		t.conn.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
+// GELFTLS configures the transport to connect to a tcp network using
+// TLS, with a specified configuration.  A nil configuration uses the
+// default configuration.
+func GELFTLS(cfg *tls.Config) GELFOption {
+	return func(t *gelf) error {
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		t.tls = cfg
+		return nil
+	}
+}
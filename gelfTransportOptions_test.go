@@ -0,0 +1,161 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestGELFTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *gelf
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "GELFChunkSize",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFChunkSize(8192)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.chunkSize).Equals(8192)
+			},
+		},
+		{scenario: "GELFChunkSize/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					GELFChunkSize(gelfChunkHeaderSize)(sut),
+					GELFChunkSize(8193)(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "GELFCompression",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFCompression(GELFZlib)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.compression).Equals(GELFZlib)
+			},
+		},
+		{scenario: "GELFCompression/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFCompression(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "GELFDialTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFDialTimeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.dialTimeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "GELFDialTimeout/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFDialTimeout(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "GELFNetwork",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFNetwork("tcp", "localhost:12201")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.network).Equals("tcp")
+				test.That(t, sut.address).Equals("localhost:12201")
+			},
+		},
+		{scenario: "GELFNetwork/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					GELFNetwork("unix", "/tmp/gelf.sock")(sut),
+					GELFNetwork("udp", "")(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "GELFReconnect",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFReconnect(time.Second, time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.conn.minBackoff).Equals(time.Second)
+				test.That(t, sut.conn.maxBackoff).Equals(time.Minute)
+			},
+		},
+		{scenario: "GELFReconnect/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					GELFReconnect(0, time.Minute)(sut),
+					GELFReconnect(time.Minute, time.Second)(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "GELFTLS",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cfg := &tls.Config{ServerName: "graylog"}
+
+				// ACT
+				err := GELFTLS(cfg)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tls).Equals(cfg)
+			},
+		},
+		{scenario: "GELFTLS/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := GELFTLS(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tls).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &gelf{conn: &netconn{}}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
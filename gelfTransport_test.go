@@ -0,0 +1,443 @@
+package ulog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestGELFTransport(t *testing.T) {
+	// ARRANGE
+
+	// logto returns a logger that logs GELF messages to a GELF transport
+	// configured with specified options
+	logto := func(t *testing.T, opts ...GELFOption) (Logger, func()) {
+		logger, closelog, err := NewLogger(context.Background(),
+			Mux(
+				MuxTarget(
+					TargetLevel(InfoLevel),
+					TargetFormat(GELFFormatter(GELFHost("host"))),
+					TargetTransport(GELFTransport(opts...)),
+				),
+			),
+		)
+		test.Error(t, err).IsNil()
+		return logger, closelog
+	}
+
+	// mockgelf returns a gelf transport with a mock udp connection
+	mockgelf := func(conn *mockconn) *gelf {
+		sut := &gelf{network: "udp", chunkSize: 100}
+		sut.conn = &netconn{name: "gelf", dial: func() (net.Conn, error) { return conn, nil }}
+		return sut
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// GELFTransport tests
+		{scenario: "GELFTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*gelf) error { return opterr }
+
+				// ACT
+				result, err := GELFTransport(opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "GELFTransport/no network",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := GELFTransport()()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrGELFConfiguration)
+			},
+		},
+		{scenario: "GELFTransport/tls without tcp",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := GELFTransport(GELFNetwork("udp", "localhost:12201"), GELFTLS(nil))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrGELFConfiguration)
+			},
+		},
+		{scenario: "GELFTransport/compression without udp",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := GELFTransport(GELFNetwork("tcp", "localhost:12201"), GELFCompression(GELFGzip))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrGELFConfiguration)
+			},
+		},
+		{scenario: "GELFTransport/defaults",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := GELFTransport(GELFNetwork("udp", "localhost:12201"))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*gelf](t, result); ok {
+					test.That(t, result.chunkSize, "chunk size").Equals(1420)
+					test.That(t, result.compression, "compression").Equals(GELFUncompressed)
+					test.That(t, result.dialTimeout, "dial timeout").Equals(5 * time.Second)
+					test.That(t, result.conn.minBackoff, "min backoff").Equals(100 * time.Millisecond)
+					test.That(t, result.conn.maxBackoff, "max backoff").Equals(30 * time.Second)
+				}
+			},
+		},
+
+		// frame tests
+		{scenario: "frame",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelf{}
+
+				// ACT
+				tcp := sut.frame(&mockconn{network: "tcp"}, []byte("message"))
+				udp := sut.frame(&mockconn{network: "udp"}, []byte("message"))
+
+				// ASSERT
+				test.That(t, string(tcp), "tcp").Equals("message\x00")
+				test.That(t, string(udp), "udp").Equals("message")
+			},
+		},
+
+		// compress tests
+		{scenario: "compress",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				msg := []byte(strings.Repeat("message ", 10))
+				testcases := []struct {
+					name   string
+					method GELFCompressionMethod
+					reader func(io.Reader) (io.Reader, error)
+				}{
+					{name: "uncompressed", method: GELFUncompressed, reader: func(r io.Reader) (io.Reader, error) { return r, nil }},
+					{name: "gzip", method: GELFGzip, reader: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+					{name: "zlib", method: GELFZlib, reader: func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ARRANGE
+						sut := &gelf{compression: tc.method}
+
+						// ACT
+						result := sut.compress(msg)
+
+						// ASSERT
+						r, err := tc.reader(bytes.NewReader(result))
+						test.Error(t, err).IsNil()
+						b, err := io.ReadAll(r)
+						test.Error(t, err).IsNil()
+						test.That(t, string(b)).Equals(string(msg))
+					})
+				}
+			},
+		},
+
+		// chunk tests
+		{scenario: "chunk",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelf{chunkSize: 20}
+				msg := []byte(strings.Repeat("x", 20))
+
+				// ACT
+				result, err := sut.chunk(msg)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result), "chunks").Equals(3)
+				for i, c := range result {
+					test.That(t, c[:2], "magic").Equals(gelfChunkMagic)
+					test.That(t, c[2:10], "message id").Equals(result[0][2:10])
+					test.That(t, int(c[10]), "sequence number").Equals(i)
+					test.That(t, int(c[11]), "sequence count").Equals(3)
+				}
+				test.That(t, len(result[0]), "first chunk").Equals(20)
+				test.That(t, len(result[2]), "last chunk").Equals(16)
+			},
+		},
+		{scenario: "chunk/too many chunks",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelf{chunkSize: 13}
+				msg := make([]byte, gelfMaxChunks+1)
+
+				// ACT
+				result, err := sut.chunk(msg)
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.That(t, err).IsNotNil()
+			},
+		},
+
+		// log tests
+		{scenario: "log",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &gelf{ch: make(chan []byte, 1)}
+				b := []byte("message")
+
+				// ACT
+				sut.log(b)
+				copy(b, "changed")
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("message")
+			},
+		},
+
+		// write tests
+		{scenario: "write/chunked",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn := &mockconn{network: "udp"}
+				sut := mockgelf(conn)
+
+				// ACT
+				err := sut.write(make([]byte, 250))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(conn.written), "datagrams").Equals(3)
+			},
+		},
+		{scenario: "write/chunked/too large",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn := &mockconn{network: "udp"}
+				sut := mockgelf(conn)
+
+				// ACT
+				err := sut.write(make([]byte, 100*gelfMaxChunks))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+				test.That(t, len(conn.written), "datagrams").Equals(0)
+			},
+		},
+		{scenario: "write/chunked/not connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dialErr := errors.New("dial error")
+				sut := mockgelf(nil)
+				sut.conn.dial = func() (net.Conn, error) { return nil, dialErr }
+
+				// ACT
+				err := sut.write(make([]byte, 250))
+
+				// ASSERT
+				test.Error(t, err).Is(dialErr)
+			},
+		},
+		{scenario: "write/chunked/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				writeErr := errors.New("write error")
+				conn := &mockconn{network: "udp", writefn: func([]byte) (int, error) { return 0, writeErr }}
+				sut := mockgelf(conn)
+
+				// ACT
+				err := sut.write(make([]byte, 250))
+
+				// ASSERT
+				test.Error(t, err).Is(writeErr)
+				test.IsTrue(t, conn.closeWasCalled, "connection closed")
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "sends over udp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.ListenPacket("udp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				logger, closelog := logto(t, GELFNetwork("udp", l.LocalAddr().String()), GELFCompression(GELFGzip))
+
+				// ACT
+				logger.WithField("key", "value").Warn("message")
+				closelog()
+
+				// ASSERT
+				b := make([]byte, 2048)
+				_ = l.SetReadDeadline(time.Now().Add(time.Second))
+				n, _, err := l.ReadFrom(b)
+				test.Error(t, err).IsNil()
+				r, err := gzip.NewReader(bytes.NewReader(b[:n]))
+				test.Error(t, err).IsNil()
+				msg := map[string]any{}
+				test.Error(t, json.NewDecoder(r).Decode(&msg)).IsNil()
+				test.That(t, msg["short_message"]).Equals("message")
+				test.That(t, msg["level"]).Equals(4.0)
+				test.That(t, msg["_key"]).Equals("value")
+			},
+		},
+		{scenario: "sends over udp/chunked",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.ListenPacket("udp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				logger, closelog := logto(t, GELFNetwork("udp", l.LocalAddr().String()), GELFChunkSize(512))
+				message := strings.Repeat("x", 1200)
+
+				// ACT
+				logger.Info(message)
+				closelog()
+
+				// ASSERT
+				chunks := map[byte][]byte{}
+				count := 0
+				b := make([]byte, 1024)
+				_ = l.SetReadDeadline(time.Now().Add(time.Second))
+				for count == 0 || len(chunks) < count {
+					n, _, err := l.ReadFrom(b)
+					if err != nil {
+						t.Fatal(err)
+					}
+					test.That(t, b[:2], "magic").Equals(gelfChunkMagic)
+					chunks[b[10]] = append([]byte{}, b[gelfChunkHeaderSize:n]...)
+					count = int(b[11])
+				}
+				data := []byte{}
+				for i := 0; i < count; i++ {
+					data = append(data, chunks[byte(i)]...)
+				}
+				msg := map[string]any{}
+				test.Error(t, json.Unmarshal(data, &msg)).IsNil()
+				test.That(t, count, "chunks").Equals(3)
+				test.That(t, msg["short_message"]).Equals(message)
+			},
+		},
+		{scenario: "sends over tcp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := make(chan []byte, 1)
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					b, _ := readAll(conn)
+					received <- b
+				}()
+
+				logger, closelog := logto(t, GELFNetwork("tcp", l.Addr().String()))
+
+				// ACT
+				logger.Info("message 1")
+				logger.Info("message 2")
+				closelog()
+
+				// ASSERT
+				select {
+				case b := <-received:
+					msgs := bytes.Split(bytes.TrimSuffix(b, []byte{0}), []byte{0})
+					test.That(t, len(msgs), "messages").Equals(2)
+					msg := map[string]any{}
+					test.Error(t, json.Unmarshal(msgs[1], &msg)).IsNil()
+					test.That(t, msg["short_message"]).Equals("message 2")
+				case <-time.After(time.Second):
+					t.Fatal("timed out")
+				}
+			},
+		},
+		{scenario: "sends over tls",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewTLSServer(http.NotFoundHandler())
+				defer srv.Close()
+
+				l, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := make(chan []byte, 1)
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					b, _ := readAll(conn)
+					received <- b
+				}()
+
+				cfg := srv.Client().Transport.(*http.Transport).TLSClientConfig
+				logger, closelog := logto(t,
+					GELFNetwork("tcp", l.Addr().String()),
+					GELFTLS(cfg),
+				)
+
+				// ACT
+				logger.Info("message")
+				closelog()
+
+				// ASSERT
+				select {
+				case b := <-received:
+					test.IsTrue(t, bytes.HasSuffix(b, []byte("}\x00")), "null terminated")
+					test.IsTrue(t, bytes.Contains(b, []byte(`"short_message":"message"`)), "message")
+				case <-time.After(time.Second):
+					t.Fatal("timed out")
+				}
+			},
+		},
+		{scenario: "sends over tcp/not connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				addr := l.Addr().String()
+				l.Close()
+
+				logger, closelog := logto(t, GELFNetwork("tcp", addr), GELFDialTimeout(100*time.Millisecond))
+
+				// ACT
+				logger.Info("message")
+				closelog()
+
+				// ASSERT
+				// message is discarded (no panic, no deadlock)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package ulog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/blugnu/msgpack"
+)
+
+const (
+	httpAuthorization = cfgkey("http.authorization")
+	httpEncoding      = cfgkey("http.encoding")
+	httpEndpoint      = cfgkey("http.endpoint")
+	httpHeaders       = cfgkey("http.headers")
+	httpHTTPClient    = cfgkey("http.httpClient")
+	httpMethod        = cfgkey("http.method")
+	httpTimeout       = cfgkey("http.timeout")
+)
+
+// HTTPBatchEncoding identifies the encoding of the body of a request sent
+// by an HTTP transport, determining how the formatted entries in a batch
+// are combined.
+type HTTPBatchEncoding int
+
+const (
+	NDJSONEncoding       HTTPBatchEncoding = iota // NDJSONEncoding sends entries separated by newlines (application/x-ndjson)
+	JSONArrayEncoding                             // JSONArrayEncoding sends entries as elements of a JSON array (application/json)
+	MsgpackArrayEncoding                          // MsgpackArrayEncoding sends entries as elements of a msgpack array (application/msgpack)
+)
+
+// contentType returns the Content-Type of a request body with the encoding.
+func (enc HTTPBatchEncoding) contentType() string {
+	switch enc {
+	case JSONArrayEncoding:
+		return "application/json"
+	case MsgpackArrayEncoding:
+		return "application/msgpack"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// httpBatchHandler is a batch handler that sends batches of formatted
+// entries to an http endpoint.
+type httpBatchHandler struct {
+	httpSender
+	endpoint      string
+	method        string
+	authorization string
+	encoding      HTTPBatchEncoding
+	buf           *sync.Pool
+}
+
+// newHTTPBatchHandler creates a new, initialised http batch handler.
+func newHTTPBatchHandler() *httpBatchHandler {
+	return &httpBatchHandler{
+		httpSender: newHTTPSender(),
+		method:     http.MethodPost,
+		encoding:   NDJSONEncoding,
+		buf:        &sync.Pool{New: func() any { return &bytes.Buffer{} }},
+	}
+}
+
+// configure applies configuration to the http batch handler.
+func (h *httpBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case httpAuthorization:
+		h.authorization = value.(string)
+	case httpEncoding:
+		h.encoding = value.(HTTPBatchEncoding)
+	case httpEndpoint:
+		h.endpoint = value.(string)
+	case httpHeaders:
+		h.setHeaders(value.(map[string]string))
+	case httpHTTPClient:
+		h.client = value.(*http.Client)
+	case httpMethod:
+		h.method = value.(string)
+	case httpTimeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrHTTPConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// encode writes the entries in a batch to a buffer using the configured
+// encoding.
+func (h *httpBatchHandler) encode(buf *bytes.Buffer, batch *Batch) error {
+	switch h.encoding {
+	case JSONArrayEncoding:
+		_ = buf.WriteByte('[')
+		for i, e := range batch.entries {
+			if i > 0 {
+				_ = buf.WriteByte(',')
+			}
+			_, _ = buf.Write(e)
+		}
+		_ = buf.WriteByte(']')
+
+	case MsgpackArrayEncoding:
+		enc, _ := msgpack.NewEncoder(buf)
+		return msgpack.EncodeArray(*enc, batch.entries, func(msg msgpack.Encoder, e []byte) error {
+			return msg.Write(e)
+		})
+
+	default:
+		for _, e := range batch.entries {
+			_, _ = buf.Write(e)
+			_ = buf.WriteByte(char.newline)
+		}
+	}
+	return nil
+}
+
+// Send sends a batch of entries to the configured endpoint.
+//
+// If the request fails or the response has a status indicating that
+// the request may succeed if sent again (e.g. 429 or 5xx), an error is
+// returned and the batch is retained.  Any other unsuccessful response
+// results in the batch being discarded, since sending it again would
+// fail in the same way.
+func (h *httpBatchHandler) Send(batch *Batch) error {
+	tracef("http: send: sending %d entries", batch.len)
+
+	buf := h.buf.Get().(*bytes.Buffer)
+	defer h.buf.Put(buf)
+	buf.Reset()
+
+	if err := h.encode(buf, batch); err != nil {
+		trace("http: send: batch encoding failed: " + err.Error())
+		return err
+	}
+
+	rq, err := h.newRequest(h.method, h.endpoint, buf.Bytes())
+	if err != nil {
+		trace("http: send: error initialising request: " + err.Error())
+		return err
+	}
+	rq.Header.Set("Content-Type", h.encoding.contentType())
+	if h.authorization != "" {
+		rq.Header.Set("Authorization", h.authorization)
+	}
+
+	if _, err := h.do(rq); err != nil {
+		trace("http: send: error sending request: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("http: send: %d entries discarded", batch.len)
+	}
+	return nil
+}
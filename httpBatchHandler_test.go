@@ -0,0 +1,170 @@
+package ulog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestHTTPBatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut   *httpBatchHandler
+		batch = func(entries ...string) *Batch {
+			b := &Batch{}
+			for _, e := range entries {
+				b.entries = append(b.entries, []byte(e))
+				b.size += len(e)
+				b.len++
+			}
+			return b
+		}
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				errs := []error{
+					sut.configure(httpAuthorization, "Bearer token"),
+					sut.configure(httpEncoding, JSONArrayEncoding),
+					sut.configure(httpEndpoint, "http://localhost"),
+					sut.configure(httpHeaders, map[string]string{"X-Custom": "value"}),
+					sut.configure(httpHTTPClient, client),
+					sut.configure(httpTimeout, time.Minute),
+					sut.configure(httpMethod, http.MethodPut),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.authorization).Equals("Bearer token")
+				test.That(t, sut.encoding).Equals(JSONArrayEncoding)
+				test.That(t, sut.endpoint).Equals("http://localhost")
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+				test.That(t, sut.timeout).Equals(time.Minute)
+				test.That(t, sut.method).Equals(http.MethodPut)
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrHTTPConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					encoding    HTTPBatchEncoding
+					contentType string
+					body        []byte
+				}{
+					{encoding: NDJSONEncoding, contentType: "application/x-ndjson", body: []byte("{\"a\":1}\n{\"b\":2}\n")},
+					{encoding: JSONArrayEncoding, contentType: "application/json", body: []byte(`[{"a":1},{"b":2}]`)},
+					{encoding: MsgpackArrayEncoding, contentType: "application/msgpack", body: packedBytes(0x92, "{\"a\":1}", "{\"b\":2}")},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.contentType, func(t *testing.T) {
+						// ARRANGE
+						var (
+							method      string
+							authheader  string
+							contenttype string
+							body        []byte
+						)
+						srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							method = r.Method
+							authheader = r.Header.Get("Authorization")
+							contenttype = r.Header.Get("Content-Type")
+							body, _ = io.ReadAll(r.Body)
+						}))
+						defer srv.Close()
+
+						sut.endpoint = srv.URL
+						sut.authorization = "Bearer token"
+						sut.encoding = tc.encoding
+
+						// ACT
+						err := sut.Send(batch(`{"a":1}`, `{"b":2}`))
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, method).Equals(http.MethodPost)
+						test.That(t, authheader).Equals("Bearer token")
+						test.That(t, contenttype).Equals(tc.contentType)
+						test.That(t, body).Equals(tc.body)
+					})
+				}
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch("entry"))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch("entry"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/invalid endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.endpoint = "\n"
+
+				// ACT
+				err := sut.Send(batch("entry"))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newHTTPBatchHandler()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package ulog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpSender provides the http client and request handling common to
+// batch handlers that send batches to a service over http.
+//
+// A timeout configured using setTimeout is not applied to the client until
+// applyTimeout is called, once all configuration options have been applied,
+// so that the timeout applies to any client supplied by the application
+// regardless of the order of the options.  The timeout is applied to a copy
+// of the client; a client supplied by (and possibly shared with) the
+// application is not modified.
+type httpSender struct {
+	client  *http.Client  // the client used to send requests
+	timeout time.Duration // the timeout applied to the client; 0 = the timeout of the client is used
+	headers http.Header   // additional headers sent with each request
+	retries int           // the number of times a failed request is retried
+	backoff time.Duration // the delay before the first retry; doubled for each subsequent retry
+}
+
+// retryConfig holds the retry configuration of an httpSender, as
+// supplied to the configure method of a batch handler.
+type retryConfig struct {
+	n       int
+	backoff time.Duration
+}
+
+// newHTTPSender returns an httpSender with a client having a timeout
+// of 5 seconds and no additional headers.
+func newHTTPSender() httpSender {
+	return httpSender{
+		client:  &http.Client{Timeout: 5 * time.Second},
+		headers: http.Header{},
+	}
+}
+
+// setHeaders adds headers to be sent with each request.  Any header
+// already configured with the same name is replaced.
+func (s *httpSender) setHeaders(h map[string]string) {
+	for k, v := range h {
+		s.headers.Set(k, v)
+	}
+}
+
+// setTimeout sets the timeout to be applied to the client.
+func (s *httpSender) setTimeout(d time.Duration) {
+	s.timeout = d
+}
+
+// applyTimeout applies any configured timeout to (a copy of) the client.
+func (s *httpSender) applyTimeout() {
+	if s.timeout == 0 {
+		return
+	}
+	c := *s.client
+	c.Timeout = s.timeout
+	s.client = &c
+}
+
+// setRetry configures the number of times a failed request is retried
+// and the delay before the first retry.
+func (s *httpSender) setRetry(n int, backoff time.Duration) {
+	s.retries = n
+	s.backoff = backoff
+}
+
+// newRequest returns a new request with the specified method, url and
+// body, with any additional headers applied.
+func (s *httpSender) newRequest(method string, url string, body []byte) (*http.Request, error) {
+	rq, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.headers {
+		rq.Header[k] = v
+	}
+	return rq, nil
+}
+
+// do sends a request and returns the body of the response.  If the
+// response has a status other than 2xx an ErrUnexpectedResponse error
+// is returned with the body of the response.
+//
+// If retries are configured, a request that fails with a retryable
+// error is sent again after a delay, doubling the delay after each
+// attempt.  The delay blocks the calling (transport) goroutine.
+func (s *httpSender) do(rq *http.Request) ([]byte, error) {
+	body, _, err := s.doResponse(rq)
+	return body, err
+}
+
+// doResponse sends a request in the same way as do, returning the
+// headers of the response as well as the body.
+func (s *httpSender) doResponse(rq *http.Request) ([]byte, http.Header, error) {
+	body, header, err := s.send(rq)
+	for attempt := 0; attempt < s.retries && isRetryable(err); attempt++ {
+		tracef("http: retrying request (attempt %d of %d): %s", attempt+1, s.retries, err)
+		time.Sleep(s.backoff << attempt)
+
+		retry := rq.Clone(rq.Context())
+		if rq.GetBody != nil {
+			if retry.Body, err = rq.GetBody(); err != nil {
+				return nil, nil, err
+			}
+		}
+		body, header, err = s.send(retry)
+	}
+	return body, header, err
+}
+
+// send sends a request once, returning the body and headers of the
+// response.
+func (s *httpSender) send(rq *http.Request) ([]byte, http.Header, error) {
+	rw, err := s.client.Do(rq)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rw.Body.Close()
+
+	body, err := io.ReadAll(rw.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rw.StatusCode < 200 || rw.StatusCode > 299 {
+		return body, rw.Header, httpStatusError{rw.StatusCode}
+	}
+	return body, rw.Header, nil
+}
+
+// httpStatusError is the error returned by httpSender.do when a response
+// has a status other than 2xx.  The error is ErrUnexpectedResponse.
+type httpStatusError struct {
+	status int
+}
+
+// Error implements the error interface.
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", ErrUnexpectedResponse, e.status, http.StatusText(e.status))
+}
+
+// Is returns true if the target is ErrUnexpectedResponse.
+func (e httpStatusError) Is(target error) bool {
+	return target == ErrUnexpectedResponse
+}
+
+// retryable returns true if the request may succeed if sent again,
+// i.e. the status is 408 (Request Timeout), 429 (Too Many Requests) or
+// any 5xx status.
+func (e httpStatusError) retryable() bool {
+	return e.status == http.StatusRequestTimeout ||
+		e.status == http.StatusTooManyRequests ||
+		e.status >= 500
+}
+
+// isRetryable returns true if an error returned by httpSender.do
+// indicates that the request may succeed if sent again.  This is the
+// case for any error other than a response with a non-retryable status.
+func isRetryable(err error) bool {
+	if err, ok := err.(httpStatusError); ok {
+		return err.retryable()
+	}
+	return err != nil
+}
@@ -0,0 +1,234 @@
+package ulog
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestHTTPSender(t *testing.T) {
+	// ARRANGE
+	var sut httpSender
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "newHTTPSender",
+			exec: func(t *testing.T) {
+				// ASSERT
+				test.That(t, sut.client.Timeout).Equals(5 * time.Second)
+				test.That(t, len(sut.headers)).Equals(0)
+			},
+		},
+		{scenario: "setHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				sut.setHeaders(map[string]string{"X-Custom": "value"})
+				sut.setHeaders(map[string]string{"x-custom": "replaced"})
+
+				// ASSERT
+				test.That(t, sut.headers.Values("X-Custom")).Equals([]string{"replaced"})
+			},
+		},
+		{scenario: "setTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				sut.setTimeout(time.Minute)
+
+				// ASSERT
+				test.That(t, sut.timeout).Equals(time.Minute)
+				test.That(t, sut.client.Timeout).Equals(5 * time.Second)
+			},
+		},
+		{scenario: "applyTimeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{Timeout: time.Second}
+				sut.setTimeout(time.Minute)
+				sut.client = client
+
+				// ACT
+				sut.applyTimeout()
+
+				// ASSERT
+				test.That(t, sut.client.Timeout).Equals(time.Minute)
+				test.That(t, client.Timeout, "supplied client").Equals(time.Second)
+			},
+		},
+		{scenario: "applyTimeout/not configured",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{Timeout: time.Second}
+				sut.client = client
+
+				// ACT
+				sut.applyTimeout()
+
+				// ASSERT
+				test.IsTrue(t, sut.client == client)
+			},
+		},
+		{scenario: "newRequest",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.setHeaders(map[string]string{"X-Custom": "value"})
+
+				// ACT
+				rq, err := sut.newRequest(http.MethodPut, "http://localhost", []byte("body"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, rq.Method).Equals(http.MethodPut)
+				test.That(t, rq.Header.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "newRequest/invalid url",
+			exec: func(t *testing.T) {
+				// ACT
+				_, err := sut.newRequest(http.MethodPost, "\n", nil)
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "do/ok",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte("response"))
+				}))
+				defer srv.Close()
+				rq, _ := sut.newRequest(http.MethodPost, srv.URL, nil)
+
+				// ACT
+				body, err := sut.do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(body)).Equals("response")
+			},
+		},
+		{scenario: "do/unexpected status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte("bad request"))
+				}))
+				defer srv.Close()
+				rq, _ := sut.newRequest(http.MethodPost, srv.URL, nil)
+
+				// ACT
+				body, err := sut.do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, err.Error()).Equals("unexpected response: 400 Bad Request")
+				test.That(t, string(body)).Equals("bad request")
+				test.IsFalse(t, isRetryable(err))
+			},
+		},
+		{scenario: "do/with retries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				bodies := []string{}
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					bodies = append(bodies, string(body))
+					if len(bodies) < 3 {
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+				}))
+				defer srv.Close()
+				sut.setRetry(3, time.Millisecond)
+				rq, _ := sut.newRequest(http.MethodPost, srv.URL, []byte("body"))
+
+				// ACT
+				_, err := sut.do(rq)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, bodies).Equals([]string{"body", "body", "body"})
+			},
+		},
+		{scenario: "do/with retries/not retryable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls := 0
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					w.WriteHeader(http.StatusUnauthorized)
+				}))
+				defer srv.Close()
+				sut.setRetry(3, time.Millisecond)
+				rq, _ := sut.newRequest(http.MethodPost, srv.URL, []byte("body"))
+
+				// ACT
+				_, err := sut.do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, calls).Equals(1)
+			},
+		},
+		{scenario: "do/with retries/retries exhausted",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls := 0
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					w.WriteHeader(http.StatusTooManyRequests)
+				}))
+				defer srv.Close()
+				sut.setRetry(2, time.Millisecond)
+				rq, _ := sut.newRequest(http.MethodPost, srv.URL, []byte("body"))
+
+				// ACT
+				_, err := sut.do(rq)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, calls).Equals(3)
+			},
+		},
+		{scenario: "do/request error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rq, _ := sut.newRequest(http.MethodPost, "unknown://localhost", nil)
+
+				// ACT
+				_, err := sut.do(rq)
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+				test.IsTrue(t, isRetryable(err))
+			},
+		},
+		{scenario: "isRetryable",
+			exec: func(t *testing.T) {
+				// ASSERT
+				test.IsFalse(t, isRetryable(nil), "nil")
+				test.IsTrue(t, isRetryable(errors.New("error")), "any error")
+				test.IsTrue(t, isRetryable(httpStatusError{http.StatusRequestTimeout}), "408")
+				test.IsTrue(t, isRetryable(httpStatusError{http.StatusTooManyRequests}), "429")
+				test.IsTrue(t, isRetryable(httpStatusError{http.StatusServiceUnavailable}), "503")
+				test.IsFalse(t, isRetryable(httpStatusError{http.StatusUnauthorized}), "401")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newHTTPSender()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type HTTPOption = func(*httpTransport) error // HTTPOption is a function that configures an http transport
+
+// HTTPTransport returns a transport factory function to create and
+// configure a transport that sends batches of formatted log entries
+// to an http endpoint, with specified configuration options applied.
+//
+// An endpoint must be configured using the HTTPEndpoint option.
+//
+// Formatted entries are sent as-is, combined in the body of each
+// request according to the configured HTTPBatchEncoding (NDJSON by
+// default).  The Formatter of the target must produce entries that
+// are compatible with the encoding; e.g. a JSONFormatter for the
+// NDJSON or JSON array encodings or a MsgpackFormatter for the msgpack
+// array encoding.
+func HTTPTransport(opts ...HTTPOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newHTTPBatchHandler()
+
+		t := &httpTransport{batchTransport{
+			name:  "http",
+			ch:    make(chan []byte, 100),
+			batch: &Batch{maxLatency: 10 * time.Second},
+		}}
+		t.batch.init(bh, 16)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if bh.endpoint == "" {
+			return nil, fmt.Errorf("%w: %w: no endpoint configured", ErrHTTPConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// httpTransport implements a transport that sends batches of log
+// entries to an http endpoint.
+type httpTransport struct {
+	batchTransport
+}
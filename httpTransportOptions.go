@@ -0,0 +1,123 @@
+package ulog
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPBasicAuth configures the transport to authenticate requests using
+// basic authentication with a specified username and password.
+//
+// The password may be specified as the name of an environment variable or
+// file from which to read the password (see: LogtailSourceToken).
+func HTTPBasicAuth(username, password string) HTTPOption {
+	return func(t *httpTransport) error {
+		creds := username + ":" + readSecret(password)
+		return t.batch.configure(httpAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(creds)))
+	}
+}
+
+// HTTPBatching applies batch options to configure the batching of log
+// entries sent by the transport.
+func HTTPBatching(opts ...BatchOption) HTTPOption {
+	return func(t *httpTransport) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// HTTPBearerToken configures the transport to authenticate requests using
+// a bearer token.
+//
+// The token may be specified as the name of an environment variable or file
+// from which to read the token (see: LogtailSourceToken).
+func HTTPBearerToken(s string) HTTPOption {
+	return func(t *httpTransport) error {
+		return t.batch.configure(httpAuthorization, "Bearer "+readSecret(s))
+	}
+}
+
+// HTTPClient configures the http.Client used to send requests.  This may be
+// used to configure TLS (e.g. private CA roots or client certificates),
+// proxy settings or a custom RoundTripper.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func HTTPClient(c *http.Client) HTTPOption {
+	return func(t *httpTransport) error {
+		if c == nil {
+			return fmt.Errorf("%w: HTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(httpHTTPClient, c)
+	}
+}
+
+// HTTPEncoding configures the encoding of the body of each request.  The
+// default is NDJSONEncoding.
+func HTTPEncoding(enc HTTPBatchEncoding) HTTPOption {
+	return func(t *httpTransport) error {
+		switch enc {
+		case NDJSONEncoding, JSONArrayEncoding, MsgpackArrayEncoding:
+			return t.batch.configure(httpEncoding, enc)
+		default:
+			return fmt.Errorf("%w: HTTPEncoding: invalid encoding (%d)", ErrInvalidConfiguration, enc)
+		}
+	}
+}
+
+// HTTPEndpoint configures the url to which requests are sent.  The url
+// must be an absolute url with an http or https scheme.
+func HTTPEndpoint(s string) HTTPOption {
+	return func(t *httpTransport) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: HTTPEndpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: HTTPEndpoint: %q: scheme must be http or https", ErrInvalidConfiguration, s)
+		}
+		return t.batch.configure(httpEndpoint, s)
+	}
+}
+
+// HTTPHeaders configures additional headers to be sent with each request.
+// This option may be specified multiple times; headers are accumulated,
+// with any header specified more than once taking the most recently
+// configured value.
+//
+// The Content-Type header is set by the transport according to the
+// configured encoding and cannot be overridden.  The Authorization header
+// is similarly replaced if configured using HTTPBasicAuth or
+// HTTPBearerToken.
+func HTTPHeaders(h map[string]string) HTTPOption {
+	return func(t *httpTransport) error {
+		return t.batch.configure(httpHeaders, h)
+	}
+}
+
+// HTTPMethod configures the http method used for each request.  The
+// default is POST.
+func HTTPMethod(m string) HTTPOption {
+	return func(t *httpTransport) error {
+		switch m {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			return t.batch.configure(httpMethod, m)
+		default:
+			return fmt.Errorf("%w: HTTPMethod: %q: must be POST, PUT or PATCH", ErrInvalidConfiguration, m)
+		}
+	}
+}
+
+// HTTPTimeout configures the timeout for requests.  The default is 5 seconds.
+func HTTPTimeout(d time.Duration) HTTPOption {
+	return func(t *httpTransport) error {
+		return t.batch.configure(httpTimeout, d)
+	}
+}
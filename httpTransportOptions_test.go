@@ -0,0 +1,186 @@
+package ulog
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestHTTPTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		bh *httpBatchHandler
+		ht = &httpTransport{batchTransport{batch: &Batch{}}}
+	)
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "HTTPBasicAuth",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				os.Setenv("TEST_PASSWORD", "password")
+				defer os.Unsetenv("TEST_PASSWORD")
+
+				// ACT
+				err := HTTPBasicAuth("user", "TEST_PASSWORD")(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("Basic dXNlcjpwYXNzd29yZA==")
+			},
+		},
+		{scenario: "HTTPBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPBatching(BatchMaxEntries(42), BatchMaxBytes(1024))(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, ht.batch.max).Equals(42)
+				test.That(t, ht.batch.maxBytes).Equals(1024)
+				test.That(t, ht.batch.handler).Equals(BatchHandler(bh))
+			},
+		},
+		{scenario: "HTTPBatching/option error",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPBatching(BatchMaxEntries(0))(ht)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPBearerToken",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPBearerToken("testdata/token.txt")(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("Bearer file_token")
+			},
+		},
+		{scenario: "HTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := HTTPClient(client)(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "HTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPClient(nil)(ht)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPEncoding",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPEncoding(MsgpackArrayEncoding)(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.encoding).Equals(MsgpackArrayEncoding)
+			},
+		},
+		{scenario: "HTTPEncoding/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPEncoding(HTTPBatchEncoding(-1))(ht)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPEndpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPEndpoint("https://collector.example.com/logs")(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("https://collector.example.com/logs")
+			},
+		},
+		{scenario: "HTTPEndpoint/invalid url",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPEndpoint("\n")(ht)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPEndpoint/invalid scheme",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPEndpoint("ftp://collector.example.com")(ht)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPHeaders(map[string]string{"X-Custom": "value"})(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "HTTPMethod",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPMethod(http.MethodPut)(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.method).Equals(http.MethodPut)
+			},
+		},
+		{scenario: "HTTPMethod/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPMethod(http.MethodGet)(ht)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := HTTPTimeout(time.Minute)(ht)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newHTTPBatchHandler()
+			ht.batch.init(bh, 16)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ulog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "HTTPTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*httpTransport) error { return opterr }
+
+				// ACT
+				result, err := HTTPTransport(HTTPEndpoint("http://localhost"), opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "HTTPTransport/no endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := HTTPTransport()()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrHTTPConfiguration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "HTTPTransport/with endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := HTTPTransport(HTTPEndpoint("http://localhost"))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*httpTransport](t, result); ok {
+					batch := result.batch
+					test.That(t, batch.max, "batch capacity").Equals(16)
+					test.That(t, batch.maxLatency, "max latency").Equals(10 * time.Second)
+
+					if handler, ok := test.IsType[*httpBatchHandler](t, batch.handler); ok {
+						test.That(t, handler.endpoint, "endpoint").Equals("http://localhost")
+						test.That(t, handler.method, "method").Equals(http.MethodPost)
+						test.That(t, handler.encoding, "encoding").Equals(NDJSONEncoding)
+					}
+				}
+			},
+		},
+		{scenario: "sends entries via mux target",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				mx := &sync.Mutex{}
+				bodies := []string{}
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					mx.Lock()
+					defer mx.Unlock()
+					bodies = append(bodies, string(body))
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(&mockformatter{}),
+							TargetTransport(HTTPTransport(
+								HTTPEndpoint(srv.URL),
+								HTTPBatching(BatchMaxEntries(2)),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("entry 1")
+				logger.Info("entry 2")
+				logger.Info("entry 3")
+				closelog()
+
+				// ASSERT
+				test.Slice(t, bodies).Equals([]string{"entry 1\nentry 2\n", "entry 3\n"})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -106,3 +106,19 @@ type dispatcher interface {
 type transport interface {
 	log([]byte)
 }
+
+// entryTransport is an interface implemented by a log transport that
+// requires the log entry as well as (or instead of) the pre-formatted
+// log message, e.g. to derive metadata from the level, time or fields
+// of the entry.
+//
+// The transport is provided with the entry and a function to format an
+// entry using the Formatter of the target.  The transport may format
+// the entry as received or a modified copy (e.g. with fields removed
+// that are sent separately as metadata).
+//
+// As for log([]byte), the formatted bytes are owned by the target; an
+// asynchronous transport must copy them before returning.
+type entryTransport interface {
+	logEntry(entry, func(entry) []byte)
+}
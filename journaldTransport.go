@@ -0,0 +1,230 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type JournaldOption = func(*journald) error // JournaldOption is a function that configures a journald transport
+
+// JournaldTransport returns a transport factory function to create and
+// configure a transport that sends log entries to the systemd journal
+// using the native journal protocol, with specified configuration options
+// applied.
+//
+// Each entry is sent as a set of journal fields:
+//
+//   - MESSAGE is the message of the entry
+//   - PRIORITY is the syslog severity corresponding to the Level of the entry
+//   - SYSLOG_IDENTIFIER identifies the application (see: JournaldIdentifier)
+//   - CODE_FILE, CODE_LINE and CODE_FUNC identify the callsite (if enabled)
+//
+// Each field of the entry is sent as an additional journal field with the
+// name of the field converted to upper case and any characters other than
+// A-Z, 0-9 and '_' replaced by '_'.  Fields that would replace any of the
+// fields above are ignored.
+//
+// The target Formatter is not used.
+//
+// Entries that are too large to be sent in a single datagram are written
+// to a sealed memfd (or, if unavailable, an unlinked temporary file) which
+// is then passed to the journal.
+//
+// The transport is supported only on linux; on other platforms the
+// factory returns ErrNotImplemented.
+func JournaldTransport(opts ...JournaldOption) TransportFactory {
+	return func() (transport, error) {
+		if !journaldSupported {
+			return nil, fmt.Errorf("%w: journald transport is only supported on linux", ErrNotImplemented)
+		}
+
+		t := &journald{
+			ch:         make(chan []byte, 100),
+			socket:     "/run/systemd/journal/socket",
+			identifier: filepath.Base(os.Args[0]),
+			conn: &netconn{
+				name:       "journald",
+				minBackoff: 100 * time.Millisecond,
+				maxBackoff: 30 * time.Second,
+			},
+		}
+		t.conn.dial = t.dial
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+}
+
+// journald implements a transport that sends log entries to the systemd
+// journal.
+type journald struct {
+	ch         chan []byte // channel over which encoded entries are received
+	conn       *netconn    // the connection to the journal socket
+	socket     string      // the path of the journal socket
+	identifier string      // the SYSLOG_IDENTIFIER of entries
+}
+
+// journaldReserved are the names of the journal fields set by the transport,
+// which may not be replaced by fields of an entry.
+var journaldReserved = []string{"MESSAGE", "PRIORITY", "SYSLOG_IDENTIFIER", "CODE_FILE", "CODE_LINE", "CODE_FUNC"}
+
+// journaldFieldName returns a valid journal field name for a specified
+// name.  The name is converted to upper case with any characters other
+// than A-Z, 0-9 and '_' replaced by '_'.  Any leading underscores are
+// removed (fields with a leading underscore are reserved for trusted
+// fields added by the journal) and a name that would then start with a
+// digit is prefixed by "F".  The name is truncated to 64 characters.
+//
+// If the resulting name is empty, "" is returned.
+func journaldFieldName(s string) string {
+	b := []byte(strings.ToUpper(s))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	name := strings.TrimLeft(string(b), "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "F" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// appendJournaldField appends a field to an entry encoded using the native
+// journal protocol.  A value that does not contain a newline is encoded
+// as NAME=value; any other value is encoded as the name followed by a
+// newline, the length of the value (a 64-bit little-endian integer) and
+// the value:
+//
+//	NAME\n<length><value>\n
+func appendJournaldField(b []byte, name string, value string) []byte {
+	b = append(b, name...)
+	if strings.IndexByte(value, '\n') == -1 {
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// encode returns an entry encoded using the native journal protocol.
+// Fields of the entry are encoded in order of their (journal) name.
+func (t *journald) encode(e entry) []byte {
+	b := make([]byte, 0, 256+len(e.Message))
+	b = appendJournaldField(b, "MESSAGE", e.Message)
+	b = appendJournaldField(b, "PRIORITY", strconv.Itoa(syslogSeverity[e.Level]))
+	if t.identifier != "" {
+		b = appendJournaldField(b, "SYSLOG_IDENTIFIER", t.identifier)
+	}
+	if e.callsite != nil {
+		b = appendJournaldField(b, "CODE_FILE", e.callsite.file)
+		b = appendJournaldField(b, "CODE_LINE", strconv.Itoa(e.callsite.line))
+		b = appendJournaldField(b, "CODE_FUNC", e.callsite.function)
+	}
+
+	if e.logcontext == nil || e.fields == nil {
+		return b
+	}
+
+	type field struct {
+		name  string
+		value any
+	}
+	fields := make([]field, 0, len(e.fields.m))
+	for k, v := range e.fields.m {
+		name := journaldFieldName(k)
+		if name == "" || slices.Contains(journaldReserved, name) {
+			continue
+		}
+		fields = append(fields, field{name, v})
+	}
+	slices.SortFunc(fields, func(a, b field) int { return strings.Compare(a.name, b.name) })
+
+	for _, f := range fields {
+		var s string
+		switch v := f.value.(type) {
+		case string:
+			s = v
+		case error:
+			s = v.Error()
+		default:
+			s = fmt.Sprintf("%v", v)
+		}
+		b = appendJournaldField(b, f.name, s)
+	}
+	return b
+}
+
+// dial establishes a connection to the journal socket.
+func (t *journald) dial() (net.Conn, error) {
+	return net.Dial("unixgram", t.socket)
+}
+
+// log implements the transport interface.  It is not used; entries are
+// received by logEntry.
+func (t *journald) log([]byte) {}
+
+// logEntry implements the entryTransport interface.  The entry is encoded
+// and sent to the transport channel.
+func (t *journald) logEntry(e entry, _ func(entry) []byte) {
+	t.ch <- t.encode(e)
+}
+
+// write sends an encoded entry to the journal.  If the entry is too large
+// to be sent as a datagram it is sent using a file descriptor.  If the
+// write fails for any other reason, the connection is closed.
+func (t *journald) write(b []byte) error {
+	if err := t.conn.connect(); err != nil {
+		return err
+	}
+
+	_, err := t.conn.conn.Write(b)
+	switch {
+	case err == nil:
+		return nil
+	case journaldTooLarge(err):
+		return t.writeFd(b)
+	default:
+		t.conn.disconnect()
+		return err
+	}
+}
+
+// stop closes the channel over which entries are received.
+func (t *journald) stop() {
+	tracef("journald: transport requested to stop...")
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  The run loop
+// terminates when the channel over which entries are received is
+// closed, closing any established connection.
+func (t *journald) run() {
+	for b := range t.ch {
+		if err := t.write(b); err != nil {
+			trace("journald: entry discarded: " + err.Error())
+		}
+	}
+	t.conn.disconnect()
+	tracef("journald: transport stopped")
+}
@@ -0,0 +1,25 @@
+package ulog
+
+import "fmt"
+
+// JournaldIdentifier configures the SYSLOG_IDENTIFIER of entries.  The
+// default is the base name of the executable.  An empty identifier omits
+// the field.
+func JournaldIdentifier(s string) JournaldOption {
+	return func(t *journald) error {
+		t.identifier = s
+		return nil
+	}
+}
+
+// JournaldSocket configures the path of the journal socket.  The default
+// is /run/systemd/journal/socket.
+func JournaldSocket(path string) JournaldOption {
+	return func(t *journald) error {
+		if path == "" {
+			return fmt.Errorf("%w: JournaldSocket: path is required", ErrInvalidConfiguration)
+		}
+		t.socket = path
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestJournaldTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *journald
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "JournaldIdentifier",
+			exec: func(t *testing.T) {
+				// ACT
+				err := JournaldIdentifier("app")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.identifier).Equals("app")
+			},
+		},
+		{scenario: "JournaldSocket",
+			exec: func(t *testing.T) {
+				// ACT
+				err := JournaldSocket("/tmp/journal.sock")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.socket).Equals("/tmp/journal.sock")
+			},
+		},
+		{scenario: "JournaldSocket/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := JournaldSocket("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &journald{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package ulog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestJournaldTransport(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// JournaldTransport tests
+		{scenario: "JournaldTransport",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := JournaldTransport()()
+
+				// ASSERT
+				if !journaldSupported {
+					test.Error(t, err).Is(ErrNotImplemented)
+					return
+				}
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*journald](t, result); ok {
+					test.That(t, result.socket).Equals("/run/systemd/journal/socket")
+				}
+			},
+		},
+		{scenario: "JournaldTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				if !journaldSupported {
+					t.Skip("journald is not supported on this platform")
+				}
+				opterr := errors.New("option error")
+				opt := func(*journald) error { return opterr }
+
+				// ACT
+				result, err := JournaldTransport(opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+
+		// journaldFieldName tests
+		{scenario: "journaldFieldName",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name   string
+					result string
+				}{
+					{name: "key", result: "KEY"},
+					{name: "request.id", result: "REQUEST_ID"},
+					{name: "_private", result: "PRIVATE"},
+					{name: "1st", result: "F1ST"},
+					{name: "___", result: ""},
+					{name: "café", result: "CAF__"},
+					{name: strings.Repeat("x", 65), result: strings.Repeat("X", 64)},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := journaldFieldName(tc.name)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// appendJournaldField tests
+		{scenario: "appendJournaldField",
+			exec: func(t *testing.T) {
+				// ACT
+				result := appendJournaldField(nil, "KEY", "value")
+
+				// ASSERT
+				test.That(t, string(result)).Equals("KEY=value\n")
+			},
+		},
+		{scenario: "appendJournaldField/multi-line value",
+			exec: func(t *testing.T) {
+				// ACT
+				result := appendJournaldField(nil, "KEY", "a\nb")
+
+				// ASSERT
+				test.That(t, string(result)).Equals("KEY\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n")
+			},
+		},
+
+		// encode tests
+		{scenario: "encode",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &journald{identifier: "app"}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(4).merge(map[string]any{
+						"request.id": "abc",
+						"count":      42,
+						"err":        errors.New("failed"),
+						"priority":   "ignored",
+					})},
+					callsite: &callsite{function: "main.main", file: "main.go", line: 10},
+					Level:    ErrorLevel,
+					Message:  "message",
+				}
+
+				// ACT
+				result := sut.encode(e)
+
+				// ASSERT
+				test.That(t, string(result)).Equals(
+					"MESSAGE=message\n" +
+						"PRIORITY=3\n" +
+						"SYSLOG_IDENTIFIER=app\n" +
+						"CODE_FILE=main.go\n" +
+						"CODE_LINE=10\n" +
+						"CODE_FUNC=main.main\n" +
+						"COUNT=42\n" +
+						"ERR=failed\n" +
+						"REQUEST_ID=abc\n")
+			},
+		},
+		{scenario: "encode/no identifier or fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &journald{}
+				e := entry{Level: InfoLevel, Message: "message"}
+
+				// ACT
+				result := sut.encode(e)
+
+				// ASSERT
+				test.That(t, string(result)).Equals("MESSAGE=message\nPRIORITY=6\n")
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &journald{ch: make(chan []byte, 1)}
+				e := entry{Level: WarnLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, nil)
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("MESSAGE=message\nPRIORITY=4\n")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package ulog
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// journaldSupported is true on platforms that support the journald transport.
+const journaldSupported = true
+
+// memfdCreate is the memfd_create syscall number on each supported
+// architecture.
+var memfdCreate = map[string]uintptr{
+	"386":     356,
+	"amd64":   319,
+	"arm":     385,
+	"arm64":   279,
+	"loong64": 279,
+	"ppc64":   360,
+	"ppc64le": 360,
+	"riscv64": 279,
+	"s390x":   350,
+}
+
+const (
+	mfdCloexec       = 0x1
+	mfdAllowSealing  = 0x2
+	fAddSeals        = 1033
+	fSealSeal        = 0x1
+	fSealShrink      = 0x2
+	fSealGrow        = 0x4
+	fSealWrite       = 0x8
+	journaldSealMask = fSealSeal | fSealShrink | fSealGrow | fSealWrite
+)
+
+// journaldTooLarge returns true if an error indicates that an entry is too
+// large to be sent as a datagram.
+func journaldTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// journaldMemfd returns a sealed memfd containing specified data.
+func journaldMemfd(b []byte) (*os.File, error) {
+	trap, ok := memfdCreate[runtime.GOARCH]
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	name, _ := syscall.BytePtrFromString("ulog-journald")
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	f := os.NewFile(fd, "memfd:ulog-journald")
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, journaldSealMask); errno != 0 {
+		f.Close()
+		return nil, errno
+	}
+	return f, nil
+}
+
+// journaldTempFile returns an unlinked temporary file in /dev/shm
+// containing specified data.
+func journaldTempFile(b []byte) (*os.File, error) {
+	f, err := os.CreateTemp("/dev/shm", "ulog-journald-")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// writeFd sends an encoded entry to the journal by writing it to a sealed
+// memfd (or, if a memfd cannot be created, an unlinked temporary file) and
+// sending the file descriptor.
+func (t *journald) writeFd(b []byte) error {
+	f, err := journaldMemfd(b)
+	if err != nil {
+		if f, err = journaldTempFile(b); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+
+	// WriteMsgUnix does not support a connected datagram socket so the
+	// message is sent using the underlying socket
+	conn, ok := t.conn.conn.(syscall.Conn)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(f.Fd()))
+	var sendErr error
+	if err := rc.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	}); err != nil {
+		return err
+	}
+	return sendErr
+}
@@ -0,0 +1,152 @@
+package ulog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestJournaldTransport_Linux(t *testing.T) {
+	// ARRANGE
+
+	// listen returns a unixgram listener on a socket in a temporary
+	// directory, returning the listener and the path of the socket
+	listen := func(t *testing.T) (*net.UnixConn, string) {
+		dir, err := os.MkdirTemp("", "ulog")
+		test.Error(t, err).IsNil()
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		path := filepath.Join(dir, "socket")
+
+		l, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		test.Error(t, err).IsNil()
+		t.Cleanup(func() { l.Close() })
+		_ = l.SetReadDeadline(time.Now().Add(time.Second))
+		return l, path
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "journaldTempFile",
+			exec: func(t *testing.T) {
+				// ACT
+				f, err := journaldTempFile([]byte("data"))
+				test.Error(t, err).IsNil()
+				defer f.Close()
+
+				// ASSERT
+				b := make([]byte, 8)
+				n, _ := f.ReadAt(b, 0)
+				test.That(t, string(b[:n])).Equals("data")
+				_, err = os.Stat(f.Name())
+				test.Error(t, err).Is(os.ErrNotExist)
+			},
+		},
+		{scenario: "write/not connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &journald{socket: filepath.Join(os.TempDir(), "ulog-missing.socket")}
+				sut.conn = &netconn{name: "journald", dial: sut.dial, maxBackoff: time.Second}
+
+				// ACT
+				err := sut.write([]byte("MESSAGE=message\n"))
+
+				// ASSERT
+				test.Error(t, err).Is(syscall.ENOENT)
+			},
+		},
+		{scenario: "write/write error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				writeErr := errors.New("write error")
+				conn := &mockconn{writefn: func([]byte) (int, error) { return 0, writeErr }}
+				sut := &journald{}
+				sut.conn = &netconn{name: "journald", dial: func() (net.Conn, error) { return conn, nil }}
+
+				// ACT
+				err := sut.write([]byte("MESSAGE=message\n"))
+
+				// ASSERT
+				test.Error(t, err).Is(writeErr)
+				test.IsTrue(t, conn.closeWasCalled, "connection closed")
+				test.That(t, sut.conn.conn).IsNil()
+			},
+		},
+		{scenario: "sends to journal",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, path := listen(t)
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetTransport(JournaldTransport(
+								JournaldIdentifier("app"),
+								JournaldSocket(path),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.WithField("key", "value").Info("message")
+				closelog()
+
+				// ASSERT
+				b := make([]byte, 1024)
+				n, err := l.Read(b)
+				test.Error(t, err).IsNil()
+				test.That(t, string(b[:n])).Equals("MESSAGE=message\nPRIORITY=6\nSYSLOG_IDENTIFIER=app\nKEY=value\n")
+			},
+		},
+		{scenario: "sends oversized entry using file descriptor",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, path := listen(t)
+				sut := &journald{socket: path}
+				sut.conn = &netconn{name: "journald", dial: sut.dial}
+				defer sut.conn.disconnect()
+				entry := appendJournaldField(nil, "MESSAGE", strings.Repeat("x", 4*1024*1024))
+
+				// ACT
+				err := sut.write(entry)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+
+				oob := make([]byte, syscall.CmsgSpace(4))
+				_, oobn, _, _, err := l.ReadMsgUnix(nil, oob)
+				test.Error(t, err).IsNil()
+				msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+				test.Error(t, err).IsNil()
+				test.That(t, len(msgs), "control messages").Equals(1)
+				fds, err := syscall.ParseUnixRights(&msgs[0])
+				test.Error(t, err).IsNil()
+				test.That(t, len(fds), "file descriptors").Equals(1)
+
+				f := os.NewFile(uintptr(fds[0]), "received")
+				defer f.Close()
+				b, err := io.ReadAll(io.NewSectionReader(f, 0, int64(len(entry))+1))
+				test.Error(t, err).IsNil()
+				test.That(t, len(b), "received bytes").Equals(len(entry))
+				test.IsTrue(t, string(b) == string(entry), "received entry")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
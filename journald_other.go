@@ -0,0 +1,15 @@
+//go:build !linux
+
+package ulog
+
+import "errors"
+
+// journaldSupported is true on platforms that support the journald transport.
+const journaldSupported = false
+
+// journaldTooLarge returns true if an error indicates that an entry is too
+// large to be sent as a datagram.
+func journaldTooLarge(error) bool { return false }
+
+// writeFd is not supported on this platform.
+func (t *journald) writeFd([]byte) error { return errors.ErrUnsupported }
@@ -41,18 +41,49 @@ func JSONFormatter(opt ...JSONFormatterOption) FormatterFactory {
 	}
 }
 
+// JSONTimeFormatType identifies the format of the time field in json
+// formatted log entries.
+type JSONTimeFormatType int
+
+const (
+	JSONTimeRFC3339       JSONTimeFormatType = iota // JSONTimeRFC3339 writes the time as an RFC 3339 string (with nanoseconds)
+	JSONTimeEpochMillis                             // JSONTimeEpochMillis writes the time as a number of milliseconds since the epoch
+	JSONTimeRFC3339Millis                           // JSONTimeRFC3339Millis writes the time in UTC as an RFC 3339 string with milliseconds (e.g. 2006-01-02T15:04:05.000Z)
+)
+
+// jsonTimeRFC3339Millis is the layout used for JSONTimeRFC3339Millis.
+const jsonTimeRFC3339Millis = "2006-01-02T15:04:05.000Z07:00"
+
 type jsonfmt struct {
-	keys   [numFields]string
-	levels [numLevels]string
+	keys         [numFields]string
+	levels       [numLevels]string
+	levelNumbers *[numLevels]int    // numeric values for the level field; nil if level labels are used
+	timeFormat   JSONTimeFormatType // the format of the time field
+	static       map[string]any     // fields added to all entries
 }
 
 // Format implements a Formatter that writes log entries as JSON.
 func (w *jsonfmt) Format(id int, e entry, b ByteWriter) {
-	entry := map[string]any{
-		w.keys[TimeField]:    e.Time,
-		w.keys[LevelField]:   w.levels[e.Level],
-		w.keys[MessageField]: e.Message,
+	entry := make(map[string]any, len(w.static)+3)
+	for k, v := range w.static {
+		entry[k] = v
+	}
+
+	switch w.timeFormat {
+	case JSONTimeEpochMillis:
+		entry[w.keys[TimeField]] = e.Time.UnixMilli()
+	case JSONTimeRFC3339Millis:
+		entry[w.keys[TimeField]] = e.Time.UTC().Format(jsonTimeRFC3339Millis)
+	default:
+		entry[w.keys[TimeField]] = e.Time
+	}
+
+	if w.levelNumbers != nil {
+		entry[w.keys[LevelField]] = w.levelNumbers[e.Level]
+	} else {
+		entry[w.keys[LevelField]] = w.levels[e.Level]
 	}
+	entry[w.keys[MessageField]] = e.Message
 
 	if e.fields != nil {
 		for k, v := range e.fields.m {
@@ -63,9 +94,41 @@ func (w *jsonfmt) Format(id int, e entry, b ByteWriter) {
 		}
 	}
 
-	// json encoder appends a trailing \n which we do not want
+	writeJSONObject(b, entry, "JSON")
+}
+
+// writeJSONObject writes the JSON encoding of an object, without a trailing
+// newline.  If the object cannot be encoded (e.g. a value is NaN or a
+// channel) any values that cannot be encoded are replaced by a string
+// describing the error, prefixed by a specified identifier (e.g. "GCP"),
+// and the object is encoded again.
+func writeJSONObject(b ByteWriter, obj map[string]any, prefix string) {
 	jb := bytes.NewBuffer(nil)
 	enc := json.NewEncoder(jb)
-	_ = enc.Encode(entry)
-	_, _ = b.Write(jb.Bytes()[0 : jb.Len()-1])
+	if err := enc.Encode(obj); err != nil {
+		jb.Reset()
+		_ = enc.Encode(jsonReplaceInvalid(obj, prefix))
+	}
+
+	// json encoder appends a trailing \n which we do not want
+	_, _ = b.Write(bytes.TrimSuffix(jb.Bytes(), []byte("\n")))
+}
+
+// jsonReplaceInvalid returns a copy of an object with any values that
+// cannot be encoded as JSON replaced by a string describing the error.
+// Nested objects are copied recursively.
+func jsonReplaceInvalid(obj map[string]any, prefix string) map[string]any {
+	result := make(map[string]any, len(obj))
+	for k, v := range obj {
+		if m, ok := v.(map[string]any); ok {
+			result[k] = jsonReplaceInvalid(m, prefix)
+			continue
+		}
+		if _, err := json.Marshal(v); err != nil {
+			result[k] = prefix + "_ERROR: marshalling error: " + err.Error()
+			continue
+		}
+		result[k] = v
+	}
+	return result
 }
@@ -1,5 +1,59 @@
 package ulog
 
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// pinoLevels are the numeric levels used by pino and bunyan.
+var pinoLevels = map[Level]int{
+	TraceLevel: 10,
+	DebugLevel: 20,
+	InfoLevel:  30,
+	WarnLevel:  40,
+	ErrorLevel: 50,
+	FatalLevel: 60,
+}
+
+// jsonOptions returns an option that applies a specified set of options.
+func jsonOptions(opts ...JSONFormatterOption) JSONFormatterOption {
+	return func(jf *jsonfmt) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(jf))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// JSONBunyan configures a json formatter to write entries using the
+// schema of bunyan (node-bunyan), with a specified name identifying the
+// application:
+//
+//	{"v":0,"level":30,"name":"app","hostname":"host","pid":123,"time":"2006-01-02T15:04:05.000Z","msg":"message"}
+//
+// Levels are written as numbers (see: JSONLevelNumbers), the time in UTC
+// as an RFC 3339 string with milliseconds (see: JSONTimeRFC3339Millis) and
+// the message as msg.  The hostname is the host name
+// reported by the kernel.
+//
+// Options applied after JSONBunyan may be used to modify the schema.
+func JSONBunyan(name string) JSONFormatterOption {
+	return func(jf *jsonfmt) error {
+		if name == "" {
+			return fmt.Errorf("%w: JSONBunyan: name is required", ErrInvalidConfiguration)
+		}
+		hostname, _ := os.Hostname()
+		return jsonOptions(
+			JSONFieldNames(map[FieldId]string{TimeField: "time", LevelField: "level", MessageField: "msg"}),
+			JSONLevelNumbers(pinoLevels),
+			JSONTimeFormat(JSONTimeRFC3339Millis),
+			JSONStaticFields(map[string]any{"v": 0, "name": name, "hostname": hostname, "pid": os.Getpid()}),
+		)(jf)
+	}
+}
+
 // LogfmtLabels configures the labels used for the each of the core
 // fields in a logfmt log: time, level, message, file and function.
 //
@@ -29,6 +83,9 @@ func JSONFieldNames(keys map[FieldId]string) JSONFormatterOption {
 
 // JSONLevelLabels configures the values used for the Level field
 // in json formatted log entries.
+//
+// Level labels are not used if numeric levels are configured (see:
+// JSONLevelNumbers).
 func JSONLevelLabels(levels map[Level]string) JSONFormatterOption {
 	return func(jf *jsonfmt) error {
 		for k, v := range levels {
@@ -37,3 +94,88 @@ func JSONLevelLabels(levels map[Level]string) JSONFormatterOption {
 		return nil
 	}
 }
+
+// JSONLevelNumbers configures numeric values for the Level field in json
+// formatted log entries, replacing the level labels.
+//
+// A map[Level]int is used to override the default value for each level
+// that is required; if a level is not included in the map the default
+// value continues to be used for that level.  The default values are
+// those used by pino and bunyan:
+//
+//	TraceLevel: 10
+//	DebugLevel: 20
+//	InfoLevel:  30
+//	WarnLevel:  40
+//	ErrorLevel: 50
+//	FatalLevel: 60
+func JSONLevelNumbers(levels map[Level]int) JSONFormatterOption {
+	return func(jf *jsonfmt) error {
+		if jf.levelNumbers == nil {
+			jf.levelNumbers = &[numLevels]int{}
+			for k, v := range pinoLevels {
+				jf.levelNumbers[k] = v
+			}
+		}
+		for k, v := range levels {
+			if k <= levelNotSet || k >= numLevels {
+				return fmt.Errorf("%w: JSONLevelNumbers: invalid level (%d)", ErrInvalidConfiguration, int(k))
+			}
+			jf.levelNumbers[k] = v
+		}
+		return nil
+	}
+}
+
+// JSONPino configures a json formatter to write entries using the schema
+// of pino:
+//
+//	{"level":30,"time":1136214245000,"pid":123,"hostname":"host","msg":"message","v":1}
+//
+// Levels are written as numbers (see: JSONLevelNumbers), the time as a
+// number of milliseconds since the epoch and the message as msg.  The
+// hostname is the host name reported by the kernel.
+//
+// Options applied after JSONPino may be used to modify the schema.
+func JSONPino() JSONFormatterOption {
+	return func(jf *jsonfmt) error {
+		hostname, _ := os.Hostname()
+		return jsonOptions(
+			JSONFieldNames(map[FieldId]string{TimeField: "time", LevelField: "level", MessageField: "msg"}),
+			JSONLevelNumbers(pinoLevels),
+			JSONTimeFormat(JSONTimeEpochMillis),
+			JSONStaticFields(map[string]any{"v": 1, "hostname": hostname, "pid": os.Getpid()}),
+		)(jf)
+	}
+}
+
+// JSONStaticFields configures fields that are added to all entries.  A
+// field of an entry with the same name as a static field replaces the
+// static field.  This option may be specified multiple times; fields are
+// accumulated, with any field specified more than once taking the most
+// recently configured value.
+func JSONStaticFields(fields map[string]any) JSONFormatterOption {
+	return func(jf *jsonfmt) error {
+		if jf.static == nil {
+			jf.static = make(map[string]any, len(fields))
+		}
+		for k, v := range fields {
+			jf.static[k] = v
+		}
+		return nil
+	}
+}
+
+// JSONTimeFormat configures the format of the time field in json
+// formatted log entries.  The default is JSONTimeRFC3339.
+func JSONTimeFormat(f JSONTimeFormatType) JSONFormatterOption {
+	return func(jf *jsonfmt) error {
+		switch f {
+		case JSONTimeRFC3339, JSONTimeEpochMillis, JSONTimeRFC3339Millis:
+			jf.timeFormat = f
+			return nil
+		default:
+			return fmt.Errorf("%w: JSONTimeFormat: invalid format (%d)", ErrInvalidConfiguration, f)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ulog
 
 import (
+	"os"
 	"testing"
 
 	"github.com/blugnu/test"
@@ -8,10 +9,43 @@ import (
 
 func TestJSONFormatterOptions(t *testing.T) {
 	// ARRANGE
+	hostname, _ := os.Hostname()
+
 	testcases := []struct {
 		scenario string
 		exec     func(t *testing.T)
 	}{
+		// JSONBunyan tests
+		{scenario: "JSONBunyan",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+				sut := f.(*jsonfmt)
+
+				// ACT
+				err := JSONBunyan("app")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.keys[MessageField]).Equals("msg")
+				test.That(t, *sut.levelNumbers).Equals([numLevels]int{0, 60, 50, 40, 30, 20, 10})
+				test.That(t, sut.timeFormat).Equals(JSONTimeRFC3339Millis)
+				test.Map(t, sut.static).Equals(map[string]any{"v": 0, "name": "app", "hostname": hostname, "pid": os.Getpid()})
+			},
+		},
+		{scenario: "JSONBunyan/no name",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+
+				// ACT
+				err := JSONBunyan("")(f.(*jsonfmt))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+
 		// JSONFieldNames tests
 		{scenario: "JSONFieldNames/override one field",
 			exec: func(t *testing.T) {
@@ -106,6 +140,110 @@ func TestJSONFormatterOptions(t *testing.T) {
 				})
 			},
 		},
+
+		// JSONLevelNumbers tests
+		{scenario: "JSONLevelNumbers",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+				sut := f.(*jsonfmt)
+
+				// ACT
+				err1 := JSONLevelNumbers(map[Level]int{TraceLevel: 5})(sut)
+				err2 := JSONLevelNumbers(map[Level]int{FatalLevel: 99})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.That(t, *sut.levelNumbers).Equals([numLevels]int{
+					FatalLevel: 99,
+					ErrorLevel: 50,
+					WarnLevel:  40,
+					InfoLevel:  30,
+					DebugLevel: 20,
+					TraceLevel: 5,
+				})
+			},
+		},
+		{scenario: "JSONLevelNumbers/invalid level",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+
+				// ACT
+				err := JSONLevelNumbers(map[Level]int{Level(99): 1})(f.(*jsonfmt))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+
+		// JSONPino tests
+		{scenario: "JSONPino",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+				sut := f.(*jsonfmt)
+
+				// ACT
+				err := JSONPino()(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.keys[TimeField]).Equals("time")
+				test.That(t, sut.keys[LevelField]).Equals("level")
+				test.That(t, sut.keys[MessageField]).Equals("msg")
+				test.That(t, *sut.levelNumbers).Equals([numLevels]int{0, 60, 50, 40, 30, 20, 10})
+				test.That(t, sut.timeFormat).Equals(JSONTimeEpochMillis)
+				test.Map(t, sut.static).Equals(map[string]any{"v": 1, "hostname": hostname, "pid": os.Getpid()})
+			},
+		},
+
+		// JSONStaticFields tests
+		{scenario: "JSONStaticFields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+				sut := f.(*jsonfmt)
+
+				// ACT
+				err1 := JSONStaticFields(map[string]any{"a": 1})(sut)
+				err2 := JSONStaticFields(map[string]any{"b": "2"})(sut)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				test.Map(t, sut.static).Equals(map[string]any{"a": 1, "b": "2"})
+			},
+		},
+
+		// JSONTimeFormat tests
+		{scenario: "JSONTimeFormat",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+				sut := f.(*jsonfmt)
+
+				// ACT
+				err := JSONTimeFormat(JSONTimeEpochMillis)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.timeFormat).Equals(JSONTimeEpochMillis)
+			},
+		},
+		{scenario: "JSONTimeFormat/invalid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				f, _ := JSONFormatter()()
+
+				// ACT
+				err := JSONTimeFormat(-1)(f.(*jsonfmt))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
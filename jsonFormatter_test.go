@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -256,6 +257,80 @@ func TestJSONFormatter_Format(t *testing.T) {
 				test.Map(t, got).Equals(wanted)
 			},
 		},
+		{scenario: "unsupported field value",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut, _ := JSONFormatter()()
+				e.logcontext = &logcontext{
+					fields: &fields{
+						mutex: mx,
+						m:     map[string]any{"ratio": math.NaN()},
+						b:     map[int][]byte{},
+					},
+				}
+
+				// ACT
+				sut.Format(0, e, dest)
+
+				// ASSERT
+				test.That(t, dest.String()).Equals(`{"level":"info","message":"message","ratio":"JSON_ERROR: marshalling error: json: unsupported value: NaN","time":"2010-09-08T07:06:05.4321Z"}`)
+			},
+		},
+		{scenario: "pino",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut, _ := JSONFormatter(
+					JSONPino(),
+					JSONStaticFields(map[string]any{"hostname": "host", "pid": 123}),
+				)()
+				e.Level = WarnLevel
+				e.logcontext = &logcontext{
+					fields: &fields{
+						mutex: mx,
+						m:     map[string]any{"key": "value", "v": 2},
+						b:     map[int][]byte{},
+					},
+				}
+
+				// ACT
+				sut.Format(0, e, dest)
+
+				// ASSERT
+				test.That(t, dest.String()).Equals(`{"hostname":"host","key":"value","level":40,"msg":"message","pid":123,"time":1283929565432,"v":2}`)
+			},
+		},
+		{scenario: "bunyan",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut, _ := JSONFormatter(
+					JSONBunyan("app"),
+					JSONStaticFields(map[string]any{"hostname": "host", "pid": 123}),
+				)()
+				e.Level = FatalLevel
+
+				// ACT
+				sut.Format(0, e, dest)
+
+				// ASSERT
+				test.That(t, dest.String()).Equals(`{"hostname":"host","level":60,"msg":"message","name":"app","pid":123,"time":"2010-09-08T07:06:05.432Z","v":0}`)
+			},
+		},
+		{scenario: "bunyan/non-UTC time",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut, _ := JSONFormatter(
+					JSONBunyan("app"),
+					JSONStaticFields(map[string]any{"hostname": "host", "pid": 123}),
+				)()
+				e.Time = tm.In(time.FixedZone("UTC+10", 10*60*60))
+
+				// ACT
+				sut.Format(0, e, dest)
+
+				// ASSERT
+				test.That(t, dest.String()).Equals(`{"hostname":"host","level":30,"msg":"message","name":"app","pid":123,"time":"2010-09-08T07:06:05.432Z","v":0}`)
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
@@ -277,3 +352,37 @@ func TestJSONFormatter_Format(t *testing.T) {
 		})
 	}
 }
+
+func Test_writeJSONObject(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		obj      map[string]any
+		result   string
+	}{
+		{scenario: "valid",
+			obj:    map[string]any{"a": 1, "b": "text"},
+			result: `{"a":1,"b":"text"}`,
+		},
+		{scenario: "invalid values",
+			obj: map[string]any{
+				"a":      math.Inf(1),
+				"b":      "text",
+				"nested": map[string]any{"c": make(chan int), "d": 1},
+			},
+			result: `{"a":"X_ERROR: marshalling error: json: unsupported value: +Inf","b":"text","nested":{"c":"X_ERROR: marshalling error: json: unsupported type: chan int","d":1}}`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			buf := &bytes.Buffer{}
+
+			// ACT
+			writeJSONObject(buf, tc.obj, "X")
+
+			// ASSERT
+			test.That(t, buf.String()).Equals(tc.result)
+		})
+	}
+}
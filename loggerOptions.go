@@ -50,6 +50,38 @@ func LoggerFormat(f FormatterFactory) LoggerOption {
 	}
 }
 
+// LoggerFraming sets the framing applied to each entry written by a
+// logger.  This configuration option only makes sense for a non-muxing
+// logger; the default is NewlineFraming.  A binary format (e.g. msgpack)
+// should use NoFraming (if the format is self-delimiting) or a length
+// prefix, since the format may itself contain newline bytes.
+//
+// If configured without/before a backend being configured, a stdio
+// backend will be installed using the default formatter writing to
+// os.Stdout.
+//
+// Returns ErrInvalidConfiguration error if the framing is not valid or
+// if configured on a mux logger.
+func LoggerFraming(f Framing) LoggerOption {
+	return func(l *logger) error {
+		if !f.isValid() {
+			return fmt.Errorf("%w: LoggerFraming: %s", ErrInvalidConfiguration, f)
+		}
+
+		if l.backend == nil {
+			l.backend = newStdioBackend(nil, os.Stdout)
+		}
+
+		switch backend := l.backend.(type) {
+		case interface{ SetFraming(Framing) error }:
+			return backend.SetFraming(f)
+
+		default:
+			return fmt.Errorf("%w: backend (%T) does not support LoggerFraming", ErrInvalidConfiguration, l.backend)
+		}
+	}
+}
+
 // LoggerLevel returns a function that sets the log level of a logger
 func LoggerLevel(level Level) LoggerOption {
 	return func(l *logger) error {
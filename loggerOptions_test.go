@@ -122,6 +122,63 @@ func TestLoggerOptions(t *testing.T) {
 			},
 		},
 
+		// LoggerFraming tests
+		{scenario: "LoggerFraming/no backend",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				lg := &logger{}
+
+				// ACT
+				err := LoggerFraming(NoFraming)(lg)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if be, ok := test.IsType[*stdioBackend](t, lg.backend); ok {
+					test.That(t, be.framing).Equals(NoFraming)
+				}
+			},
+		},
+		{scenario: "LoggerFraming/invalid framing",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				lg := &logger{}
+
+				// ACT
+				err := LoggerFraming(Framing(-1))(lg)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+				test.That(t, lg.backend).IsNil()
+			},
+		},
+		{scenario: "LoggerFraming/backend rejects framing",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				framingerr := errors.New("framing error")
+				lg := &logger{backend: &mockbackend{
+					setframingfn: func(Framing) error { return framingerr },
+				}}
+
+				// ACT
+				err := LoggerFraming(VarintFraming)(lg)
+
+				// ASSERT
+				test.Error(t, err).Is(framingerr)
+			},
+		},
+		{scenario: "LoggerFraming/mux backend",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				lg := &logger{backend: &mux{}}
+
+				// ACT
+				err := LoggerFraming(VarintFraming)(lg)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+
 		// LoggerOutput tests
 		{scenario: "LoggerOutput/no backend",
 			exec: func(t *testing.T) {
@@ -12,11 +12,15 @@ import (
 
 const (
 	logtailEndpoint    = cfgkey("logtail.endpoint")
+	logtailHeaders     = cfgkey("logtail.headers")
+	logtailHTTPClient  = cfgkey("logtail.httpClient")
 	logtailMaxLatency  = cfgkey("logtail.maxLatency")
 	logtailSourceToken = cfgkey("logtail.sourceToken")
+	logtailTimeout     = cfgkey("logtail.timeout")
 )
 
 type logtailBatchHandler struct {
+	httpSender
 	endpoint    string
 	token       string
 	buf         *sync.Pool
@@ -27,7 +31,8 @@ type logtailBatchHandler struct {
 // buffer pool and batch encoding function.
 func (h *logtailBatchHandler) init() {
 	*h = logtailBatchHandler{
-		buf: &sync.Pool{New: func() any { return &bytes.Buffer{} }},
+		httpSender: newHTTPSender(),
+		buf:        &sync.Pool{New: func() any { return &bytes.Buffer{} }},
 		encodeBatch: func(buf *bytes.Buffer, batch *Batch) error {
 			enc, _ := msgpack.NewEncoder(buf)
 			return msgpack.EncodeArray(*enc, batch.entries, func(msg msgpack.Encoder, e []byte) error {
@@ -49,6 +54,12 @@ func (h *logtailBatchHandler) configure(key cfgkey, value any) error {
 	switch key {
 	case logtailEndpoint:
 		h.endpoint = value.(string)
+	case logtailHeaders:
+		h.setHeaders(value.(map[string]string))
+	case logtailHTTPClient:
+		h.client = value.(*http.Client)
+	case logtailTimeout:
+		h.setTimeout(value.(time.Duration))
 	case logtailSourceToken:
 		h.token = value.(string)
 	default:
@@ -57,8 +68,8 @@ func (h *logtailBatchHandler) configure(key cfgkey, value any) error {
 	return nil
 }
 
-// send sends a batch of entries to the BetterStack Logs service.
-func (h *logtailBatchHandler) send(batch *Batch) error {
+// Send sends a batch of entries to the BetterStack Logs service.
+func (h *logtailBatchHandler) Send(batch *Batch) error {
 	tracef("logtail: send: sending %d entries", batch.len)
 
 	buf := h.buf.Get().(*bytes.Buffer)
@@ -73,24 +84,20 @@ func (h *logtailBatchHandler) send(batch *Batch) error {
 
 	trace("logtail: sending: ", buf.String())
 
-	body := bytes.NewReader(buf.Bytes())
-
-	client := http.Client{
-		Timeout: 5 * time.Second,
-	}
-	rq, err := http.NewRequest(http.MethodPost, h.endpoint, body)
+	rq, err := h.newRequest(http.MethodPost, h.endpoint, buf.Bytes())
 	if err != nil {
 		trace("logtail: send: error initialising request: " + err.Error())
 		return err
 	}
 
-	rq.Header.Add("Authorization", fmt.Sprintf("Bearer %s", h.token))
-	rq.Header.Add("Content-Type", "application/msgpack")
-	rw, err := client.Do(rq)
+	rq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", h.token))
+	rq.Header.Set("Content-Type", "application/msgpack")
+	rw, err := h.client.Do(rq)
 	if err != nil {
 		trace("logtail: send: error sending request: " + err.Error())
 		return err
 	}
+	defer rw.Body.Close()
 
 	trace("logtail: send: result: " + rw.Status)
 
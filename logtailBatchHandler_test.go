@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/blugnu/test"
 )
@@ -45,6 +46,44 @@ func TestLogtailBatchHandler(t *testing.T) {
 				test.Error(t, err).IsNil()
 			},
 		},
+		{scenario: "configure/headers",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure(logtailHeaders, map[string]string{"X-Custom": "value"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "configure/http client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := sut.configure(logtailHTTPClient, client)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.client).Equals(client)
+			},
+		},
+		{scenario: "configure/timeout",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{Timeout: time.Second}
+				_ = sut.configure(logtailHTTPClient, client)
+
+				// ACT
+				err := sut.configure(logtailTimeout, time.Minute)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.timeout, "configured timeout").Equals(time.Minute)
+				test.That(t, client.Timeout, "original client timeout").Equals(time.Second)
+			},
+		},
 		{scenario: "configure/unknown key",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -84,7 +123,7 @@ func TestLogtailBatchHandler(t *testing.T) {
 				}
 
 				// ACT
-				_ = sut.send(b)
+				_ = sut.Send(b)
 
 				// ASSERT
 				test.That(t, authheader).Equals("Bearer token")
@@ -92,6 +131,36 @@ func TestLogtailBatchHandler(t *testing.T) {
 				test.That(t, body).Equals(packedBytes(0x91, 0x81, 0xa3, "key", 0xa5, "value"))
 			},
 		},
+		{scenario: "send/with headers and http client",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					authheader   string
+					customheader string
+				)
+				srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authheader = r.Header.Get("Authorization")
+					customheader = r.Header.Get("X-Custom")
+				}))
+				defer srv.Close()
+
+				sut.endpoint = srv.URL
+				sut.token = "token"
+				_ = sut.configure(logtailHTTPClient, srv.Client())
+				_ = sut.configure(logtailHeaders, map[string]string{
+					"Authorization": "not used",
+					"X-Custom":      "value",
+				})
+
+				// ACT
+				err := sut.Send(&Batch{entries: [][]byte{packedBytes(0xc0)}, len: 1})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, authheader).Equals("Bearer token")
+				test.That(t, customheader).Equals("value")
+			},
+		},
 		{scenario: "send/encoding error",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -99,7 +168,7 @@ func TestLogtailBatchHandler(t *testing.T) {
 				sut.encodeBatch = func(*bytes.Buffer, *Batch) error { return encerr }
 
 				// ACT
-				err := sut.send(&Batch{})
+				err := sut.Send(&Batch{})
 
 				// ASSERT
 				test.Error(t, err).Is(encerr)
@@ -112,7 +181,7 @@ func TestLogtailBatchHandler(t *testing.T) {
 				b := &Batch{}
 
 				// ACT
-				err := sut.send(b)
+				err := sut.Send(b)
 
 				// ASSERT
 				_, _ = test.IsType[*url.Error](t, err)
@@ -124,7 +193,7 @@ func TestLogtailBatchHandler(t *testing.T) {
 				b := &Batch{}
 
 				// ACT
-				err := sut.send(b)
+				err := sut.Send(b)
 
 				// ASSERT
 				_, _ = test.IsType[*url.Error](t, err)
@@ -1,7 +1,9 @@
 package ulog
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -12,6 +14,53 @@ func LogtailEndpoint(s string) LogtailOption {
 	}
 }
 
+// LogtailBatching applies batch options to configure the batching of log
+// entries sent to the BetterStack Logs service, e.g. to impose a limit on
+// the size of each request:
+//
+//	ulog.LogtailTransport(
+//	   ulog.LogtailBatching(
+//	      ulog.BatchMaxBytes(1 << 20),
+//	   ),
+//	)
+func LogtailBatching(opts ...BatchOption) LogtailOption {
+	return func(t *logtail) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// LogtailHeaders configures additional headers to be sent with each request
+// to the BetterStack Logs service.  This option may be specified multiple
+// times; headers are accumulated, with any header specified more than once
+// taking the most recently configured value.
+//
+// The Authorization and Content-Type headers are set by the transport and
+// cannot be overridden.
+func LogtailHeaders(h map[string]string) LogtailOption {
+	return func(t *logtail) error {
+		return t.batch.configure(logtailHeaders, h)
+	}
+}
+
+// LogtailHTTPClient configures the http.Client used to send requests to the
+// BetterStack Logs service.  This may be used to configure TLS (e.g. private
+// CA roots or client certificates), proxy settings or a custom RoundTripper.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func LogtailHTTPClient(c *http.Client) LogtailOption {
+	return func(t *logtail) error {
+		if c == nil {
+			return fmt.Errorf("%w: LogtailHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(logtailHTTPClient, c)
+	}
+}
+
 // configures the maximum number of log entries to send to the BetterStack
 // Logs service in a single request.  The default value is 16.
 //
@@ -20,7 +69,7 @@ func LogtailEndpoint(s string) LogtailOption {
 // been reached.
 func LogtailMaxBatch(m int) LogtailOption {
 	return func(t *logtail) error {
-		t.batch.init(t.batch.batchHandler, m)
+		t.batch.init(t.batch.handler, m)
 		return nil
 	}
 }
@@ -30,11 +79,19 @@ func LogtailMaxBatch(m int) LogtailOption {
 // entry it will be sent after this time has elapsed.
 func LogtailMaxLatency(d time.Duration) LogtailOption {
 	return func(t *logtail) error {
-		t.maxLatency = d
+		t.batch.maxLatency = d
 		return nil
 	}
 }
 
+// LogtailTimeout configures the timeout for requests sent to the BetterStack
+// Logs service.  The default is 5 seconds.
+func LogtailTimeout(d time.Duration) LogtailOption {
+	return func(t *logtail) error {
+		return t.batch.configure(logtailTimeout, d)
+	}
+}
+
 // LogtailSourceToken configures the source token of the log entries sent to the
 // BetterStack Logs service.
 //
@@ -46,19 +103,6 @@ func LogtailMaxLatency(d time.Duration) LogtailOption {
 //   - a source token value (not recommended, to avoid leaking secrets in source)
 func LogtailSourceToken(s string) LogtailOption {
 	return func(t *logtail) error {
-		// if s identifies an environment variable, the source token is
-		// the value of that variable
-		if v, ok := os.LookupEnv(s); ok {
-			return t.batch.configure(logtailSourceToken, v)
-		}
-
-		// if s identifies a file re can read from, the source token is
-		// the contents of that file
-		if b, err := os.ReadFile(s); err == nil {
-			return t.batch.configure(logtailSourceToken, string(b))
-		}
-
-		// otherwise, s is the source token
-		return t.batch.configure(logtailSourceToken, s)
+		return t.batch.configure(logtailSourceToken, readSecret(s))
 	}
 }
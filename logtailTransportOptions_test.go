@@ -1,6 +1,7 @@
 package ulog
 
 import (
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -12,12 +13,32 @@ func TestLogtailTransportOptions(t *testing.T) {
 	// ARRANGE
 	var (
 		bh *logtailBatchHandler
-		lt = &logtail{batch: &Batch{}}
+		lt = &logtail{batchTransport{batch: &Batch{}}}
 	)
 	testcases := []struct {
 		scenario string
 		exec     func(t *testing.T)
 	}{
+		{scenario: "LogtailBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LogtailBatching(BatchMaxBytes(1024), BatchMaxEntryBytes(256))(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, lt.batch.maxBytes, "max bytes").Equals(1024)
+				test.That(t, lt.batch.maxEntryBytes, "max entry bytes").Equals(256)
+			},
+		},
+		{scenario: "LogtailBatching/option error",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LogtailBatching(BatchMaxBytes(-1))(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
 		{scenario: "LogtailEndpoint",
 			exec: func(t *testing.T) {
 				// ACT
@@ -27,6 +48,38 @@ func TestLogtailTransportOptions(t *testing.T) {
 				test.That(t, bh.endpoint).Equals("https://custom.endpoint.com")
 			},
 		},
+		{scenario: "LogtailHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LogtailHeaders(map[string]string{"X-Custom": "value"})(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "LogtailHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := LogtailHTTPClient(client)(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "LogtailHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LogtailHTTPClient(nil)(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
 		{scenario: "LogtailMaxBatch",
 			exec: func(t *testing.T) {
 				// ACT
@@ -42,7 +95,17 @@ func TestLogtailTransportOptions(t *testing.T) {
 				_ = LogtailMaxLatency(1 * time.Hour)(lt)
 
 				// ASSERT
-				test.That(t, lt.maxLatency).Equals(time.Hour)
+				test.That(t, lt.batch.maxLatency).Equals(time.Hour)
+			},
+		},
+		{scenario: "LogtailTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LogtailTimeout(time.Minute)(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
 			},
 		},
 		{scenario: "LogtailSourceToken/literal",
@@ -0,0 +1,239 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	lokiAuthorization = cfgkey("loki.authorization")
+	lokiEncoding      = cfgkey("loki.encoding")
+	lokiEndpoint      = cfgkey("loki.endpoint")
+	lokiHeaders       = cfgkey("loki.headers")
+	lokiHTTPClient    = cfgkey("loki.httpClient")
+	lokiRetry         = cfgkey("loki.retry")
+	lokiTenant        = cfgkey("loki.tenant")
+	lokiTimeout       = cfgkey("loki.timeout")
+)
+
+// LokiPushEncoding identifies the encoding of requests sent to the Loki
+// push api.
+type LokiPushEncoding int
+
+const (
+	LokiJSON     LokiPushEncoding = iota // LokiJSON sends requests encoded as JSON (application/json)
+	LokiProtobuf                         // LokiProtobuf sends requests encoded as snappy compressed protobuf (application/x-protobuf)
+)
+
+// lokiBatchHandler is a batch handler that sends batches of records to
+// the Loki push api.
+type lokiBatchHandler struct {
+	httpSender
+	endpoint      string
+	tenant        string
+	authorization string
+	encoding      LokiPushEncoding
+}
+
+// lokiStream is a stream of entries with the same (encoded) labels.
+type lokiStream struct {
+	labels  []byte
+	entries []lokiStreamEntry
+}
+
+// lokiStreamEntry is the timestamp and line of an entry in a stream.
+type lokiStreamEntry struct {
+	ts   int64
+	line []byte
+}
+
+// newLokiBatchHandler creates a new, initialised loki batch handler.
+func newLokiBatchHandler() *lokiBatchHandler {
+	return &lokiBatchHandler{
+		httpSender: newHTTPSender(),
+		encoding:   LokiJSON,
+	}
+}
+
+// configure applies configuration to the loki batch handler.
+func (h *lokiBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case lokiAuthorization:
+		h.authorization = value.(string)
+	case lokiEncoding:
+		h.encoding = value.(LokiPushEncoding)
+	case lokiEndpoint:
+		h.endpoint = value.(string)
+	case lokiHeaders:
+		h.setHeaders(value.(map[string]string))
+	case lokiHTTPClient:
+		h.client = value.(*http.Client)
+	case lokiRetry:
+		cfg := value.(retryConfig)
+		h.setRetry(cfg.n, cfg.backoff)
+	case lokiTenant:
+		h.tenant = value.(string)
+	case lokiTimeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrLokiConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// streams groups the records in a batch into streams, in the order in
+// which each stream first occurs in the batch.
+func (h *lokiBatchHandler) streams(batch *Batch) []*lokiStream {
+	streams := []*lokiStream{}
+	idx := map[string]*lokiStream{}
+	for _, rec := range batch.entries {
+		ts, labels, line := decodeLokiRecord(rec)
+		s, ok := idx[string(labels)]
+		if !ok {
+			s = &lokiStream{labels: labels}
+			idx[string(labels)] = s
+			streams = append(streams, s)
+		}
+		s.entries = append(s.entries, lokiStreamEntry{ts: ts, line: line})
+	}
+	return streams
+}
+
+// encodeJSON returns the JSON encoding of a push request for specified
+// streams.
+func (h *lokiBatchHandler) encodeJSON(streams []*lokiStream) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	rq := struct {
+		Streams []stream `json:"streams"`
+	}{
+		Streams: make([]stream, 0, len(streams)),
+	}
+	for _, s := range streams {
+		js := stream{
+			Stream: map[string]string{},
+			Values: make([][2]string, 0, len(s.entries)),
+		}
+		decodeLokiLabels(s.labels, func(k, v string) { js.Stream[k] = v })
+		for _, e := range s.entries {
+			js.Values = append(js.Values, [2]string{strconv.FormatInt(e.ts, 10), string(e.line)})
+		}
+		rq.Streams = append(rq.Streams, js)
+	}
+
+	return json.Marshal(rq)
+}
+
+// encodeProtobuf returns the snappy compressed protobuf encoding of a
+// push request for specified streams:
+//
+//	message PushRequest {
+//	  repeated StreamAdapter streams = 1;
+//	}
+//	message StreamAdapter {
+//	  string labels = 1;
+//	  repeated EntryAdapter entries = 2;
+//	}
+//	message EntryAdapter {
+//	  google.protobuf.Timestamp timestamp = 1;
+//	  string line = 2;
+//	}
+func (h *lokiBatchHandler) encodeProtobuf(streams []*lokiStream) []byte {
+	var (
+		rq    []byte
+		sa    []byte
+		ea    []byte
+		ts    []byte
+		label = &strings.Builder{}
+	)
+	for _, s := range streams {
+		label.Reset()
+		_ = label.WriteByte('{')
+		decodeLokiLabels(s.labels, func(k, v string) {
+			if label.Len() > 1 {
+				_, _ = label.WriteString(", ")
+			}
+			_, _ = label.WriteString(k)
+			_ = label.WriteByte('=')
+			_, _ = label.WriteString(strconv.Quote(v))
+		})
+		_ = label.WriteByte('}')
+
+		sa = pbAppendString(sa[:0], 1, label.String())
+		for _, e := range s.entries {
+			ts = pbAppendVarint(ts[:0], 1, uint64(e.ts/int64(time.Second)))
+			ts = pbAppendVarint(ts, 2, uint64(e.ts%int64(time.Second)))
+			ea = pbAppendBytes(ea[:0], 1, ts)
+			ea = pbAppendBytes(ea, 2, e.line)
+			sa = pbAppendBytes(sa, 2, ea)
+		}
+		rq = pbAppendBytes(rq, 1, sa)
+	}
+	return snappyEncode(rq)
+}
+
+// headerLen implements the recordHandler interface, returning the length
+// of the timestamp and labels preceding the line of a record.
+func (h *lokiBatchHandler) headerLen(rec []byte) int {
+	_, n1 := binary.Uvarint(rec)
+	lbsz, n2 := binary.Uvarint(rec[n1:])
+	return n1 + n2 + int(lbsz)
+}
+
+// Send sends a batch of records to the Loki push api.
+//
+// If the request fails or the response has a status indicating that
+// the request may succeed if sent again (e.g. 429 or 5xx), an error is
+// returned and the batch is retained.  Any other unsuccessful response
+// results in the batch being discarded.
+func (h *lokiBatchHandler) Send(batch *Batch) error {
+	tracef("loki: send: sending %d entries", batch.len)
+
+	var (
+		body        []byte
+		contentType string
+		streams     = h.streams(batch)
+	)
+	switch h.encoding {
+	case LokiProtobuf:
+		body = h.encodeProtobuf(streams)
+		contentType = "application/x-protobuf"
+	default:
+		var err error
+		if body, err = h.encodeJSON(streams); err != nil {
+			trace("loki: send: batch encoding failed: " + err.Error())
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	rq, err := h.newRequest(http.MethodPost, h.endpoint, body)
+	if err != nil {
+		trace("loki: send: error initialising request: " + err.Error())
+		return err
+	}
+	rq.Header.Set("Content-Type", contentType)
+	if h.tenant != "" {
+		rq.Header.Set("X-Scope-OrgID", h.tenant)
+	}
+	if h.authorization != "" {
+		rq.Header.Set("Authorization", h.authorization)
+	}
+
+	if _, err := h.do(rq); err != nil {
+		trace("loki: send: error sending request: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("loki: send: %d entries discarded", batch.len)
+	}
+	return nil
+}
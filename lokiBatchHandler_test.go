@@ -0,0 +1,319 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// pbField is a field decoded from a protobuf message.
+type pbField struct {
+	num    int
+	varint uint64
+	bytes  []byte
+}
+
+// pbDecode decodes the varint and length-delimited fields of a protobuf
+// message, for verifying protobuf encoded requests.
+func pbDecode(b []byte) []pbField {
+	result := []pbField{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		b = b[n:]
+		f := pbField{num: int(tag >> 3)}
+		switch tag & 0x07 {
+		case pbVarint:
+			f.varint, n = binary.Uvarint(b)
+			b = b[n:]
+		case pbBytes:
+			sz, n := binary.Uvarint(b)
+			f.bytes = b[n : n+int(sz)]
+			b = b[n+int(sz):]
+		case pbFixed64:
+			f.varint = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case pbFixed32:
+			f.varint = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+func TestLokiBatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut   *lokiBatchHandler
+		tm    = time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+		batch = func(recs ...[]byte) *Batch {
+			b := &Batch{}
+			for _, rec := range recs {
+				b.entries = append(b.entries, rec)
+				b.size += len(rec)
+				b.len++
+			}
+			return b
+		}
+		info   = map[string]string{"level": "info"}
+		errlbl = map[string]string{"level": "error", "service": "api"}
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// headerLen tests
+		{scenario: "headerLen/truncated record",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rec := encodeLokiRecord(tm, errlbl, []byte("a long line"))
+				hl := len(rec) - len("a long line")
+				b := &Batch{max: 10, maxEntryBytes: hl + 4, oversize: TruncateOversizeEntries, handler: sut}
+
+				// ACT
+				b.Add(rec)
+
+				// ASSERT
+				test.That(t, sut.headerLen(rec)).Equals(hl)
+				ts, lb, line := decodeLokiRecord(b.entries[0])
+				labels := map[string]string{}
+				decodeLokiLabels(lb, func(k, v string) { labels[k] = v })
+				test.That(t, ts).Equals(tm.UnixNano())
+				test.Map(t, labels).Equals(errlbl)
+				test.That(t, string(line)).Equals("a lo")
+			},
+		},
+		{scenario: "headerLen/limit less than header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				rec := encodeLokiRecord(tm, errlbl, []byte("line"))
+				b := &Batch{max: 10, maxEntryBytes: 2, oversize: TruncateOversizeEntries, handler: sut}
+
+				// ACT
+				b.Add(rec)
+
+				// ASSERT
+				test.That(t, b.len).Equals(0)
+			},
+		},
+
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				errs := []error{
+					sut.configure(lokiAuthorization, "Bearer token"),
+					sut.configure(lokiEncoding, LokiProtobuf),
+					sut.configure(lokiEndpoint, "http://localhost"),
+					sut.configure(lokiHeaders, map[string]string{"X-Custom": "value"}),
+					sut.configure(lokiHTTPClient, client),
+					sut.configure(lokiRetry, retryConfig{3, time.Second}),
+					sut.configure(lokiTenant, "tenant"),
+					sut.configure(lokiTimeout, time.Minute),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.authorization).Equals("Bearer token")
+				test.That(t, sut.encoding).Equals(LokiProtobuf)
+				test.That(t, sut.endpoint).Equals("http://localhost")
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+				test.That(t, sut.tenant).Equals("tenant")
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrLokiConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// streams tests
+		{scenario: "streams",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := batch(
+					encodeLokiRecord(tm, info, []byte("1")),
+					encodeLokiRecord(tm, errlbl, []byte("2")),
+					encodeLokiRecord(tm, info, []byte("3")),
+				)
+
+				// ACT
+				result := sut.streams(b)
+
+				// ASSERT
+				test.That(t, len(result)).Equals(2)
+				test.That(t, len(result[0].entries)).Equals(2)
+				test.That(t, string(result[0].entries[1].line)).Equals("3")
+				test.That(t, len(result[1].entries)).Equals(1)
+			},
+		},
+
+		// encoding tests
+		{scenario: "encodeJSON",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				streams := sut.streams(batch(
+					encodeLokiRecord(tm, errlbl, []byte(`line "1"`)),
+				))
+
+				// ACT
+				result, err := sut.encodeJSON(streams)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(result)).Equals(`{"streams":[{"stream":{"level":"error","service":"api"},"values":[["1283929565432100000","line \"1\""]]}]}`)
+			},
+		},
+		{scenario: "encodeProtobuf",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				streams := sut.streams(batch(
+					encodeLokiRecord(tm, errlbl, []byte("line 1")),
+					encodeLokiRecord(tm.Add(time.Second), errlbl, []byte("line 2")),
+				))
+
+				// ACT
+				result := sut.encodeProtobuf(streams)
+
+				// ASSERT
+				rq, err := snappyDecode(result)
+				test.Error(t, err).IsNil()
+
+				pr := pbDecode(rq)
+				test.That(t, len(pr), "streams").Equals(1)
+
+				sa := pbDecode(pr[0].bytes)
+				test.That(t, len(sa), "stream fields").Equals(3)
+				test.That(t, string(sa[0].bytes), "labels").Equals(`{level="error", service="api"}`)
+
+				ea := pbDecode(sa[2].bytes)
+				test.That(t, string(ea[1].bytes), "line").Equals("line 2")
+
+				ts := pbDecode(ea[0].bytes)
+				test.That(t, ts[0].varint, "seconds").Equals(uint64(tm.Unix() + 1))
+				test.That(t, ts[1].varint, "nanos").Equals(uint64(432100000))
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					encoding    LokiPushEncoding
+					contentType string
+				}{
+					{encoding: LokiJSON, contentType: "application/json"},
+					{encoding: LokiProtobuf, contentType: "application/x-protobuf"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.contentType, func(t *testing.T) {
+						// ARRANGE
+						var (
+							authheader  string
+							contenttype string
+							tenant      string
+							body        []byte
+						)
+						srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							authheader = r.Header.Get("Authorization")
+							contenttype = r.Header.Get("Content-Type")
+							tenant = r.Header.Get("X-Scope-OrgID")
+							body, _ = io.ReadAll(r.Body)
+							w.WriteHeader(http.StatusNoContent)
+						}))
+						defer srv.Close()
+
+						sut.endpoint = srv.URL
+						sut.authorization = "Bearer token"
+						sut.tenant = "tenant"
+						sut.encoding = tc.encoding
+
+						// ACT
+						err := sut.Send(batch(encodeLokiRecord(tm, info, []byte("line"))))
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, authheader).Equals("Bearer token")
+						test.That(t, contenttype).Equals(tc.contentType)
+						test.That(t, tenant).Equals("tenant")
+						test.IsTrue(t, len(body) > 0)
+					})
+				}
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls := 0
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					w.WriteHeader(http.StatusTooManyRequests)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+				sut.setRetry(1, time.Millisecond)
+
+				// ACT
+				err := sut.Send(batch(encodeLokiRecord(tm, info, []byte("line"))))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, calls).Equals(2)
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch(encodeLokiRecord(tm, info, []byte("line"))))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/invalid endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.endpoint = "\n"
+
+				// ACT
+				err := sut.Send(batch(encodeLokiRecord(tm, info, []byte("line"))))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newLokiBatchHandler()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type LokiOption = func(*loki) error // LokiOption is a function that configures a loki transport
+
+// LokiTransport returns a transport factory function to create and
+// configure a transport that sends log entries to a Grafana Loki server
+// using the Loki push api (/loki/api/v1/push), with specified
+// configuration options applied.
+//
+// An endpoint must be configured using the LokiEndpoint option.
+//
+// Entries are grouped into streams identified by labels, derived from
+// fields of each entry identified using the LokiLabels option, together
+// with any static labels configured using LokiStaticLabels.  Fields used
+// as labels are removed from the entry before it is formatted by the
+// target Formatter to provide the log line.
+//
+// Loki requires at least one label for each stream; if no labels or
+// static labels are configured, the level of each entry is used as a
+// label (equivalent to LokiLabels("level")).
+func LokiTransport(opts ...LokiOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newLokiBatchHandler()
+
+		t := &loki{
+			batchTransport: batchTransport{
+				name:  "loki",
+				ch:    make(chan []byte, 100),
+				batch: &Batch{maxLatency: 10 * time.Second},
+			},
+			static: map[string]string{},
+		}
+		t.batch.init(bh, 100)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if bh.endpoint == "" {
+			return nil, fmt.Errorf("%w: %w: no endpoint configured", ErrLokiConfiguration, ErrInvalidConfiguration)
+		}
+		if len(t.labels) == 0 && len(t.static) == 0 {
+			t.labels = []string{"level"}
+		}
+		return t, nil
+	}
+}
+
+// lokiLevels are the values of the level label for each Level.
+var lokiLevels = [numLevels]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warning",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
+// loki implements a transport that sends log entries to a Grafana Loki
+// server.
+type loki struct {
+	batchTransport
+	labels []string          // names of the fields used as stream labels
+	static map[string]string // static labels applied to all streams
+}
+
+// logEntry implements the entryTransport interface.  The labels for the
+// entry are derived from the entry and the entry is formatted, without
+// any label fields, to provide the log line.  The timestamp, labels and
+// line are then encoded in a record that is sent to the transport channel.
+func (t *loki) logEntry(e entry, format func(entry) []byte) {
+	labels := make(map[string]string, len(t.static)+len(t.labels))
+	for k, v := range t.static {
+		labels[k] = v
+	}
+
+	if len(t.labels) > 0 {
+		for _, k := range t.labels {
+			if k == "level" {
+				labels[k] = lokiLevels[e.Level]
+				continue
+			}
+			if e.logcontext == nil || e.fields == nil {
+				continue
+			}
+			if v, ok := e.fields.m[k]; ok {
+				labels[lokiLabelName(k)] = fmt.Sprintf("%v", v)
+			}
+		}
+
+		if e.logcontext != nil {
+			if f := e.fields.without(t.labels...); f != e.fields {
+				lc := *e.logcontext
+				lc.fields = f
+				e.logcontext = &lc
+			}
+		}
+	}
+
+	t.ch <- encodeLokiRecord(e.Time, labels, format(e))
+}
+
+// lokiLabelName returns a valid Loki label name for a specified name,
+// replacing any character other than a letter, digit or underscore with
+// an underscore and prefixing a name that starts with a digit with an
+// underscore.  A valid name is returned unchanged.
+func lokiLabelName(name string) string {
+	valid := func(i int, c rune) bool {
+		return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+	}
+
+	sanitised := true
+	for i, c := range name {
+		if !valid(i, c) {
+			sanitised = false
+			break
+		}
+	}
+	if sanitised {
+		return name
+	}
+
+	sb := strings.Builder{}
+	sb.Grow(len(name) + 1)
+	for i, c := range name {
+		switch {
+		case valid(i, c):
+			_, _ = sb.WriteRune(c)
+		case i == 0 && c >= '0' && c <= '9':
+			_ = sb.WriteByte('_')
+			_, _ = sb.WriteRune(c)
+		default:
+			_ = sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// encodeLokiRecord encodes the timestamp, labels and line of an entry in a
+// record to be added to a batch.  The record is encoded as:
+//
+//	uvarint    timestamp (nanoseconds since the unix epoch)
+//	uvarint    length of the encoded labels
+//	[]byte     encoded labels
+//	[]byte     line
+//
+// labels are encoded, sorted by name, as a sequence of uvarint length
+// prefixed names and values.  The encoded labels identify the stream
+// of the entry.
+func encodeLokiRecord(ts time.Time, labels map[string]string, line []byte) []byte {
+	names := make([]string, 0, len(labels))
+	lbsz := 0
+	for k, v := range labels {
+		names = append(names, k)
+		lbsz += len(k) + len(v) + 2*binary.MaxVarintLen16
+	}
+	slices.Sort(names)
+
+	lb := make([]byte, 0, lbsz)
+	for _, k := range names {
+		lb = binary.AppendUvarint(lb, uint64(len(k)))
+		lb = append(lb, k...)
+		lb = binary.AppendUvarint(lb, uint64(len(labels[k])))
+		lb = append(lb, labels[k]...)
+	}
+
+	rec := make([]byte, 0, 2*binary.MaxVarintLen64+len(lb)+len(line))
+	rec = binary.AppendUvarint(rec, uint64(ts.UnixNano()))
+	rec = binary.AppendUvarint(rec, uint64(len(lb)))
+	rec = append(rec, lb...)
+	return append(rec, line...)
+}
+
+// decodeLokiRecord decodes a record encoded by encodeLokiRecord, returning
+// the timestamp, encoded labels and line of the record.
+func decodeLokiRecord(rec []byte) (int64, []byte, []byte) {
+	ts, n := binary.Uvarint(rec)
+	rec = rec[n:]
+	lbsz, n := binary.Uvarint(rec)
+	rec = rec[n:]
+	return int64(ts), rec[:lbsz], rec[lbsz:]
+}
+
+// decodeLokiLabels decodes labels encoded in a record, calling a
+// specified function with the name and value of each label in turn.
+func decodeLokiLabels(lb []byte, fn func(string, string)) {
+	next := func() string {
+		sz, n := binary.Uvarint(lb)
+		s := string(lb[n : n+int(sz)])
+		lb = lb[n+int(sz):]
+		return s
+	}
+	for len(lb) > 0 {
+		k := next()
+		fn(k, next())
+	}
+}
@@ -0,0 +1,175 @@
+package ulog
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LokiBasicAuth configures the transport to authenticate requests using
+// basic authentication with a specified username and password.
+//
+// The password may be specified as the name of an environment variable or
+// file from which to read the password (see: LogtailSourceToken).
+func LokiBasicAuth(username, password string) LokiOption {
+	return func(t *loki) error {
+		creds := username + ":" + readSecret(password)
+		return t.batch.configure(lokiAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(creds)))
+	}
+}
+
+// LokiBatching applies batch options to configure the batching of log
+// entries sent by the transport.  By default a batch is sent when it
+// contains 100 entries or after 10 seconds.
+func LokiBatching(opts ...BatchOption) LokiOption {
+	return func(t *loki) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// LokiBearerToken configures the transport to authenticate requests using
+// a bearer token.
+//
+// The token may be specified as the name of an environment variable or file
+// from which to read the token (see: LogtailSourceToken).
+func LokiBearerToken(s string) LokiOption {
+	return func(t *loki) error {
+		return t.batch.configure(lokiAuthorization, "Bearer "+readSecret(s))
+	}
+}
+
+// LokiEncoding configures the encoding of requests sent to the Loki push
+// api.  The default is LokiJSON.
+func LokiEncoding(enc LokiPushEncoding) LokiOption {
+	return func(t *loki) error {
+		switch enc {
+		case LokiJSON, LokiProtobuf:
+			return t.batch.configure(lokiEncoding, enc)
+		default:
+			return fmt.Errorf("%w: LokiEncoding: invalid encoding (%d)", ErrInvalidConfiguration, enc)
+		}
+	}
+}
+
+// LokiEndpoint configures the url of the Loki server.  If the url does not
+// specify a path, the path of the push api (/loki/api/v1/push) is used.
+func LokiEndpoint(s string) LokiOption {
+	return func(t *loki) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: LokiEndpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: LokiEndpoint: %q: scheme must be http or https", ErrInvalidConfiguration, s)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/loki/api/v1/push"
+		}
+		return t.batch.configure(lokiEndpoint, u.String())
+	}
+}
+
+// LokiHeaders configures additional headers to be sent with each request.
+// This option may be specified multiple times; headers are accumulated,
+// with any header specified more than once taking the most recently
+// configured value.
+func LokiHeaders(h map[string]string) LokiOption {
+	return func(t *loki) error {
+		return t.batch.configure(lokiHeaders, h)
+	}
+}
+
+// LokiHTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func LokiHTTPClient(c *http.Client) LokiOption {
+	return func(t *loki) error {
+		if c == nil {
+			return fmt.Errorf("%w: LokiHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(lokiHTTPClient, c)
+	}
+}
+
+// LokiLabels configures the names of fields to be used as stream labels.
+// The name "level" identifies the Level of each entry.
+//
+// Fields used as labels are not included in the log line.  An entry that
+// does not have a field identified as a label is added to a stream without
+// that label.
+//
+// A label name may contain only letters, digits and underscores and may
+// not start with a digit; any other characters in the name of a field are
+// replaced with an underscore in the label name (e.g. a "service.name"
+// field provides a "service_name" label) and a name that starts with a
+// digit is prefixed with an underscore.
+//
+// Labels should be chosen to have a small number of distinct values; each
+// distinct combination of label values results in a separate stream in
+// Loki.
+func LokiLabels(names ...string) LokiOption {
+	return func(t *loki) error {
+		for _, s := range names {
+			if s == "" {
+				return fmt.Errorf("%w: LokiLabels: label name is empty", ErrInvalidConfiguration)
+			}
+		}
+		t.labels = append(t.labels, names...)
+		return nil
+	}
+}
+
+// LokiRetry configures the number of times a request is retried if it
+// fails with an error that may succeed if retried (e.g. a network error
+// or a 429 or 5xx response), and the delay before the first retry.  The
+// delay is doubled for each subsequent retry.
+//
+// By default requests are not retried; a batch that cannot be sent is
+// retained and sent again when next flushed.
+func LokiRetry(n int, backoff time.Duration) LokiOption {
+	return func(t *loki) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: LokiRetry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(lokiRetry, retryConfig{n, backoff})
+	}
+}
+
+// LokiStaticLabels configures labels with fixed values to be applied to
+// all streams, e.g. to identify the application or environment.  Label
+// names are sanitised in the same way as the names of fields used as
+// labels (see: LokiLabels).
+func LokiStaticLabels(labels map[string]string) LokiOption {
+	return func(t *loki) error {
+		for k, v := range labels {
+			if k == "" {
+				return fmt.Errorf("%w: LokiStaticLabels: label name is empty", ErrInvalidConfiguration)
+			}
+			t.static[lokiLabelName(k)] = v
+		}
+		return nil
+	}
+}
+
+// LokiTenant configures the tenant id sent with each request (as the
+// X-Scope-OrgID header) when sending to a multi-tenant Loki server.
+func LokiTenant(id string) LokiOption {
+	return func(t *loki) error {
+		return t.batch.configure(lokiTenant, id)
+	}
+}
+
+// LokiTimeout configures the timeout for requests.  The default is 5 seconds.
+func LokiTimeout(d time.Duration) LokiOption {
+	return func(t *loki) error {
+		return t.batch.configure(lokiTimeout, d)
+	}
+}
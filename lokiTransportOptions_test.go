@@ -0,0 +1,245 @@
+package ulog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestLokiTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		bh *lokiBatchHandler
+		lt *loki
+	)
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "LokiBasicAuth",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiBasicAuth("user", "password")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("Basic dXNlcjpwYXNzd29yZA==")
+			},
+		},
+		{scenario: "LokiBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiBatching(BatchMaxEntries(42))(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, lt.batch.max).Equals(42)
+			},
+		},
+		{scenario: "LokiBearerToken",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiBearerToken("token")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("Bearer token")
+			},
+		},
+		{scenario: "LokiEncoding",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiEncoding(LokiProtobuf)(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.encoding).Equals(LokiProtobuf)
+			},
+		},
+		{scenario: "LokiEncoding/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiEncoding(LokiPushEncoding(-1))(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "LokiEndpoint/with path",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiEndpoint("https://logs.example.com/api/push")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("https://logs.example.com/api/push")
+			},
+		},
+		{scenario: "LokiEndpoint/without path",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiEndpoint("http://localhost:3100/")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("http://localhost:3100/loki/api/v1/push")
+			},
+		},
+		{scenario: "LokiEndpoint/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					LokiEndpoint("\n")(lt),
+					LokiEndpoint("localhost:3100")(lt),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "LokiHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiHeaders(map[string]string{"X-Custom": "value"})(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "LokiHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := LokiHTTPClient(client)(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "LokiHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiHTTPClient(nil)(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "LokiLabels",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiLabels("level", "service")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, lt.labels).Equals([]string{"level", "service"})
+			},
+		},
+		{scenario: "LokiLabels/name requiring sanitisation",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiLabels("service.name")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Slice(t, lt.labels).Equals([]string{"service.name"})
+			},
+		},
+		{scenario: "LokiLabels/empty name",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiLabels("")(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "LokiRetry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiRetry(3, time.Second)(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.retries).Equals(3)
+				test.That(t, bh.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "LokiRetry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiRetry(-1, time.Second)(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "LokiStaticLabels",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiStaticLabels(map[string]string{"env": "prod"})(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, lt.static).Equals(map[string]string{"env": "prod"})
+			},
+		},
+		{scenario: "LokiStaticLabels/names requiring sanitisation",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiStaticLabels(map[string]string{"0env": "prod", "app-name": "api"})(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, lt.static).Equals(map[string]string{"_0env": "prod", "app_name": "api"})
+			},
+		},
+		{scenario: "LokiStaticLabels/empty name",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiStaticLabels(map[string]string{"": "prod"})(lt)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "LokiTenant",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiTenant("tenant")(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.tenant).Equals("tenant")
+			},
+		},
+		{scenario: "LokiTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := LokiTimeout(time.Minute)(lt)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newLokiBatchHandler()
+			lt = &loki{batchTransport: batchTransport{batch: &Batch{}}, static: map[string]string{}}
+			lt.batch.init(bh, 16)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package ulog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestLokiTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// LokiTransport tests
+		{scenario: "LokiTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*loki) error { return opterr }
+
+				// ACT
+				result, err := LokiTransport(LokiEndpoint("http://localhost:3100"), opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "LokiTransport/no endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := LokiTransport()()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrLokiConfiguration)
+			},
+		},
+		{scenario: "LokiTransport/with endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := LokiTransport(LokiEndpoint("http://localhost:3100"))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*loki](t, result); ok {
+					test.That(t, result.batch.max, "batch capacity").Equals(100)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(10 * time.Second)
+					test.Slice(t, result.labels, "default labels").Equals([]string{"level"})
+					if handler, ok := test.IsType[*lokiBatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.endpoint, "endpoint").Equals("http://localhost:3100/loki/api/v1/push")
+					}
+				}
+			},
+		},
+		{scenario: "LokiTransport/with static labels",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := LokiTransport(
+					LokiEndpoint("http://localhost:3100"),
+					LokiStaticLabels(map[string]string{"app": "api"}),
+				)()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*loki](t, result); ok {
+					test.That(t, len(result.labels), "labels").Equals(0)
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry/no labels",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &loki{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(e.Message) })
+
+				// ASSERT
+				ts, lb, line := decodeLokiRecord(<-sut.ch)
+				test.That(t, ts).Equals(tm.UnixNano())
+				test.That(t, len(lb)).Equals(0)
+				test.That(t, string(line)).Equals("message")
+			},
+		},
+		{scenario: "logEntry/with labels",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &loki{
+					batchTransport: batchTransport{ch: make(chan []byte, 1)},
+					labels:         []string{"level", "service", "http.method", "missing"},
+					static:         map[string]string{"env": "test"},
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(2).merge(map[string]any{
+						"service":     "api",
+						"http.method": "GET",
+						"key":         "value",
+					})},
+					Time:    tm,
+					Level:   WarnLevel,
+					Message: "message",
+				}
+				formatted := map[string]any{}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte {
+					formatted = e.fields.m
+					return []byte(e.Message)
+				})
+
+				// ASSERT
+				_, lb, _ := decodeLokiRecord(<-sut.ch)
+				labels := map[string]string{}
+				decodeLokiLabels(lb, func(k, v string) { labels[k] = v })
+				test.Map(t, labels).Equals(map[string]string{"env": "test", "level": "warning", "service": "api", "http_method": "GET"})
+				test.Map(t, formatted, "formatted fields").Equals(map[string]any{"key": "value"})
+				test.That(t, len(e.fields.m), "original fields").Equals(3)
+			},
+		},
+
+		// lokiLabelName tests
+		{scenario: "lokiLabelName",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name   string
+					result string
+				}{
+					{name: "service", result: "service"},
+					{name: "_Service_1", result: "_Service_1"},
+					{name: "service.name", result: "service_name"},
+					{name: "http-status code", result: "http_status_code"},
+					{name: "1xx", result: "_1xx"},
+					{name: "1.x", result: "_1_x"},
+					{name: "région", result: "r_gion"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := lokiLabelName(tc.name)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// record tests
+		{scenario: "encodeLokiRecord/decodeLokiRecord",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				labels := map[string]string{"b": "2", "a": "1"}
+
+				// ACT
+				rec := encodeLokiRecord(tm, labels, []byte("line"))
+				ts, lb, line := decodeLokiRecord(rec)
+
+				// ASSERT
+				names := []string{}
+				decodeLokiLabels(lb, func(k, v string) { names = append(names, k+"="+v) })
+				test.That(t, ts).Equals(tm.UnixNano())
+				test.Slice(t, names).Equals([]string{"a=1", "b=2"})
+				test.That(t, string(line)).Equals("line")
+			},
+		},
+
+		// end-to-end
+		{scenario: "sends streams to loki",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					path   string
+					tenant string
+					body   []byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					path = r.URL.Path
+					tenant = r.Header.Get("X-Scope-OrgID")
+					body, _ = io.ReadAll(r.Body)
+					w.WriteHeader(http.StatusNoContent)
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(&mockformatter{}),
+							TargetTransport(LokiTransport(
+								LokiEndpoint(srv.URL),
+								LokiLabels("level"),
+								LokiTenant("tenant"),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("info 1")
+				logger.Warn("warn 1")
+				logger.Info("info 2")
+				closelog()
+
+				// ASSERT
+				rq := struct {
+					Streams []struct {
+						Stream map[string]string `json:"stream"`
+						Values [][2]string       `json:"values"`
+					} `json:"streams"`
+				}{}
+				test.Error(t, json.Unmarshal(body, &rq)).IsNil()
+				test.That(t, path).Equals("/loki/api/v1/push")
+				test.That(t, tenant).Equals("tenant")
+				test.That(t, len(rq.Streams), "streams").Equals(2)
+				test.Map(t, rq.Streams[0].Stream).Equals(map[string]string{"level": "info"})
+				test.That(t, len(rq.Streams[0].Values), "info entries").Equals(2)
+				test.That(t, rq.Streams[0].Values[1][1]).Equals("info 2")
+				test.Map(t, rq.Streams[1].Stream).Equals(map[string]string{"level": "warning"})
+			},
+		},
+		{scenario: "sends labelled streams to loki by default",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var body []byte
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ = io.ReadAll(r.Body)
+					w.WriteHeader(http.StatusNoContent)
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(&mockformatter{}),
+							TargetTransport(LokiTransport(LokiEndpoint(srv.URL))),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("info")
+				closelog()
+
+				// ASSERT
+				rq := struct {
+					Streams []struct {
+						Stream map[string]string `json:"stream"`
+					} `json:"streams"`
+				}{}
+				test.Error(t, json.Unmarshal(body, &rq)).IsNil()
+				test.That(t, len(rq.Streams), "streams").Equals(1)
+				for _, s := range rq.Streams {
+					test.IsTrue(t, len(s.Stream) > 0, "stream has labels")
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+type NetOption = func(*netTransport) error // NetOption is a function that configures a net transport
+
+// NetState identifies the health of the connection of a net transport.
+type NetState int
+
+const (
+	NetConnecting NetState = iota // NetConnecting is the initial state, before any entry has been sent
+	NetConnected                  // NetConnected indicates that entries are being sent successfully
+	NetBuffering                  // NetBuffering indicates that the transport is not connected; entries are queued
+	NetDropping                   // NetDropping indicates that the transport is not connected and the queue is full; the oldest entries are discarded
+	NetStopped                    // NetStopped indicates that the transport has been stopped
+)
+
+// String implements the Stringer interface for NetState.
+func (s NetState) String() string {
+	switch s {
+	case NetConnecting:
+		return "connecting"
+	case NetConnected:
+		return "connected"
+	case NetBuffering:
+		return "buffering"
+	case NetDropping:
+		return "dropping"
+	case NetStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("<invalid state (%d)>", int(s))
+}
+
+// NetTransport returns a transport factory function to create and configure
+// a transport that writes log entries to a network (or unix socket) address,
+// with specified configuration options applied.  The network must be one of
+// "tcp", "udp", "unix" (stream socket) or "unixgram" (datagram socket).
+//
+// Each entry is formatted by the target Formatter and framed (see:
+// NetFraming) before being written to the connection.
+//
+// If a connection cannot be established, or fails, the transport reconnects
+// with a backoff (see: NetReconnect).  While not connected, entries are held
+// in a bounded queue (see: NetQueueSize) and sent when the connection is
+// re-established; if the queue is full, the oldest entry is discarded.  Any
+// entries remaining in the queue when the transport is stopped (after a
+// final attempt to send them) are discarded.
+//
+// The health of the connection is tracked as a NetState; changes in state
+// are emitted as trace messages and may be observed using a callback (see:
+// NetStateChange).
+func NetTransport(network, address string, opts ...NetOption) TransportFactory {
+	return func() (transport, error) {
+		switch network {
+		case "tcp", "udp", "unix", "unixgram":
+		default:
+			return nil, fmt.Errorf("%w: %w: %q: network must be tcp, udp, unix or unixgram", ErrNetConfiguration, ErrInvalidConfiguration, network)
+		}
+		if address == "" {
+			return nil, fmt.Errorf("%w: %w: address is required", ErrNetConfiguration, ErrInvalidConfiguration)
+		}
+
+		t := &netTransport{
+			ch:          make(chan []byte, 100),
+			network:     network,
+			address:     address,
+			dialTimeout: 5 * time.Second,
+			queueSize:   1000,
+			conn: &netconn{
+				name:       "net",
+				minBackoff: 100 * time.Millisecond,
+				maxBackoff: 30 * time.Second,
+			},
+		}
+		t.conn.dial = t.dial
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		if t.tls != nil && t.network != "tcp" {
+			return nil, fmt.Errorf("%w: %w: tls requires a tcp network", ErrNetConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// netTransport implements a transport that writes log entries to a network
+// (or unix socket) address.
+type netTransport struct {
+	ch          chan []byte             // channel over which framed entries are received
+	conn        *netconn                // the connection to the address
+	network     string                  // the network of the address
+	address     string                  // the address to which entries are written
+	tls         *tls.Config             // tls configuration for a tcp connection; nil if tls is not used
+	dialTimeout time.Duration           // timeout for establishing a connection
+	framing     Framing                 // the framing applied to entries
+	queue       [][]byte                // entries waiting to be sent
+	queueSize   int                     // the maximum number of entries in the queue
+	dropped     int                     // the number of entries discarded since the last successful write
+	state       NetState                // the current state of the connection
+	onState     func(from, to NetState) // optional function called when the state changes
+}
+
+// dial establishes a connection to the configured network and address.
+func (t *netTransport) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: t.dialTimeout}
+	if t.tls != nil {
+		return tls.DialWithDialer(d, t.network, t.address, t.tls)
+	}
+	return d.Dial(t.network, t.address)
+}
+
+// setState changes the state of the transport, emitting a trace message
+// and calling any configured state change function.
+func (t *netTransport) setState(s NetState) {
+	if s == t.state {
+		return
+	}
+	old := t.state
+	t.state = s
+
+	tracef("net: %s -> %s", old, s)
+	if t.onState != nil {
+		t.onState(old, s)
+	}
+}
+
+// enqueue adds an entry to the queue.  If the queue is full the oldest
+// entry is discarded.
+func (t *netTransport) enqueue(b []byte) {
+	if len(t.queue) >= t.queueSize {
+		t.queue[0] = nil
+		t.queue = t.queue[1:]
+		t.dropped++
+		t.setState(NetDropping)
+	}
+	t.queue = append(t.queue, b)
+}
+
+// flush sends queued entries until the queue is empty or a write fails.
+func (t *netTransport) flush() {
+	for len(t.queue) > 0 {
+		if err := t.conn.write(t.queue[0]); err != nil {
+			if t.state != NetDropping {
+				t.setState(NetBuffering)
+			}
+			return
+		}
+		t.queue[0] = nil
+		t.queue = t.queue[1:]
+
+		if t.dropped > 0 {
+			tracef("net: %d entries discarded while not connected", t.dropped)
+			t.dropped = 0
+		}
+		t.setState(NetConnected)
+	}
+}
+
+// log implements the transport interface.  A framed copy of the formatted
+// entry is sent to the transport channel.
+func (t *netTransport) log(b []byte) {
+	t.ch <- t.framing.frame(b)
+}
+
+// stop closes the channel over which entries are received.
+func (t *netTransport) stop() {
+	tracef("net: transport requested to stop...")
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  Entries are added to
+// the queue as they are received and the queue is then flushed.  If any
+// entries remain in the queue, a further attempt to flush the queue is
+// made when a reconnection attempt is due.
+//
+// The run loop terminates when the channel over which entries are
+// received is closed.  A final attempt is made to send any queued
+// entries and the connection is closed.
+func (t *netTransport) run() {
+	var retry <-chan time.Time
+	for {
+		select {
+		case b, ok := <-t.ch:
+			if !ok {
+				t.conn.retryAt = time.Time{}
+				t.flush()
+				if n := len(t.queue) + t.dropped; n > 0 {
+					tracef("net: %d entries discarded", n)
+				}
+				t.conn.disconnect()
+				t.setState(NetStopped)
+				tracef("net: transport stopped")
+				return
+			}
+			t.enqueue(b)
+			t.flush()
+
+		case <-retry:
+			t.flush()
+		}
+
+		retry = nil
+		if len(t.queue) > 0 {
+			retry = time.After(max(t.conn.retryAt.Sub(now()), t.conn.minBackoff))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+)
+
+// NetDialTimeout configures the timeout for establishing a connection.
+// The default is 5 seconds.
+func NetDialTimeout(d time.Duration) NetOption {
+	return func(t *netTransport) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: NetDialTimeout: must be > 0", ErrInvalidConfiguration)
+		}
+		t.dialTimeout = d
+		return nil
+	}
+}
+
+// NetFraming configures the framing applied to entries.  The default is
+// NewlineFraming.  A binary format (e.g. msgpack) should use NoFraming
+// (if the format is self-delimiting) or a length prefix, since the format
+// may itself contain newline bytes.
+func NetFraming(f Framing) NetOption {
+	return func(t *netTransport) error {
+		if !f.isValid() {
+			return fmt.Errorf("%w: NetFraming: %s", ErrInvalidConfiguration, f)
+		}
+		t.framing = f
+		return nil
+	}
+}
+
+// NetQueueSize configures the maximum number of entries held while the
+// transport is not connected.  The default is 1000.
+func NetQueueSize(n int) NetOption {
+	return func(t *netTransport) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: NetQueueSize: must be > 0", ErrInvalidConfiguration)
+		}
+		t.queueSize = n
+		return nil
+	}
+}
+
+// NetReconnect configures the delay before attempting to reconnect
+// after a connection fails, and the maximum delay.  The delay is doubled
+// after each consecutive failure, up to the maximum.  The defaults are
+// 100ms and 30s respectively.
+func NetReconnect(backoff, maxBackoff time.Duration) NetOption {
+	return func(t *netTransport) error {
+		if backoff <= 0 || maxBackoff < backoff {
+			return fmt.Errorf("%w: NetReconnect: backoff must be > 0 and <= max backoff", ErrInvalidConfiguration)
+		}
+		t.conn.minBackoff = backoff
+		t.conn.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
+// NetStateChange configures a function to be called when the state of the
+// connection changes, e.g. to report a loss of connectivity.  The function
+// is called by the goroutine of the transport and should not block.
+func NetStateChange(fn func(from, to NetState)) NetOption {
+	return func(t *netTransport) error {
+		if fn == nil {
+			return fmt.Errorf("%w: NetStateChange: function is required", ErrInvalidConfiguration)
+		}
+		t.onState = fn
+		return nil
+	}
+}
+
+// NetTLS configures the transport to connect to a tcp network using TLS,
+// with a specified configuration.  A nil configuration uses the default
+// configuration.
+func NetTLS(cfg *tls.Config) NetOption {
+	return func(t *netTransport) error {
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		t.tls = cfg
+		return nil
+	}
+}
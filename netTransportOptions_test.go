@@ -0,0 +1,156 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestNetTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *netTransport
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "NetDialTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetDialTimeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.dialTimeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "NetDialTimeout/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetDialTimeout(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NetFraming",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetFraming(NoFraming)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.framing).Equals(NoFraming)
+			},
+		},
+		{scenario: "NetFraming/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetFraming(Framing(-1))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NetQueueSize",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetQueueSize(10)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.queueSize).Equals(10)
+			},
+		},
+		{scenario: "NetQueueSize/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetQueueSize(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NetReconnect",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetReconnect(time.Second, time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.conn.minBackoff).Equals(time.Second)
+				test.That(t, sut.conn.maxBackoff).Equals(time.Minute)
+			},
+		},
+		{scenario: "NetReconnect/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					NetReconnect(0, time.Minute)(sut),
+					NetReconnect(time.Minute, time.Second)(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NetStateChange",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var result NetState
+
+				// ACT
+				err := NetStateChange(func(_, to NetState) { result = to })(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				sut.setState(NetConnected)
+				test.That(t, result).Equals(NetConnected)
+			},
+		},
+		{scenario: "NetStateChange/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetStateChange(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NetTLS",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cfg := &tls.Config{ServerName: "logs.example.com"}
+
+				// ACT
+				err := NetTLS(cfg)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tls).Equals(cfg)
+			},
+		},
+		{scenario: "NetTLS/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := NetTLS(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tls).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &netTransport{conn: &netconn{}}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,462 @@
+package ulog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestNetTransport(t *testing.T) {
+	// ARRANGE
+	var (
+		tm       = time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+		writeerr = errors.New("write error")
+	)
+
+	// logto returns a logger that logs to a net transport configured with
+	// specified network, address and options
+	logto := func(t *testing.T, network, address string, opts ...NetOption) (Logger, func()) {
+		logger, closelog, err := NewLogger(context.Background(),
+			Mux(
+				MuxTarget(
+					TargetLevel(InfoLevel),
+					TargetFormat(LogfmtFormatter()),
+					TargetTransport(NetTransport(network, address, opts...)),
+				),
+			),
+		)
+		test.Error(t, err).IsNil()
+		return logger, closelog
+	}
+
+	// mocknet returns a net transport with a mock connection, recording
+	// any changes in state
+	mocknet := func(conn *mockconn, states *[]NetState) *netTransport {
+		sut := &netTransport{queueSize: 2}
+		sut.conn = &netconn{name: "net", minBackoff: time.Second, maxBackoff: time.Minute, dial: func() (net.Conn, error) { return conn, nil }}
+		sut.onState = func(_, to NetState) { *states = append(*states, to) }
+		return sut
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// NetTransport tests
+		{scenario: "NetTransport",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NetTransport("tcp", "localhost:5170")()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*netTransport](t, result); ok {
+					test.That(t, result.network, "network").Equals("tcp")
+					test.That(t, result.address, "address").Equals("localhost:5170")
+					test.That(t, result.framing, "framing").Equals(NewlineFraming)
+					test.That(t, result.queueSize, "queue size").Equals(1000)
+					test.That(t, result.dialTimeout, "dial timeout").Equals(5 * time.Second)
+					test.That(t, result.conn.minBackoff, "min backoff").Equals(100 * time.Millisecond)
+					test.That(t, result.conn.maxBackoff, "max backoff").Equals(30 * time.Second)
+					test.That(t, result.state, "state").Equals(NetConnecting)
+				}
+			},
+		},
+		{scenario: "NetTransport/invalid network",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NetTransport("ip", "localhost")()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrNetConfiguration)
+			},
+		},
+		{scenario: "NetTransport/no address",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NetTransport("tcp", "")()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrNetConfiguration)
+			},
+		},
+		{scenario: "NetTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*netTransport) error { return opterr }
+
+				// ACT
+				result, err := NetTransport("tcp", "localhost:5170", opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "NetTransport/tls without tcp",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := NetTransport("udp", "localhost:5170", NetTLS(nil))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrNetConfiguration)
+			},
+		},
+
+		// NetState tests
+		{scenario: "NetState/String",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					NetState
+					result string
+				}{
+					{NetState: NetConnecting, result: "connecting"},
+					{NetState: NetConnected, result: "connected"},
+					{NetState: NetBuffering, result: "buffering"},
+					{NetState: NetDropping, result: "dropping"},
+					{NetState: NetStopped, result: "stopped"},
+					{NetState: NetState(-1), result: "<invalid state (-1)>"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.result, func(t *testing.T) {
+						// ACT
+						result := tc.String()
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// log tests
+		{scenario: "log",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &netTransport{ch: make(chan []byte, 1), framing: LengthPrefixFraming}
+				b := []byte("entry")
+
+				// ACT
+				sut.log(b)
+				b[0] = 'E'
+
+				// ASSERT
+				test.That(t, <-sut.ch).Equals([]byte("\x00\x00\x00\x05entry"))
+			},
+		},
+
+		// flush tests
+		{scenario: "flush",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				states := []NetState{}
+				conn := &mockconn{}
+				sut := mocknet(conn, &states)
+				sut.queue = [][]byte{[]byte("one"), []byte("two")}
+
+				// ACT
+				sut.flush()
+
+				// ASSERT
+				test.That(t, conn.written).Equals([][]byte{[]byte("one"), []byte("two")})
+				test.That(t, len(sut.queue), "queue").Equals(0)
+				test.Slice(t, states).Equals([]NetState{NetConnected})
+			},
+		},
+		{scenario: "flush/not connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&now, func() time.Time { return tm })()
+				states := []NetState{}
+				sut := mocknet(nil, &states)
+				sut.conn.dial = func() (net.Conn, error) { return nil, writeerr }
+				sut.queue = [][]byte{[]byte("one")}
+
+				// ACT
+				sut.flush()
+
+				// ASSERT
+				test.That(t, len(sut.queue), "queue").Equals(1)
+				test.Slice(t, states).Equals([]NetState{NetBuffering})
+				test.That(t, sut.conn.retryAt).Equals(tm.Add(time.Second))
+			},
+		},
+		{scenario: "enqueue/queue full",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				states := []NetState{}
+				sut := mocknet(nil, &states)
+				sut.state = NetBuffering
+
+				// ACT
+				sut.enqueue([]byte("one"))
+				sut.enqueue([]byte("two"))
+				sut.enqueue([]byte("three"))
+
+				// ASSERT
+				test.That(t, sut.queue).Equals([][]byte{[]byte("two"), []byte("three")})
+				test.That(t, sut.dropped).Equals(1)
+				test.Slice(t, states).Equals([]NetState{NetDropping})
+			},
+		},
+		{scenario: "flush/after dropping",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+
+				states := []NetState{}
+				conn := &mockconn{}
+				sut := mocknet(conn, &states)
+				sut.state = NetDropping
+				sut.dropped = 3
+				sut.queue = [][]byte{[]byte("one")}
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.flush()
+				})
+
+				// ASSERT
+				stderr.Contains("net: 3 entries discarded while not connected")
+				test.That(t, sut.dropped).Equals(0)
+				test.Slice(t, states).Equals([]NetState{NetConnected})
+			},
+		},
+
+		// run tests
+		{scenario: "run/retries when due",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				states := []NetState{}
+				conn := &mockconn{}
+				sut := mocknet(conn, &states)
+				sut.ch = make(chan []byte, 1)
+				sut.conn.minBackoff = time.Millisecond
+				sut.conn.maxBackoff = time.Millisecond
+
+				dials := 0
+				connected := make(chan struct{})
+				sut.conn.dial = func() (net.Conn, error) {
+					if dials++; dials == 1 {
+						return nil, writeerr
+					}
+					close(connected)
+					return conn, nil
+				}
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					sut.run()
+				}()
+
+				// ACT
+				sut.ch <- []byte("entry")
+				<-connected
+				sut.stop()
+				<-done
+
+				// ASSERT
+				test.That(t, conn.written).Equals([][]byte{[]byte("entry")})
+				test.Slice(t, states).Equals([]NetState{NetBuffering, NetConnected, NetStopped})
+			},
+		},
+		{scenario: "run/entries discarded when stopped",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+
+				states := []NetState{}
+				sut := mocknet(nil, &states)
+				sut.ch = make(chan []byte, 3)
+				sut.conn.dial = func() (net.Conn, error) { return nil, writeerr }
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.ch <- []byte("one")
+					sut.ch <- []byte("two")
+					sut.ch <- []byte("three")
+					sut.stop()
+					sut.run()
+				})
+
+				// ASSERT
+				stderr.Contains("net: 3 entries discarded")
+				test.Slice(t, states).Equals([]NetState{NetBuffering, NetDropping, NetStopped})
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "sends over tcp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				logger, closelog := logto(t, "tcp", l.Addr().String(), NetFraming(LengthPrefixFraming))
+
+				// ACT
+				logger.Info("one")
+				logger.Info("two")
+				closelog()
+
+				// ASSERT
+				conn, err := l.Accept()
+				test.Error(t, err).IsNil()
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+				for _, msg := range []string{"one", "two"} {
+					var n uint32
+					test.Error(t, binary.Read(r, binary.BigEndian, &n)).IsNil()
+					b := make([]byte, n)
+					_, err := io.ReadFull(r, b)
+					test.Error(t, err).IsNil()
+					test.IsTrue(t, bytes.Contains(b, []byte(`message="`+msg+`"`)), msg)
+				}
+			},
+		},
+		{scenario: "sends over tls",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewUnstartedServer(nil)
+				srv.StartTLS()
+				cert := srv.TLS.Certificates
+				srv.Close()
+
+				l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: cert})
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				received := make(chan []byte, 1)
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					b, _ := bufio.NewReader(conn).ReadBytes('\n')
+					received <- b
+				}()
+
+				logger, closelog := logto(t, "tcp", l.Addr().String(), NetTLS(&tls.Config{InsecureSkipVerify: true}))
+
+				// ACT
+				logger.Info("entry")
+				closelog()
+
+				// ASSERT
+				test.IsTrue(t, bytes.Contains(<-received, []byte(`message="entry"`)))
+			},
+		},
+		{scenario: "sends over udp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer conn.Close()
+
+				logger, closelog := logto(t, "udp", conn.LocalAddr().String())
+
+				// ACT
+				logger.Info("entry")
+				closelog()
+
+				// ASSERT
+				_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+				buf := make([]byte, 1024)
+				n, _, err := conn.ReadFrom(buf)
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, bytes.Contains(buf[:n], []byte(`message="entry"`)))
+				test.That(t, buf[n-1]).Equals(byte('\n'))
+			},
+		},
+		{scenario: "sends over unix socket",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				path := filepath.Join(t.TempDir(), "net.sock")
+				l, err := net.Listen("unix", path)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				logger, closelog := logto(t, "unix", path)
+
+				// ACT
+				logger.Info("one")
+				logger.Info("two")
+				closelog()
+
+				// ASSERT
+				conn, err := l.Accept()
+				test.Error(t, err).IsNil()
+				defer conn.Close()
+
+				b, _ := readAll(conn)
+				test.That(t, bytes.Count(b, []byte("\n"))).Equals(2)
+			},
+		},
+		{scenario: "reconnects and sends queued entries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				addr := l.Addr().String()
+				test.Error(t, l.Close()).IsNil()
+
+				states := make(chan NetState, 10)
+				logger, closelog := logto(t, "tcp", addr,
+					NetReconnect(time.Millisecond, 10*time.Millisecond),
+					NetStateChange(func(_, to NetState) { states <- to }),
+				)
+
+				// ACT
+				logger.Info("one")
+				test.That(t, <-states).Equals(NetBuffering)
+
+				l, err = net.Listen("tcp", addr)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				test.That(t, <-states).Equals(NetConnected)
+				logger.Info("two")
+				closelog()
+
+				// ASSERT
+				test.That(t, <-states).Equals(NetStopped)
+
+				conn, err := l.Accept()
+				test.Error(t, err).IsNil()
+				defer conn.Close()
+
+				b, _ := readAll(conn)
+				test.That(t, bytes.Count(b, []byte("\n"))).Equals(2)
+				test.IsTrue(t, bytes.Contains(b, []byte(`message="one"`)), "queued entry")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package ulog
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// errNotConnected is returned by a netconn when a connection is not
+// established and a reconnection attempt is not yet due.
+var errNotConnected = errors.New("not connected")
+
+// netconn manages a connection used by a transport to write to a network
+// (or unix socket) address.
+//
+// A connection is established when first written to.  If a connection
+// cannot be established, or fails when written to, further attempts to
+// connect are delayed by a backoff that is doubled after each consecutive
+// failure (up to a maximum).  Writes made while a reconnection attempt
+// is not yet due fail with errNotConnected.
+//
+// netconn is not thread-safe; it is intended to be used only by the run
+// loop of a transport.
+type netconn struct {
+	name       string                        // name of the transport (used in trace messages)
+	dial       func() (net.Conn, error)      // function to establish a connection
+	frame      func(net.Conn, []byte) []byte // optional function applying framing to data written to a connection
+	minBackoff time.Duration                 // the delay before the first reconnection attempt
+	maxBackoff time.Duration                 // the maximum delay between reconnection attempts
+	conn       net.Conn                      // the current connection; nil if not connected
+	backoff    time.Duration                 // the current backoff
+	retryAt    time.Time                     // the time at which the next connection attempt is due
+}
+
+// connect establishes a connection, if one is not already established
+// and a connection attempt is due.
+func (c *netconn) connect() error {
+	if c.conn != nil {
+		return nil
+	}
+	if now().Before(c.retryAt) {
+		return errNotConnected
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		c.backoff = min(max(c.minBackoff, 2*c.backoff), c.maxBackoff)
+		c.retryAt = now().Add(c.backoff)
+		tracef("%s: connection failed (retry in %v): %s", c.name, c.backoff, err)
+		return err
+	}
+
+	tracef("%s: connected to %s", c.name, conn.RemoteAddr())
+	c.conn = conn
+	c.backoff = 0
+	return nil
+}
+
+// disconnect closes any established connection.
+func (c *netconn) disconnect() {
+	if c.conn == nil {
+		return
+	}
+	_ = c.conn.Close()
+	c.conn = nil
+}
+
+// write writes data to the connection, connecting first if required.
+//
+// If the write fails, the connection is closed and a single attempt is
+// made to reconnect and write the data again (a stream connection that
+// has been closed by the remote end may not fail until written to).
+func (c *netconn) write(b []byte) error {
+	if err := c.connect(); err != nil {
+		return err
+	}
+	err := c.send(b)
+	if err == nil {
+		return nil
+	}
+	tracef("%s: write failed: %s", c.name, err)
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+	return c.send(b)
+}
+
+// send writes data to an established connection, applying any framing.
+// If the write fails the connection is closed.
+func (c *netconn) send(b []byte) error {
+	if c.frame != nil {
+		b = c.frame(c.conn, b)
+	}
+	if _, err := c.conn.Write(b); err != nil {
+		c.disconnect()
+		return err
+	}
+	return nil
+}
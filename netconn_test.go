@@ -0,0 +1,197 @@
+package ulog
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestNetconn(t *testing.T) {
+	// ARRANGE
+	var (
+		tm       = time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+		dialerr  = errors.New("dial error")
+		writeerr = errors.New("write error")
+	)
+	ognow := now
+	defer func() { now = ognow }()
+	now = func() time.Time { return tm }
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "connect/already connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn := &mockconn{}
+				sut := &netconn{conn: conn, dial: func() (net.Conn, error) { panic("not expected") }}
+
+				// ACT
+				err := sut.connect()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.conn).Equals(net.Conn(conn))
+			},
+		},
+		{scenario: "connect/dial ok",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn := &mockconn{}
+				sut := &netconn{backoff: time.Second, dial: func() (net.Conn, error) { return conn, nil }}
+
+				// ACT
+				err := sut.connect()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.conn).Equals(net.Conn(conn))
+				test.That(t, sut.backoff).Equals(time.Duration(0))
+			},
+		},
+		{scenario: "connect/dial fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &netconn{
+					minBackoff: time.Second,
+					maxBackoff: 3 * time.Second,
+					dial:       func() (net.Conn, error) { return nil, dialerr },
+				}
+
+				// ACT
+				backoff := []time.Duration{}
+				for i := 0; i < 3; i++ {
+					err := sut.connect()
+					test.Error(t, err).Is(dialerr)
+					backoff = append(backoff, sut.backoff)
+					sut.retryAt = time.Time{}
+				}
+
+				// ASSERT
+				test.Slice(t, backoff).Equals([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second})
+			},
+		},
+		{scenario: "connect/retry not due",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &netconn{retryAt: tm.Add(time.Millisecond), dial: func() (net.Conn, error) { panic("not expected") }}
+
+				// ACT
+				err := sut.connect()
+
+				// ASSERT
+				test.Error(t, err).Is(errNotConnected)
+			},
+		},
+		{scenario: "disconnect",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn := &mockconn{}
+				sut := &netconn{conn: conn}
+
+				// ACT
+				sut.disconnect()
+				sut.disconnect()
+
+				// ASSERT
+				test.IsTrue(t, conn.closeWasCalled)
+				test.That(t, sut.conn).IsNil()
+			},
+		},
+		{scenario: "write/with framing",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				conn := &mockconn{}
+				sut := &netconn{
+					dial:  func() (net.Conn, error) { return conn, nil },
+					frame: func(_ net.Conn, b []byte) []byte { return append(b, '\n') },
+				}
+
+				// ACT
+				err := sut.write([]byte("data"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, conn.written).Equals([][]byte{[]byte("data\n")})
+			},
+		},
+		{scenario: "write/not connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &netconn{dial: func() (net.Conn, error) { return nil, dialerr }}
+
+				// ACT
+				err := sut.write([]byte("data"))
+
+				// ASSERT
+				test.Error(t, err).Is(dialerr)
+			},
+		},
+		{scenario: "write/fails then reconnects",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				failed := &mockconn{writefn: func([]byte) (int, error) { return 0, writeerr }}
+				conn := &mockconn{}
+				conns := []*mockconn{failed, conn}
+				sut := &netconn{dial: func() (net.Conn, error) {
+					c := conns[0]
+					conns = conns[1:]
+					return c, nil
+				}}
+
+				// ACT
+				err := sut.write([]byte("data"))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, failed.closeWasCalled, "failed connection closed")
+				test.That(t, conn.written).Equals([][]byte{[]byte("data")})
+			},
+		},
+		{scenario: "write/fails then reconnect fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				failed := &mockconn{writefn: func([]byte) (int, error) { return 0, writeerr }}
+				dials := 0
+				sut := &netconn{dial: func() (net.Conn, error) {
+					dials++
+					if dials == 1 {
+						return failed, nil
+					}
+					return nil, dialerr
+				}}
+
+				// ACT
+				err := sut.write([]byte("data"))
+
+				// ASSERT
+				test.Error(t, err).Is(dialerr)
+				test.That(t, sut.conn).IsNil()
+			},
+		},
+		{scenario: "write/fails twice",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &netconn{dial: func() (net.Conn, error) {
+					return &mockconn{writefn: func([]byte) (int, error) { return 0, writeerr }}, nil
+				}}
+
+				// ACT
+				err := sut.write([]byte("data"))
+
+				// ASSERT
+				test.Error(t, err).Is(writeerr)
+				test.That(t, sut.conn).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package ulog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	otlpEncoding   = cfgkey("otlp.encoding")
+	otlpEndpoint   = cfgkey("otlp.endpoint")
+	otlpHeaders    = cfgkey("otlp.headers")
+	otlpHTTPClient = cfgkey("otlp.httpClient")
+	otlpResource   = cfgkey("otlp.resource")
+	otlpRetry      = cfgkey("otlp.retry")
+	otlpTimeout    = cfgkey("otlp.timeout")
+)
+
+// OTLPEncodingType identifies the encoding of OTLP/HTTP requests.
+type OTLPEncodingType int
+
+const (
+	OTLPProtobuf OTLPEncodingType = iota // OTLPProtobuf sends requests encoded as protobuf (application/x-protobuf)
+	OTLPJSON                             // OTLPJSON sends requests encoded as JSON (application/json)
+)
+
+// otlpBatchHandler is a batch handler that sends batches of log records
+// to an OTLP/HTTP receiver.
+type otlpBatchHandler struct {
+	httpSender
+	endpoint string
+	encoding OTLPEncodingType
+	resource map[string]any
+}
+
+// newOTLPBatchHandler creates a new, initialised otlp batch handler.
+func newOTLPBatchHandler() *otlpBatchHandler {
+	return &otlpBatchHandler{
+		httpSender: newHTTPSender(),
+		resource:   map[string]any{},
+	}
+}
+
+// configure applies configuration to the otlp batch handler.
+func (h *otlpBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case otlpEncoding:
+		h.encoding = value.(OTLPEncodingType)
+	case otlpEndpoint:
+		h.endpoint = value.(string)
+	case otlpHeaders:
+		h.setHeaders(value.(map[string]string))
+	case otlpHTTPClient:
+		h.client = value.(*http.Client)
+	case otlpResource:
+		for k, v := range value.(map[string]any) {
+			h.resource[k] = v
+		}
+	case otlpRetry:
+		cfg := value.(retryConfig)
+		h.setRetry(cfg.n, cfg.backoff)
+	case otlpTimeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrOTLPConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// encodeProtobuf returns the protobuf encoding of an export request for
+// the (protobuf encoded) log records in a batch:
+//
+//	message ExportLogsServiceRequest {
+//	  repeated ResourceLogs resource_logs = 1;
+//	}
+//	message ResourceLogs {
+//	  Resource resource = 1;
+//	  repeated ScopeLogs scope_logs = 2;
+//	}
+//	message Resource {
+//	  repeated KeyValue attributes = 1;
+//	}
+//	message ScopeLogs {
+//	  InstrumentationScope scope = 1;
+//	  repeated LogRecord log_records = 2;
+//	}
+//	message InstrumentationScope {
+//	  string name = 1;
+//	}
+func (h *otlpBatchHandler) encodeProtobuf(batch *Batch) []byte {
+	sl := pbAppendBytes(make([]byte, 0, batch.size+16*batch.len+64), 1, pbAppendString(nil, 1, otlpScope))
+	for _, rec := range batch.entries {
+		sl = pbAppendBytes(sl, 2, rec)
+	}
+
+	rl := pbAppendBytes(nil, 1, otlpAppendPBAttributes(nil, 1, h.resource))
+	rl = pbAppendBytes(rl, 2, sl)
+
+	return pbAppendBytes(nil, 1, rl)
+}
+
+// encodeJSON returns the JSON encoding of an export request for the
+// (JSON encoded) log records in a batch.
+func (h *otlpBatchHandler) encodeJSON(batch *Batch) ([]byte, error) {
+	records := make([]json.RawMessage, 0, batch.len)
+	for _, rec := range batch.entries {
+		records = append(records, rec)
+	}
+
+	rq := map[string]any{
+		"resourceLogs": []any{
+			map[string]any{
+				"resource": map[string]any{"attributes": otlpJSONAttributes(h.resource)},
+				"scopeLogs": []any{
+					map[string]any{
+						"scope":      map[string]any{"name": otlpScope},
+						"logRecords": records,
+					},
+				},
+			},
+		},
+	}
+	return json.Marshal(rq)
+}
+
+// Send sends a batch of log records to the OTLP/HTTP receiver.
+//
+// If the request fails or the response has a status indicating that
+// the request may succeed if sent again (e.g. 429 or 5xx), an error is
+// returned and the batch is retained.  Any other unsuccessful response
+// results in the batch being discarded.
+func (h *otlpBatchHandler) Send(batch *Batch) error {
+	tracef("otlp: send: sending %d entries", batch.len)
+
+	var (
+		body        []byte
+		contentType string
+	)
+	switch h.encoding {
+	case OTLPJSON:
+		var err error
+		if body, err = h.encodeJSON(batch); err != nil {
+			trace("otlp: send: batch encoding failed: " + err.Error())
+			return err
+		}
+		contentType = "application/json"
+	default:
+		body = h.encodeProtobuf(batch)
+		contentType = "application/x-protobuf"
+	}
+
+	rq, err := h.newRequest(http.MethodPost, h.endpoint, body)
+	if err != nil {
+		trace("otlp: send: error initialising request: " + err.Error())
+		return err
+	}
+	rq.Header.Set("Content-Type", contentType)
+
+	if _, err := h.do(rq); err != nil {
+		trace("otlp: send: error sending request: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("otlp: send: %d entries discarded", batch.len)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package ulog
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestOTLPBatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut   *otlpBatchHandler
+		batch = func(recs ...[]byte) *Batch {
+			b := &Batch{}
+			for _, rec := range recs {
+				b.entries = append(b.entries, rec)
+				b.size += len(rec)
+				b.len++
+			}
+			return b
+		}
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				errs := []error{
+					sut.configure(otlpEncoding, OTLPJSON),
+					sut.configure(otlpEndpoint, "http://localhost"),
+					sut.configure(otlpHeaders, map[string]string{"X-Custom": "value"}),
+					sut.configure(otlpHTTPClient, client),
+					sut.configure(otlpResource, map[string]any{"a": 1}),
+					sut.configure(otlpResource, map[string]any{"b": 2}),
+					sut.configure(otlpRetry, retryConfig{3, time.Second}),
+					sut.configure(otlpTimeout, time.Minute),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.encoding).Equals(OTLPJSON)
+				test.That(t, sut.endpoint).Equals("http://localhost")
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+				test.Map(t, sut.resource).Equals(map[string]any{"a": 1, "b": 2})
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrOTLPConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// encode tests
+		{scenario: "encodeProtobuf",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.resource["service.name"] = "service"
+
+				// ACT
+				result := sut.encodeProtobuf(batch([]byte{0x09, 0x01}, []byte{0x09, 0x02}))
+
+				// ASSERT
+				rq := pbDecode(result)
+				test.That(t, len(rq), "resource logs").Equals(1)
+				rl := pbDecode(rq[0].bytes)
+				test.That(t, len(rl), "resource logs fields").Equals(2)
+
+				attrs := pbDecode(pbDecode(rl[0].bytes)[0].bytes)
+				test.That(t, string(attrs[0].bytes), "resource attribute").Equals("service.name")
+
+				sl := pbDecode(rl[1].bytes)
+				test.That(t, len(sl), "scope logs fields").Equals(3)
+				test.That(t, string(pbDecode(sl[0].bytes)[0].bytes), "scope").Equals(otlpScope)
+				test.That(t, sl[1].bytes, "record 1").Equals([]byte{0x09, 0x01})
+				test.That(t, sl[2].bytes, "record 2").Equals([]byte{0x09, 0x02})
+			},
+		},
+		{scenario: "encodeJSON",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.resource["service.name"] = "service"
+
+				// ACT
+				result, err := sut.encodeJSON(batch([]byte(`{"a":1}`), []byte(`{"b":2}`)))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(result)).Equals(`{"resourceLogs":[{` +
+					`"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"service"}}]},` +
+					`"scopeLogs":[{"logRecords":[{"a":1},{"b":2}],"scope":{"name":"github.com/blugnu/ulog"}}]` +
+					`}]}`)
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					encoding    OTLPEncodingType
+					contentType string
+					record      []byte
+				}{
+					{encoding: OTLPJSON, contentType: "application/json", record: []byte(`{}`)},
+					{encoding: OTLPProtobuf, contentType: "application/x-protobuf", record: []byte{0x09, 0x01}},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.contentType, func(t *testing.T) {
+						// ARRANGE
+						var (
+							contenttype string
+							body        []byte
+						)
+						srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							contenttype = r.Header.Get("Content-Type")
+							body, _ = io.ReadAll(r.Body)
+						}))
+						defer srv.Close()
+
+						sut.endpoint = srv.URL
+						sut.encoding = tc.encoding
+
+						// ACT
+						err := sut.Send(batch(tc.record))
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, contenttype).Equals(tc.contentType)
+						test.IsTrue(t, len(body) > 0)
+					})
+				}
+			},
+		},
+		{scenario: "send/encoding error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.encoding = OTLPJSON
+				sut.resource["nan"] = math.NaN()
+
+				// ACT
+				err := sut.Send(batch([]byte(`{}`)))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				calls := 0
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+				sut.setRetry(1, time.Millisecond)
+
+				// ACT
+				err := sut.Send(batch([]byte{0x09, 0x01}))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, calls).Equals(2)
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+				}))
+				defer srv.Close()
+				sut.endpoint = srv.URL
+
+				// ACT
+				err := sut.Send(batch([]byte{0x09, 0x01}))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/invalid endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.endpoint = "\n"
+
+				// ACT
+				err := sut.Send(batch([]byte{0x09, 0x01}))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newOTLPBatchHandler()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package ulog
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type OTLPOption = func(*otlp) error // OTLPOption is a function that configures an OTLP transport
+
+// OTLPTraceContextFunc is a function that returns the trace id and span id
+// of a span in a specified context.  If the context does not contain a
+// valid span the function returns ok == false.
+//
+// e.g. using the OpenTelemetry trace api:
+//
+//	func(ctx context.Context) ([16]byte, [8]byte, bool) {
+//	    sc := trace.SpanContextFromContext(ctx)
+//	    return sc.TraceID(), sc.SpanID(), sc.IsValid()
+//	}
+type OTLPTraceContextFunc = func(context.Context) (traceID [16]byte, spanID [8]byte, ok bool)
+
+// otlpScope is the name of the instrumentation scope of log records.
+const otlpScope = "github.com/blugnu/ulog"
+
+// otlpSeverityNumber is the OTLP SeverityNumber for each Level.
+var otlpSeverityNumber = [numLevels]uint64{
+	TraceLevel: 1,  // SEVERITY_NUMBER_TRACE
+	DebugLevel: 5,  // SEVERITY_NUMBER_DEBUG
+	InfoLevel:  9,  // SEVERITY_NUMBER_INFO
+	WarnLevel:  13, // SEVERITY_NUMBER_WARN
+	ErrorLevel: 17, // SEVERITY_NUMBER_ERROR
+	FatalLevel: 21, // SEVERITY_NUMBER_FATAL
+}
+
+// otlpSeverityText is the SeverityText for each Level.
+var otlpSeverityText = [numLevels]string{
+	TraceLevel: "TRACE",
+	DebugLevel: "DEBUG",
+	InfoLevel:  "INFO",
+	WarnLevel:  "WARN",
+	ErrorLevel: "ERROR",
+	FatalLevel: "FATAL",
+}
+
+// OTLPTransport returns a transport factory function to create and
+// configure a transport that exports log entries to an OpenTelemetry
+// collector (or other OTLP receiver) using OTLP/HTTP, with specified
+// configuration options applied.
+//
+// By default, requests are sent to http://localhost:4318/v1/logs using
+// the protobuf encoding; the endpoint and encoding may be configured using
+// OTLPEndpoint and OTLPEncoding.
+//
+// Each entry is exported as a LogRecord:
+//
+//   - the time of the entry is the Timestamp and ObservedTimestamp
+//   - the Level of the entry determines the SeverityNumber and SeverityText
+//   - the message of the entry is the Body
+//   - the fields of the entry are the Attributes
+//   - the TraceId and SpanId are obtained from the context of the entry
+//     (see: OTLPTraceContext)
+//
+// The target Formatter is not used.
+//
+// The Resource of the exported records identifies the service, with
+// a service.name of "unknown_service:<executable>" unless configured
+// using OTLPServiceName or OTLPResource.
+func OTLPTransport(opts ...OTLPOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newOTLPBatchHandler()
+		bh.endpoint = "http://localhost:4318/v1/logs"
+		bh.resource["service.name"] = "unknown_service:" + filepath.Base(os.Args[0])
+
+		t := &otlp{
+			batchTransport: batchTransport{
+				name:  "otlp",
+				ch:    make(chan []byte, 100),
+				batch: &Batch{maxLatency: 10 * time.Second},
+			},
+		}
+		t.batch.init(bh, 100)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if t.batch.oversize == TruncateOversizeEntries {
+			return nil, fmt.Errorf("%w: %w: TruncateOversizeEntries is not supported", ErrOTLPConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// otlp implements a transport that exports log entries using OTLP/HTTP.
+type otlp struct {
+	batchTransport
+	encoding     OTLPEncodingType     // the encoding of log records
+	traceContext OTLPTraceContextFunc // function returning the trace context of an entry; nil if not configured
+}
+
+// logEntry implements the entryTransport interface.  The entry is encoded
+// as a LogRecord which is sent to the transport channel.
+func (t *otlp) logEntry(e entry, _ func(entry) []byte) {
+	var (
+		traceID [16]byte
+		spanID  [8]byte
+		ok      bool
+	)
+	if t.traceContext != nil && e.logcontext != nil && e.ctx != nil {
+		traceID, spanID, ok = t.traceContext(e.ctx)
+	}
+
+	var attrs map[string]any
+	if e.logcontext != nil && e.fields != nil {
+		attrs = e.fields.m
+	}
+
+	switch t.encoding {
+	case OTLPJSON:
+		rec := otlpJSONRecord{
+			TimeUnixNano:         strconv.FormatInt(e.Time.UnixNano(), 10),
+			ObservedTimeUnixNano: strconv.FormatInt(e.Time.UnixNano(), 10),
+			SeverityNumber:       otlpSeverityNumber[e.Level],
+			SeverityText:         otlpSeverityText[e.Level],
+			Body:                 otlpJSONValue(e.Message),
+			Attributes:           otlpJSONAttributes(attrs),
+		}
+		if ok {
+			rec.TraceID = hex.EncodeToString(traceID[:])
+			rec.SpanID = hex.EncodeToString(spanID[:])
+		}
+		b, err := json.Marshal(rec)
+		if err != nil {
+			trace("otlp: entry discarded: " + err.Error())
+			return
+		}
+		t.ch <- b
+
+	default:
+		b := make([]byte, 0, 64+len(e.Message))
+		b = pbAppendFixed64(b, 1, uint64(e.Time.UnixNano()))
+		b = pbAppendVarint(b, 2, otlpSeverityNumber[e.Level])
+		b = pbAppendString(b, 3, otlpSeverityText[e.Level])
+		b = pbAppendBytes(b, 5, otlpAppendPBValue(nil, e.Message))
+		b = otlpAppendPBAttributes(b, 6, attrs)
+		if ok {
+			b = pbAppendBytes(b, 9, traceID[:])
+			b = pbAppendBytes(b, 10, spanID[:])
+		}
+		t.ch <- pbAppendFixed64(b, 11, uint64(e.Time.UnixNano()))
+	}
+}
+
+// otlpValue returns a value normalised for encoding as an OTLP AnyValue.
+// The returned value is nil or one of: string, bool, int64, float64,
+// []byte, []any or map[string]any.
+//
+// Errors and values implementing fmt.Stringer are converted to strings.
+// Unsigned integers greater than math.MaxInt64 (which cannot be represented
+// as an int64) are converted to decimal strings.  Structs are converted to maps (via their JSON representation) and any
+// other value that cannot be represented is converted to a string.
+func otlpValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int64, float64, []byte:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return strconv.FormatUint(u, 10)
+		}
+		return int64(u)
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Elem().Kind() != reflect.Struct {
+			return otlpValue(rv.Elem().Interface())
+		}
+		fallthrough
+	case reflect.Struct:
+		j, err := jsonMarshal(v)
+		if err != nil {
+			return fmt.Sprintf("OTLP_ERROR: marshalling error: %v", err)
+		}
+		var m any
+		_ = json.Unmarshal(j, &m)
+		return otlpValue(m)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		a := make([]any, rv.Len())
+		for i := range a {
+			a[i] = otlpValue(rv.Index(i).Interface())
+		}
+		return a
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		m := make(map[string]any, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			m[it.Key().String()] = otlpValue(it.Value().Interface())
+		}
+		return m
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// sortedKeys returns the keys of a map, sorted.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// otlpAppendPBValue appends the fields of an AnyValue message for a
+// specified value.  Unlike other protobuf fields, the (oneof) value is
+// encoded even if it is a zero value.
+//
+//	message AnyValue {
+//	  oneof value {
+//	    string string_value = 1;
+//	    bool bool_value = 2;
+//	    int64 int_value = 3;
+//	    double double_value = 4;
+//	    ArrayValue array_value = 5;
+//	    KeyValueList kvlist_value = 6;
+//	    bytes bytes_value = 7;
+//	  }
+//	}
+func otlpAppendPBValue(b []byte, v any) []byte {
+	appendLen := func(b []byte, field int, v []byte) []byte {
+		b = pbAppendTag(b, field, pbBytes)
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		return append(b, v...)
+	}
+
+	switch v := otlpValue(v).(type) {
+	case string:
+		return appendLen(b, 1, []byte(v))
+	case bool:
+		b = pbAppendTag(b, 2, pbVarint)
+		if v {
+			return append(b, 1)
+		}
+		return append(b, 0)
+	case int64:
+		return binary.AppendUvarint(pbAppendTag(b, 3, pbVarint), uint64(v))
+	case float64:
+		return binary.LittleEndian.AppendUint64(pbAppendTag(b, 4, pbFixed64), math.Float64bits(v))
+	case []any:
+		var av []byte
+		for _, e := range v {
+			av = appendLen(av, 1, otlpAppendPBValue(nil, e))
+		}
+		return appendLen(b, 5, av)
+	case map[string]any:
+		return appendLen(b, 6, otlpAppendPBAttributes(nil, 1, v))
+	case []byte:
+		return appendLen(b, 7, v)
+	}
+	return b
+}
+
+// otlpAppendPBAttributes appends a repeated KeyValue field for the entries
+// in a map, in order of key.
+//
+//	message KeyValue {
+//	  string key = 1;
+//	  AnyValue value = 2;
+//	}
+func otlpAppendPBAttributes(b []byte, field int, m map[string]any) []byte {
+	var kv []byte
+	for _, k := range sortedKeys(m) {
+		kv = pbAppendString(kv[:0], 1, k)
+		kv = pbAppendBytes(kv, 2, otlpAppendPBValue(nil, m[k]))
+		b = pbAppendTag(b, field, pbBytes)
+		b = binary.AppendUvarint(b, uint64(len(kv)))
+		b = append(b, kv...)
+	}
+	return b
+}
+
+// otlpJSONRecord is the JSON encoding of a LogRecord.
+type otlpJSONRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       uint64         `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 map[string]any `json:"body,omitempty"`
+	Attributes           []any          `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+// otlpJSONValue returns the JSON encoding of an AnyValue for a specified
+// value.  In the OTLP JSON encoding, int64 values are encoded as strings
+// and bytes values as base64 strings.
+func otlpJSONValue(v any) map[string]any {
+	switch v := otlpValue(v).(type) {
+	case string:
+		return map[string]any{"stringValue": v}
+	case bool:
+		return map[string]any{"boolValue": v}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return map[string]any{"doubleValue": "NaN"}
+		case math.IsInf(v, 1):
+			return map[string]any{"doubleValue": "Infinity"}
+		case math.IsInf(v, -1):
+			return map[string]any{"doubleValue": "-Infinity"}
+		}
+		return map[string]any{"doubleValue": v}
+	case []any:
+		values := make([]any, len(v))
+		for i, e := range v {
+			values[i] = otlpJSONValue(e)
+		}
+		return map[string]any{"arrayValue": map[string]any{"values": values}}
+	case map[string]any:
+		return map[string]any{"kvlistValue": map[string]any{"values": otlpJSONAttributes(v)}}
+	case []byte:
+		return map[string]any{"bytesValue": v}
+	}
+	return map[string]any{}
+}
+
+// otlpJSONAttributes returns the JSON encoding of a list of KeyValues for
+// the entries in a map, in order of key.
+func otlpJSONAttributes(m map[string]any) []any {
+	if len(m) == 0 {
+		return nil
+	}
+	attrs := make([]any, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		attrs = append(attrs, map[string]any{"key": k, "value": otlpJSONValue(m[k])})
+	}
+	return attrs
+}
@@ -0,0 +1,140 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// OTLPBatching applies batch options to configure the batching of log
+// entries sent by the transport.  By default a batch is sent when it
+// contains 100 entries or after 10 seconds.
+func OTLPBatching(opts ...BatchOption) OTLPOption {
+	return func(t *otlp) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// OTLPEncoding configures the encoding of requests.  The default is
+// OTLPProtobuf.
+func OTLPEncoding(enc OTLPEncodingType) OTLPOption {
+	return func(t *otlp) error {
+		switch enc {
+		case OTLPProtobuf, OTLPJSON:
+			t.encoding = enc
+			return t.batch.configure(otlpEncoding, enc)
+		default:
+			return fmt.Errorf("%w: OTLPEncoding: invalid encoding (%d)", ErrInvalidConfiguration, enc)
+		}
+	}
+}
+
+// OTLPEndpoint configures the url of the OTLP/HTTP receiver.  If the url
+// does not specify a path, the path of the logs api (/v1/logs) is used.
+// The default is http://localhost:4318/v1/logs.
+func OTLPEndpoint(s string) OTLPOption {
+	return func(t *otlp) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: OTLPEndpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: OTLPEndpoint: %q: scheme must be http or https", ErrInvalidConfiguration, s)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/v1/logs"
+		}
+		return t.batch.configure(otlpEndpoint, u.String())
+	}
+}
+
+// OTLPHeaders configures additional headers to be sent with each request,
+// e.g. to provide authentication required by the receiver.  This option
+// may be specified multiple times; headers are accumulated, with any
+// header specified more than once taking the most recently configured
+// value.
+func OTLPHeaders(h map[string]string) OTLPOption {
+	return func(t *otlp) error {
+		return t.batch.configure(otlpHeaders, h)
+	}
+}
+
+// OTLPHTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func OTLPHTTPClient(c *http.Client) OTLPOption {
+	return func(t *otlp) error {
+		if c == nil {
+			return fmt.Errorf("%w: OTLPHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(otlpHTTPClient, c)
+	}
+}
+
+// OTLPResource configures attributes of the Resource of exported log
+// records, e.g. service.version or deployment.environment.  This option
+// may be specified multiple times; attributes are accumulated, with any
+// attribute specified more than once taking the most recently configured
+// value.
+func OTLPResource(attrs map[string]any) OTLPOption {
+	return func(t *otlp) error {
+		return t.batch.configure(otlpResource, attrs)
+	}
+}
+
+// OTLPRetry configures the number of times a request is retried if it
+// fails with an error that may succeed if retried (e.g. a network error
+// or a 429 or 5xx response), and the delay before the first retry.  The
+// delay is doubled for each subsequent retry.
+//
+// By default requests are not retried; a batch that cannot be sent is
+// retained and sent again when next flushed.
+func OTLPRetry(n int, backoff time.Duration) OTLPOption {
+	return func(t *otlp) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: OTLPRetry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(otlpRetry, retryConfig{n, backoff})
+	}
+}
+
+// OTLPServiceName configures the service.name attribute of the Resource
+// of exported log records.
+func OTLPServiceName(s string) OTLPOption {
+	return func(t *otlp) error {
+		if s == "" {
+			return fmt.Errorf("%w: OTLPServiceName: name is required", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(otlpResource, map[string]any{"service.name": s})
+	}
+}
+
+// OTLPTimeout configures the timeout for requests.  The default is 5 seconds.
+func OTLPTimeout(d time.Duration) OTLPOption {
+	return func(t *otlp) error {
+		return t.batch.configure(otlpTimeout, d)
+	}
+}
+
+// OTLPTraceContext configures a function used to obtain the trace id and
+// span id of log records from the context of each entry.
+//
+// ulog does not depend on any tracing library; the function provides the
+// trace context using whichever library is used by the application (see:
+// OTLPTraceContextFunc).
+func OTLPTraceContext(fn OTLPTraceContextFunc) OTLPOption {
+	return func(t *otlp) error {
+		if fn == nil {
+			return fmt.Errorf("%w: OTLPTraceContext: function is nil", ErrInvalidConfiguration)
+		}
+		t.traceContext = fn
+		return nil
+	}
+}
@@ -0,0 +1,214 @@
+package ulog
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestOTLPTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		sut *otlp
+		bh  *otlpBatchHandler
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "OTLPBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPBatching(BatchMaxEntries(10), BatchMaxLatency(time.Minute))(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.batch.max).Equals(10)
+				test.That(t, sut.batch.maxLatency).Equals(time.Minute)
+			},
+		},
+		{scenario: "OTLPEncoding",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPEncoding(OTLPJSON)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.encoding, "transport").Equals(OTLPJSON)
+				test.That(t, bh.encoding, "handler").Equals(OTLPJSON)
+			},
+		},
+		{scenario: "OTLPEncoding/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPEncoding(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "OTLPEndpoint",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					url    string
+					result string
+				}{
+					{url: "http://collector:4318", result: "http://collector:4318/v1/logs"},
+					{url: "https://collector/", result: "https://collector/v1/logs"},
+					{url: "https://collector/custom/logs", result: "https://collector/custom/logs"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.url, func(t *testing.T) {
+						// ACT
+						err := OTLPEndpoint(tc.url)(sut)
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, bh.endpoint).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "OTLPEndpoint/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					OTLPEndpoint("\n")(sut),
+					OTLPEndpoint("grpc://collector:4317")(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "OTLPHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPHeaders(map[string]string{"Authorization": "Bearer token"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("Authorization")).Equals("Bearer token")
+			},
+		},
+		{scenario: "OTLPHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := OTLPHTTPClient(client)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "OTLPHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPHTTPClient(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "OTLPResource",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPResource(map[string]any{"service.version": "1.0"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.resource["service.version"]).Equals(any("1.0"))
+			},
+		},
+		{scenario: "OTLPRetry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPRetry(3, time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.retries).Equals(3)
+				test.That(t, bh.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "OTLPRetry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPRetry(-1, time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "OTLPServiceName",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPServiceName("service")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.resource["service.name"]).Equals(any("service"))
+			},
+		},
+		{scenario: "OTLPServiceName/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPServiceName("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "OTLPTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPTimeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "OTLPTraceContext",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				fn := func(context.Context) ([16]byte, [8]byte, bool) { return [16]byte{}, [8]byte{}, false }
+
+				// ACT
+				err := OTLPTraceContext(fn)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.traceContext).IsNotNil()
+			},
+		},
+		{scenario: "OTLPTraceContext/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := OTLPTraceContext(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newOTLPBatchHandler()
+			sut = &otlp{batchTransport: batchTransport{batch: &Batch{}}}
+			sut.batch.init(bh, 100)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package ulog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// stringer is a type implementing fmt.Stringer, for testing values that
+// are converted to strings.
+type stringer struct{}
+
+func (stringer) String() string { return "stringer" }
+
+func TestOTLPTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+	traceID := [16]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	spanID := [8]byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}
+
+	type ctxkey struct{}
+	traceContext := func(ctx context.Context) ([16]byte, [8]byte, bool) {
+		if ctx.Value(ctxkey{}) == nil {
+			return [16]byte{}, [8]byte{}, false
+		}
+		return traceID, spanID, true
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// OTLPTransport tests
+		{scenario: "OTLPTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*otlp) error { return opterr }
+
+				// ACT
+				result, err := OTLPTransport(opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "OTLPTransport/truncate not supported",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := OTLPTransport(OTLPBatching(BatchOversizeEntries(TruncateOversizeEntries)))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrOTLPConfiguration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "OTLPTransport/defaults",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := OTLPTransport()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*otlp](t, result); ok {
+					test.That(t, result.encoding, "encoding").Equals(OTLPProtobuf)
+					test.That(t, result.batch.max, "batch capacity").Equals(100)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(10 * time.Second)
+					if handler, ok := test.IsType[*otlpBatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.endpoint, "endpoint").Equals("http://localhost:4318/v1/logs")
+						test.Map(t, handler.resource, "resource").Equals(map[string]any{
+							"service.name": "unknown_service:" + filepath.Base(os.Args[0]),
+						})
+					}
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry/protobuf",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &otlp{
+					batchTransport: batchTransport{ch: make(chan []byte, 1)},
+					traceContext:   traceContext,
+				}
+				e := entry{
+					logcontext: &logcontext{
+						ctx:    context.WithValue(context.Background(), ctxkey{}, true),
+						fields: newFields(1).merge(map[string]any{"key": "value"}),
+					},
+					Time:    tm,
+					Level:   WarnLevel,
+					Message: "message",
+				}
+
+				// ACT
+				sut.logEntry(e, nil)
+
+				// ASSERT
+				fields := pbDecode(<-sut.ch)
+				test.That(t, len(fields), "fields").Equals(8)
+				test.That(t, fields[0].num, "time").Equals(1)
+				test.That(t, fields[0].varint, "time").Equals(uint64(tm.UnixNano()))
+				test.That(t, fields[1].varint, "severity number").Equals(uint64(13))
+				test.That(t, string(fields[2].bytes), "severity text").Equals("WARN")
+				test.That(t, fields[3].num, "body").Equals(5)
+				test.That(t, string(pbDecode(fields[3].bytes)[0].bytes), "body").Equals("message")
+				test.That(t, fields[4].num, "attribute").Equals(6)
+				kv := pbDecode(fields[4].bytes)
+				test.That(t, string(kv[0].bytes), "attribute key").Equals("key")
+				test.That(t, string(pbDecode(kv[1].bytes)[0].bytes), "attribute value").Equals("value")
+				test.That(t, fields[5].bytes, "trace id").Equals(traceID[:])
+				test.That(t, fields[6].bytes, "span id").Equals(spanID[:])
+				test.That(t, fields[7].num, "observed time").Equals(11)
+			},
+		},
+		{scenario: "logEntry/protobuf/no trace context",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &otlp{
+					batchTransport: batchTransport{ch: make(chan []byte, 1)},
+					traceContext:   traceContext,
+				}
+				e := entry{
+					logcontext: &logcontext{ctx: context.Background()},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				sut.logEntry(e, nil)
+
+				// ASSERT
+				nums := []int{}
+				for _, f := range pbDecode(<-sut.ch) {
+					nums = append(nums, f.num)
+				}
+				test.Slice(t, nums).Equals([]int{1, 2, 3, 5, 11})
+			},
+		},
+		{scenario: "logEntry/json",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &otlp{
+					batchTransport: batchTransport{ch: make(chan []byte, 1)},
+					encoding:       OTLPJSON,
+					traceContext:   traceContext,
+				}
+				e := entry{
+					logcontext: &logcontext{
+						ctx:    context.WithValue(context.Background(), ctxkey{}, true),
+						fields: newFields(1).merge(map[string]any{"count": 42}),
+					},
+					Time:    tm,
+					Level:   ErrorLevel,
+					Message: "message",
+				}
+
+				// ACT
+				sut.logEntry(e, nil)
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`{` +
+					`"timeUnixNano":"1283929565432100000",` +
+					`"observedTimeUnixNano":"1283929565432100000",` +
+					`"severityNumber":17,` +
+					`"severityText":"ERROR",` +
+					`"body":{"stringValue":"message"},` +
+					`"attributes":[{"key":"count","value":{"intValue":"42"}}],` +
+					`"traceId":"0102030405060708090a0b0c0d0e0f10",` +
+					`"spanId":"1112131415161718"` +
+					`}`)
+			},
+		},
+		{scenario: "logEntry/json/non-finite values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &otlp{
+					batchTransport: batchTransport{ch: make(chan []byte, 1)},
+					encoding:       OTLPJSON,
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"nan": math.NaN()})},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				sut.logEntry(e, nil)
+
+				// ASSERT
+				test.That(t, len(sut.ch)).Equals(1)
+				test.IsTrue(t, strings.Contains(string(<-sut.ch), `"attributes":[{"key":"nan","value":{"doubleValue":"NaN"}}]`))
+			},
+		},
+
+		// otlpValue tests
+		{scenario: "otlpValue",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				type named int
+				type st struct {
+					A int `json:"a"`
+				}
+				s := "s"
+				testcases := []struct {
+					name   string
+					value  any
+					result any
+				}{
+					{name: "nil", value: nil, result: nil},
+					{name: "string", value: "s", result: "s"},
+					{name: "bool", value: true, result: true},
+					{name: "int", value: 42, result: int64(42)},
+					{name: "named int", value: named(1), result: int64(1)},
+					{name: "uint", value: uint16(42), result: int64(42)},
+					{name: "max int64 uint", value: uint64(math.MaxInt64), result: int64(math.MaxInt64)},
+					{name: "out of range uint", value: uint64(math.MaxUint64), result: "18446744073709551615"},
+					{name: "float32", value: float32(1.5), result: 1.5},
+					{name: "bytes", value: []byte("b"), result: []byte("b")},
+					{name: "error", value: errors.New("failed"), result: "failed"},
+					{name: "stringer", value: stringer{}, result: "stringer"},
+					{name: "duration", value: time.Second, result: "1s"},
+					{name: "pointer", value: &s, result: "s"},
+					{name: "nil pointer", value: (*string)(nil), result: nil},
+					{name: "struct", value: st{A: 1}, result: map[string]any{"a": 1.0}},
+					{name: "struct pointer", value: &st{A: 2}, result: map[string]any{"a": 2.0}},
+					{name: "slice", value: []int{1, 2}, result: []any{int64(1), int64(2)}},
+					{name: "nil slice", value: []int(nil), result: nil},
+					{name: "array", value: [1]string{"a"}, result: []any{"a"}},
+					{name: "map", value: map[string]int{"a": 1}, result: map[string]any{"a": int64(1)}},
+					{name: "map with non-string keys", value: map[int]int{1: 1}, result: "map[1:1]"},
+					{name: "other", value: make(chan int), result: "<chan>"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := otlpValue(tc.value)
+
+						// ASSERT
+						if tc.name == "other" {
+							test.That(t, result.(string)[:2]).Equals("0x")
+							return
+						}
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "otlpValue/marshalling error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&jsonMarshal, func(v any) ([]byte, error) { return nil, errors.New("marshalling error") })()
+
+				// ACT
+				result := otlpValue(struct{}{})
+
+				// ASSERT
+				test.That(t, result).Equals("OTLP_ERROR: marshalling error: marshalling error")
+			},
+		},
+
+		// otlpAppendPBValue tests
+		{scenario: "otlpAppendPBValue",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name   string
+					value  any
+					result []byte
+				}{
+					{name: "empty string", value: "", result: []byte{0x0a, 0x00}},
+					{name: "false", value: false, result: []byte{0x10, 0x00}},
+					{name: "true", value: true, result: []byte{0x10, 0x01}},
+					{name: "zero", value: 0, result: []byte{0x18, 0x00}},
+					{name: "negative", value: -1, result: []byte{0x18, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+					{name: "double", value: 1.0, result: []byte{0x21, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f}},
+					{name: "array", value: []any{"a", nil}, result: []byte{0x2a, 0x07, 0x0a, 0x03, 0x0a, 0x01, 'a', 0x0a, 0x00}},
+					{name: "kvlist", value: map[string]any{"k": 1}, result: []byte{0x32, 0x09, 0x0a, 0x07, 0x0a, 0x01, 'k', 0x12, 0x02, 0x18, 0x01}},
+					{name: "bytes", value: []byte{0xff}, result: []byte{0x3a, 0x01, 0xff}},
+					{name: "nil", value: nil, result: nil},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := otlpAppendPBValue(nil, tc.value)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// otlpJSONValue tests
+		{scenario: "otlpJSONValue",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name   string
+					value  any
+					result string
+				}{
+					{name: "string", value: "s", result: `{"stringValue":"s"}`},
+					{name: "bool", value: false, result: `{"boolValue":false}`},
+					{name: "int", value: -1, result: `{"intValue":"-1"}`},
+					{name: "double", value: 1.5, result: `{"doubleValue":1.5}`},
+					{name: "NaN", value: math.NaN(), result: `{"doubleValue":"NaN"}`},
+					{name: "+Inf", value: math.Inf(1), result: `{"doubleValue":"Infinity"}`},
+					{name: "-Inf", value: math.Inf(-1), result: `{"doubleValue":"-Infinity"}`},
+					{name: "out of range uint", value: uint64(math.MaxUint64), result: `{"stringValue":"18446744073709551615"}`},
+					{name: "array", value: []any{"a", 1}, result: `{"arrayValue":{"values":[{"stringValue":"a"},{"intValue":"1"}]}}`},
+					{name: "kvlist", value: map[string]any{"k": true}, result: `{"kvlistValue":{"values":[{"key":"k","value":{"boolValue":true}}]}}`},
+					{name: "bytes", value: []byte("b"), result: `{"bytesValue":"Yg=="}`},
+					{name: "nil", value: nil, result: `{}`},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result, err := json.Marshal(otlpJSONValue(tc.value))
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, string(result)).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "exports to receiver",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					path        string
+					contentType string
+					body        []byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					path = r.URL.Path
+					contentType = r.Header.Get("Content-Type")
+					body, _ = io.ReadAll(r.Body)
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetTransport(OTLPTransport(
+								OTLPEndpoint(srv.URL),
+								OTLPEncoding(OTLPJSON),
+								OTLPServiceName("service"),
+								OTLPTraceContext(traceContext),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.WithContext(context.WithValue(context.Background(), ctxkey{}, true)).Info("message 1")
+				logger.WithField("key", "value").Warn("message 2")
+				closelog()
+
+				// ASSERT
+				rq := struct {
+					ResourceLogs []struct {
+						Resource struct {
+							Attributes []map[string]any `json:"attributes"`
+						} `json:"resource"`
+						ScopeLogs []struct {
+							LogRecords []map[string]any `json:"logRecords"`
+						} `json:"scopeLogs"`
+					} `json:"resourceLogs"`
+				}{}
+				test.Error(t, json.Unmarshal(body, &rq)).IsNil()
+				test.That(t, path).Equals("/v1/logs")
+				test.That(t, contentType).Equals("application/json")
+				test.That(t, rq.ResourceLogs[0].Resource.Attributes[0]["value"]).Equals(any(map[string]any{"stringValue": "service"}))
+				records := rq.ResourceLogs[0].ScopeLogs[0].LogRecords
+				test.That(t, len(records), "records").Equals(2)
+				test.That(t, records[0]["traceId"], "trace id").Equals(any("0102030405060708090a0b0c0d0e0f10"))
+				test.That(t, records[1]["traceId"], "trace id").IsNil()
+				test.That(t, records[1]["severityText"], "severity").Equals(any("WARN"))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
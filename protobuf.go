@@ -0,0 +1,85 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// protobuf wire types
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+	pbFixed32 = 5
+)
+
+// the functions in this file provide a minimal protocol buffers encoder,
+// sufficient for transports that send protobuf encoded requests without
+// introducing a dependency on a protobuf runtime and generated code.
+//
+// each function appends an encoded field to a byte slice and returns the
+// extended slice, in the manner of the append functions in the strconv
+// and encoding/binary packages.
+//
+// as required by proto3, fields with a zero value are omitted.
+
+// pbAppendTag appends a field tag with a specified field number and
+// wire type.
+func pbAppendTag(b []byte, field int, wt int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wt))
+}
+
+// pbAppendBytes appends a length-delimited field.  A nested message is
+// appended as a bytes field containing the encoded message.
+func pbAppendBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = pbAppendTag(b, field, pbBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// pbAppendString appends a string field.
+func pbAppendString(b []byte, field int, v string) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = pbAppendTag(b, field, pbBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// pbAppendVarint appends a varint field (int32, int64, uint32, uint64,
+// bool or enum).  Negative int32 and int64 values must be converted to
+// uint64 by the caller, yielding the 10-byte encoding of the value.
+func pbAppendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = pbAppendTag(b, field, pbVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// pbAppendFixed32 appends a fixed32 field.
+func pbAppendFixed32(b []byte, field int, v uint32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = pbAppendTag(b, field, pbFixed32)
+	return binary.LittleEndian.AppendUint32(b, v)
+}
+
+// pbAppendFixed64 appends a fixed64 field.
+func pbAppendFixed64(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = pbAppendTag(b, field, pbFixed64)
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+// pbAppendDouble appends a double field.
+func pbAppendDouble(b []byte, field int, v float64) []byte {
+	return pbAppendFixed64(b, field, math.Float64bits(v))
+}
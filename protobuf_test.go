@@ -0,0 +1,102 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestProtobuf(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "pbAppendTag",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendTag(nil, 1, pbBytes)
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x0a})
+			},
+		},
+		{scenario: "pbAppendBytes",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendBytes(nil, 2, []byte{0x01, 0x02})
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x12, 0x02, 0x01, 0x02})
+			},
+		},
+		{scenario: "pbAppendBytes/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendBytes([]byte{0xff}, 2, nil)
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0xff})
+			},
+		},
+		{scenario: "pbAppendString",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendString(nil, 1, "testing")
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x0a, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g'})
+			},
+		},
+		{scenario: "pbAppendVarint",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendVarint(nil, 1, 150)
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x08, 0x96, 0x01})
+			},
+		},
+		{scenario: "pbAppendVarint/zero",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendVarint(nil, 1, 0)
+
+				// ASSERT
+				test.That(t, len(result)).Equals(0)
+			},
+		},
+		{scenario: "pbAppendFixed32",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendFixed32(nil, 3, 1)
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x1d, 0x01, 0x00, 0x00, 0x00})
+			},
+		},
+		{scenario: "pbAppendFixed64",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendFixed64(nil, 1, 1)
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+			},
+		},
+		{scenario: "pbAppendDouble",
+			exec: func(t *testing.T) {
+				// ACT
+				result := pbAppendDouble(nil, 1, 1.0)
+
+				// ASSERT
+				test.That(t, result).Equals([]byte{0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x3f})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package ulog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	s3Bucket      = cfgkey("s3.bucket")
+	s3Compress    = cfgkey("s3.compress")
+	s3Credentials = cfgkey("s3.credentials")
+	s3Endpoint    = cfgkey("s3.endpoint")
+	s3HTTPClient  = cfgkey("s3.httpClient")
+	s3KeyTemplate = cfgkey("s3.keyTemplate")
+	s3Labels      = cfgkey("s3.labels")
+	s3PartSize    = cfgkey("s3.partSize")
+	s3Region      = cfgkey("s3.region")
+	s3Retry       = cfgkey("s3.retry")
+	s3Timeout     = cfgkey("s3.timeout")
+)
+
+// s3MinPartSize is the minimum size of a part of a multipart upload (other
+// than the last part).
+const s3MinPartSize = 5 * 1024 * 1024
+
+// s3DefaultKeyTemplate is the default template for the keys of objects.
+const s3DefaultKeyTemplate = `{{.Time.Format "2006/01/02/15"}}/{{.ID}}.ndjson{{if .Compressed}}.gz{{end}}`
+
+// errS3Response is returned when a response to an S3 request is successful
+// but does not contain the expected content (or contains an error).  The
+// request is not retried.
+var errS3Response = errors.New("s3: invalid response")
+
+// s3Creds holds the credentials used to sign requests, as supplied
+// to the configure method of an s3 batch handler.
+type s3Creds struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+// S3KeyData is the data available to the template of object keys.
+type S3KeyData struct {
+	Time       time.Time         // the time of the entries in the object (UTC)
+	ID         string            // an id that is unique to each upload, beginning with the time of the upload
+	Labels     map[string]string // static labels configured using S3Labels
+	Compressed bool              // true if the object is compressed
+}
+
+// s3BatchHandler is a batch handler that uploads batches of entries as
+// objects to an S3 compatible object store.
+type s3BatchHandler struct {
+	httpSender
+	sigv4
+	endpoint  *url.URL           // the url of the service
+	pathStyle bool               // true if the bucket is identified in the path of requests
+	bucket    string             // the name of the bucket
+	template  *template.Template // the template of object keys
+	labels    map[string]string  // static labels available to the template
+	compress  bool               // true if objects are compressed using gzip
+	partSize  int                // the size of the parts of a multipart upload
+}
+
+// s3Object is an object to be uploaded, identifying the indices of the
+// entries in the batch that comprise the object.
+type s3Object struct {
+	key     string
+	entries []int
+}
+
+// newS3BatchHandler creates a new, initialised s3 batch handler.
+func newS3BatchHandler() *s3BatchHandler {
+	h := &s3BatchHandler{
+		httpSender: newHTTPSender(),
+		sigv4:      sigv4{region: "us-east-1", service: "s3"},
+		template:   template.Must(template.New("key").Parse(s3DefaultKeyTemplate)),
+		labels:     map[string]string{},
+		compress:   true,
+		partSize:   8 * 1024 * 1024,
+	}
+	h.client.Timeout = 30 * time.Second
+	h.endpoint, _ = url.Parse("https://s3.us-east-1.amazonaws.com")
+	return h
+}
+
+// configure applies configuration to the s3 batch handler.
+func (h *s3BatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case s3Bucket:
+		h.bucket = value.(string)
+	case s3Compress:
+		h.compress = value.(bool)
+	case s3Credentials:
+		creds := value.(s3Creds)
+		h.accessKey = creds.accessKey
+		h.secretKey = creds.secretKey
+		h.sessionToken = creds.sessionToken
+	case s3Endpoint:
+		h.endpoint = value.(*url.URL)
+		h.pathStyle = true
+	case s3HTTPClient:
+		h.client = value.(*http.Client)
+	case s3KeyTemplate:
+		h.template = value.(*template.Template)
+	case s3Labels:
+		for k, v := range value.(map[string]string) {
+			h.labels[k] = v
+		}
+	case s3PartSize:
+		h.partSize = value.(int)
+	case s3Region:
+		h.region = value.(string)
+		if !h.pathStyle {
+			h.endpoint.Host = "s3." + h.region + ".amazonaws.com"
+		}
+	case s3Retry:
+		cfg := value.(retryConfig)
+		h.setRetry(cfg.n, cfg.backoff)
+	case s3Timeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrS3Configuration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// newS3ObjectID returns a new id for an upload, comprising the time of the
+// upload and a random suffix.
+func newS3ObjectID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// objects groups the entries in a batch into objects according to the
+// key for each entry, in the order in which each key first occurs in the
+// batch.
+func (h *s3BatchHandler) objects(batch *Batch, id string) ([]*s3Object, error) {
+	objects := []*s3Object{}
+	idx := map[string]*s3Object{}
+	data := S3KeyData{ID: id, Labels: h.labels, Compressed: h.compress}
+	sb := &strings.Builder{}
+	for i, rec := range batch.entries {
+		data.Time = time.Unix(0, int64(binary.BigEndian.Uint64(rec))).UTC()
+
+		sb.Reset()
+		if err := h.template.Execute(sb, data); err != nil {
+			return nil, err
+		}
+		key := strings.TrimPrefix(sb.String(), "/")
+
+		obj, ok := idx[key]
+		if !ok {
+			obj = &s3Object{key: key}
+			idx[key] = obj
+			objects = append(objects, obj)
+		}
+		obj.entries = append(obj.entries, i)
+	}
+	return objects, nil
+}
+
+// body returns the content of an object: the entries of the object, each
+// terminated by a newline, compressed if configured.
+func (h *s3BatchHandler) body(batch *Batch, obj *s3Object) []byte {
+	buf := &bytes.Buffer{}
+	for _, i := range obj.entries {
+		_, _ = buf.Write(batch.entries[i][8:])
+		_ = buf.WriteByte('\n')
+	}
+	if !h.compress {
+		return buf.Bytes()
+	}
+
+	gz := &bytes.Buffer{}
+	w := gzip.NewWriter(gz)
+	_, _ = w.Write(buf.Bytes())
+	_ = w.Close()
+	return gz.Bytes()
+}
+
+// url returns the url of an object with specified query parameters.
+func (h *s3BatchHandler) url(key string, query string) string {
+	u := *h.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if h.pathStyle {
+		path += "/" + sigv4Escape(h.bucket, false)
+	} else {
+		u.Host = h.bucket + "." + u.Host
+	}
+	s := u.Scheme + "://" + u.Host + path + "/" + sigv4Escape(key, true)
+	if query != "" {
+		s += "?" + query
+	}
+	return s
+}
+
+// request sends a signed request, returning the body and headers of the
+// response.
+func (h *s3BatchHandler) request(method, url string, body []byte, contentType string) ([]byte, http.Header, error) {
+	rq, err := h.newRequest(method, url, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if contentType != "" {
+		rq.Header.Set("Content-Type", contentType)
+	}
+	h.sign(rq, sigv4Hash(body), now())
+	return h.doResponse(rq)
+}
+
+// contentType returns the content type of objects.
+func (h *s3BatchHandler) contentType() string {
+	if h.compress {
+		return "application/gzip"
+	}
+	return "application/x-ndjson"
+}
+
+// put uploads an object in a single request.
+func (h *s3BatchHandler) put(key string, body []byte) error {
+	_, _, err := h.request(http.MethodPut, h.url(key, ""), body, h.contentType())
+	return err
+}
+
+// multipart uploads an object in parts.  If the upload fails after it has
+// been initiated, the upload is aborted.
+func (h *s3BatchHandler) multipart(key string, body []byte) error {
+	resp, _, err := h.request(http.MethodPost, h.url(key, "uploads="), nil, h.contentType())
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(resp, &initiated); err != nil || initiated.UploadID == "" {
+		return fmt.Errorf("%w: no upload id", errS3Response)
+	}
+	uploadID := url.QueryEscape(initiated.UploadID)
+
+	type part struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	complete := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+
+	upload := func() error {
+		for n, off := 1, 0; off < len(body); n, off = n+1, off+h.partSize {
+			end := min(off+h.partSize, len(body))
+			query := "partNumber=" + strconv.Itoa(n) + "&uploadId=" + uploadID
+			_, hdr, err := h.request(http.MethodPut, h.url(key, query), body[off:end], "")
+			if err != nil {
+				return err
+			}
+			complete.Parts = append(complete.Parts, part{PartNumber: n, ETag: hdr.Get("ETag")})
+		}
+
+		b, _ := xml.Marshal(complete)
+		resp, _, err := h.request(http.MethodPost, h.url(key, "uploadId="+uploadID), b, "application/xml")
+		if err != nil {
+			return err
+		}
+		// a complete request may fail after a 200 OK response has been sent,
+		// with the error in the body of the response
+		if bytes.Contains(resp, []byte("<Error>")) {
+			return fmt.Errorf("%w: complete multipart upload failed", errS3Response)
+		}
+		return nil
+	}
+
+	if err := upload(); err != nil {
+		if _, _, abortErr := h.request(http.MethodDelete, h.url(key, "uploadId="+uploadID), nil, ""); abortErr != nil {
+			trace("s3: send: error aborting multipart upload: " + abortErr.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// headerLen implements the recordHandler interface, returning the length
+// of the time preceding the formatted entry of a record.
+func (h *s3BatchHandler) headerLen([]byte) int {
+	return 8
+}
+
+// Send uploads a batch of entries.  The entries are grouped into objects
+// according to the key derived for each entry, with each object uploaded
+// separately.  An object larger than the part size is uploaded using a
+// multipart upload.
+//
+// If the upload of an object fails with an error that indicates that the
+// upload may succeed if retried (e.g. a network error, 429 or 5xx), the
+// entries of that object and of any objects not yet uploaded are retained
+// and an error is returned.  The entries of an object that fails for any
+// other reason are discarded.
+func (h *s3BatchHandler) Send(batch *Batch) error {
+	tracef("s3: send: sending %d entries", batch.len)
+
+	objects, err := h.objects(batch, newS3ObjectID())
+	if err != nil {
+		trace("s3: send: error executing key template: " + err.Error())
+		tracef("s3: send: %d entries discarded", batch.len)
+		return nil
+	}
+
+	for i, obj := range objects {
+		body := h.body(batch, obj)
+
+		upload := h.put
+		if len(body) > h.partSize {
+			upload = h.multipart
+		}
+		err := upload(obj.key, body)
+		if err == nil {
+			continue
+		}
+
+		trace("s3: send: error uploading " + obj.key + ": " + err.Error())
+		if isRetryable(err) && !errors.Is(err, errS3Response) {
+			retain := []int{}
+			for _, obj := range objects[i:] {
+				retain = append(retain, obj.entries...)
+			}
+			slices.Sort(retain)
+			batch.Retain(retain)
+			return err
+		}
+		tracef("s3: send: %d entries discarded", len(obj.entries))
+	}
+	return nil
+}
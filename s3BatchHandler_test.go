@@ -0,0 +1,535 @@
+package ulog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// fakeS3 is a minimal S3 compatible server supporting PutObject and
+// multipart uploads, for path-style requests.  The signature of each
+// request is verified.
+type fakeS3 struct {
+	sync.Mutex
+	*httptest.Server
+	signer   sigv4
+	objects  map[string][]byte         // objects, by "bucket/key"
+	uploads  map[string]map[int][]byte // parts of multipart uploads, by upload id
+	requests []string                  // "METHOD path?query" of each request received
+	fail     func(r *http.Request) int // optional function returning a status with which to fail a request; 0 = no failure
+	aborted  int                       // the number of aborted multipart uploads
+}
+
+func newFakeS3() *fakeS3 {
+	s := &fakeS3{
+		signer:  sigv4{accessKey: "access", secretKey: "secret", region: "us-east-1", service: "s3"},
+		objects: map[string][]byte{},
+		uploads: map[string]map[int][]byte{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// verify returns true if the Authorization header of a request is that
+// obtained by signing the request with the signed headers identified in
+// the Authorization header.
+func (s *fakeS3) verify(r *http.Request, body []byte) bool {
+	auth := r.Header.Get("Authorization")
+	_, signed, ok := strings.Cut(auth, "SignedHeaders=")
+	if !ok {
+		return false
+	}
+	signed, _, _ = strings.Cut(signed, ",")
+
+	rq, _ := http.NewRequest(r.Method, "http://"+r.Host+r.RequestURI, nil)
+	for _, k := range strings.Split(signed, ";") {
+		if k != "host" {
+			rq.Header.Set(k, r.Header.Get(k))
+		}
+	}
+	tm, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	s.signer.sign(rq, sigv4Hash(body), tm)
+	return rq.Header.Get("Authorization") == auth && r.Header.Get("X-Amz-Content-Sha256") == sigv4Hash(body)
+}
+
+func (s *fakeS3) handle(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+
+	if !s.verify(r, body) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if s.fail != nil {
+		if status := s.fail(r); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	id := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPut && id != "":
+		n := 0
+		_, _ = fmt.Sscan(query.Get("partNumber"), &n)
+		s.uploads[id][n] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, n))
+
+	case r.Method == http.MethodPut:
+		s.objects[path] = body
+
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(s.uploads)+1)
+		s.uploads[id] = map[int][]byte{}
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+
+	case r.Method == http.MethodPost && id != "":
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		_ = xml.Unmarshal(body, &complete)
+		obj := []byte{}
+		for _, p := range complete.Parts {
+			if p.ETag != fmt.Sprintf(`"etag-%d"`, p.PartNumber) {
+				_, _ = w.Write([]byte("<Error><Code>InvalidPart</Code></Error>"))
+				return
+			}
+			obj = append(obj, s.uploads[id][p.PartNumber]...)
+		}
+		delete(s.uploads, id)
+		s.objects[path] = obj
+		_, _ = w.Write([]byte("<CompleteMultipartUploadResult/>"))
+
+	case r.Method == http.MethodDelete && id != "":
+		delete(s.uploads, id)
+		s.aborted++
+	}
+}
+
+// gunzip returns the decompressed content of a gzip compressed object.
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	content, _ := io.ReadAll(r)
+	return string(content)
+}
+
+func TestS3BatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut *s3BatchHandler
+		srv *fakeS3
+		tm  = time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+		rec = func(tm time.Time, s string) []byte {
+			b := binary.BigEndian.AppendUint64(nil, uint64(tm.UnixNano()))
+			return append(b, s...)
+		}
+		batch = func(recs ...[]byte) *Batch {
+			b := &Batch{}
+			for _, rec := range recs {
+				b.entries = append(b.entries, rec)
+				b.size += len(rec)
+				b.len++
+			}
+			return b
+		}
+	)
+	defer test.Using(&now, func() time.Time { return tm })()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// headerLen tests
+		{scenario: "headerLen/truncated record",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &Batch{max: 10, maxEntryBytes: 12, oversize: TruncateOversizeEntries, handler: sut}
+
+				// ACT
+				b.Add(rec(tm, "entry text"))
+
+				// ASSERT
+				test.That(t, sut.headerLen(nil)).Equals(8)
+				test.That(t, b.entries[0]).Equals(rec(tm, "entr"))
+			},
+		},
+		{scenario: "headerLen/limit less than header",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := &Batch{max: 10, maxEntryBytes: 4, oversize: TruncateOversizeEntries, handler: sut}
+
+				// ACT
+				b.Add(rec(tm, "entry"))
+
+				// ASSERT
+				test.That(t, b.len).Equals(0)
+			},
+		},
+
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+				endpoint, _ := url.Parse("http://localhost:9000")
+				tmpl := template.Must(template.New("key").Parse("key"))
+
+				// ACT
+				errs := []error{
+					sut.configure(s3Region, "eu-west-1"),
+					sut.configure(s3Bucket, "bucket"),
+					sut.configure(s3Compress, false),
+					sut.configure(s3Credentials, s3Creds{"access", "secret", "token"}),
+					sut.configure(s3Endpoint, endpoint),
+					sut.configure(s3HTTPClient, client),
+					sut.configure(s3KeyTemplate, tmpl),
+					sut.configure(s3Labels, map[string]string{"a": "1"}),
+					sut.configure(s3Labels, map[string]string{"b": "2"}),
+					sut.configure(s3PartSize, 1024),
+					sut.configure(s3Retry, retryConfig{3, time.Second}),
+					sut.configure(s3Timeout, time.Minute),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.region).Equals("eu-west-1")
+				test.That(t, sut.bucket).Equals("bucket")
+				test.IsFalse(t, sut.compress)
+				test.That(t, sut.accessKey).Equals("access")
+				test.That(t, sut.secretKey).Equals("secret")
+				test.That(t, sut.sessionToken).Equals("token")
+				test.That(t, sut.endpoint.String()).Equals("http://localhost:9000")
+				test.IsTrue(t, sut.pathStyle)
+				test.That(t, sut.template).Equals(tmpl)
+				test.Map(t, sut.labels).Equals(map[string]string{"a": "1", "b": "2"})
+				test.That(t, sut.partSize).Equals(1024)
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrS3Configuration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// url tests
+		{scenario: "url/virtual-hosted style",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut = newS3BatchHandler()
+				_ = sut.configure(s3Bucket, "bucket")
+				_ = sut.configure(s3Region, "eu-west-1")
+
+				// ACT
+				result := sut.url("a/b c.log", "uploads=")
+
+				// ASSERT
+				test.That(t, result).Equals("https://bucket.s3.eu-west-1.amazonaws.com/a/b%20c.log?uploads=")
+			},
+		},
+		{scenario: "url/path style",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				endpoint, _ := url.Parse("http://localhost:9000/")
+				_ = sut.configure(s3Bucket, "bucket")
+				_ = sut.configure(s3Endpoint, endpoint)
+
+				// ACT
+				result := sut.url("a/b.log", "")
+
+				// ASSERT
+				test.That(t, result).Equals("http://localhost:9000/bucket/a/b.log")
+			},
+		},
+
+		// objects tests
+		{scenario: "objects",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`/{{.Labels.env}}/{{.Time.Format "15"}}/{{.ID}}`)))
+				_ = sut.configure(s3Labels, map[string]string{"env": "prod"})
+				b := batch(
+					rec(tm, "one"),
+					rec(tm.Add(time.Hour), "two"),
+					rec(tm, "three"),
+				)
+
+				// ACT
+				result, err := sut.objects(b, "id")
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, result).Equals([]*s3Object{
+					{key: "prod/07/id", entries: []int{0, 2}},
+					{key: "prod/08/id", entries: []int{1}},
+				})
+			},
+		},
+		{scenario: "objects/template error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`{{.Time.Foo}}`)))
+
+				// ACT
+				_, err := sut.objects(batch(rec(tm, "one")), "id")
+
+				// ASSERT
+				test.IsTrue(t, err != nil)
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b := batch(
+					rec(tm, `{"message":"one"}`),
+					rec(tm.Add(time.Hour), `{"message":"two"}`),
+					rec(tm, `{"message":"three"}`),
+				)
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, len(srv.objects)).Equals(2)
+
+				keys := []string{}
+				for k := range srv.objects {
+					keys = append(keys, k)
+				}
+				key := func(hour string) string {
+					for _, k := range keys {
+						if strings.HasPrefix(k, "bucket/2010/09/08/"+hour+"/20100908T070605Z-") && strings.HasSuffix(k, ".ndjson.gz") {
+							return k
+						}
+					}
+					t.Fatalf("no object for hour %s in %v", hour, keys)
+					return ""
+				}
+				test.That(t, gunzip(t, srv.objects[key("07")])).Equals("{\"message\":\"one\"}\n{\"message\":\"three\"}\n")
+				test.That(t, gunzip(t, srv.objects[key("08")])).Equals("{\"message\":\"two\"}\n")
+			},
+		},
+		{scenario: "send/uncompressed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3Compress, false)
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`{{.Time.Format "2006"}}.log`)))
+
+				// ACT
+				err := sut.Send(batch(rec(tm, "one"), rec(tm, "two")))
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, string(srv.objects["bucket/2010.log"])).Equals("one\ntwo\n")
+			},
+		},
+		{scenario: "send/multipart",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3Compress, false)
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`object`)))
+				sut.partSize = 4
+
+				// ACT
+				err := sut.Send(batch(rec(tm, "one"), rec(tm, "two"), rec(tm, "three")))
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, string(srv.objects["bucket/object"])).Equals("one\ntwo\nthree\n")
+				test.Slice(t, srv.requests).Equals([]string{
+					"POST /bucket/object?uploads=",
+					"PUT /bucket/object?partNumber=1&uploadId=upload-1",
+					"PUT /bucket/object?partNumber=2&uploadId=upload-1",
+					"PUT /bucket/object?partNumber=3&uploadId=upload-1",
+					"PUT /bucket/object?partNumber=4&uploadId=upload-1",
+					"POST /bucket/object?uploadId=upload-1",
+				})
+			},
+		},
+		{scenario: "send/multipart/part fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3Compress, false)
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`object`)))
+				sut.partSize = 4
+				srv.fail = func(r *http.Request) int {
+					if r.URL.Query().Get("partNumber") == "2" {
+						return http.StatusServiceUnavailable
+					}
+					return 0
+				}
+				b := batch(rec(tm, "one"), rec(tm, "two"))
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, srv.aborted).Equals(1)
+				test.That(t, b.len).Equals(2)
+				test.That(t, len(srv.objects)).Equals(0)
+			},
+		},
+		{scenario: "send/multipart/complete fails",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3Compress, false)
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`object`)))
+				sut.partSize = 4
+				srv.fail = func(r *http.Request) int {
+					if r.Method == http.MethodPut && r.URL.Query().Get("partNumber") == "1" {
+						// corrupt the etag of the part by responding without one
+						return http.StatusOK
+					}
+					return 0
+				}
+				b := batch(rec(tm, "one"), rec(tm, "two"))
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, srv.aborted).Equals(1)
+				test.That(t, len(srv.objects)).Equals(0)
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`{{.Time.Format "15"}}`)))
+				srv.fail = func(r *http.Request) int {
+					if r.URL.Path == "/bucket/08" {
+						return http.StatusServiceUnavailable
+					}
+					return 0
+				}
+				b := batch(
+					rec(tm, "one"),
+					rec(tm.Add(time.Hour), "two"),
+					rec(tm, "three"),
+					rec(tm.Add(2*time.Hour), "four"),
+				)
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+				test.That(t, len(srv.objects)).Equals(1)
+				retained := []string{}
+				for _, e := range b.entries {
+					retained = append(retained, string(e[8:]))
+				}
+				test.Slice(t, retained).Equals([]string{"two", "four"})
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`{{.Time.Format "15"}}`)))
+				srv.fail = func(r *http.Request) int {
+					if r.URL.Path == "/bucket/07" {
+						return http.StatusBadRequest
+					}
+					return 0
+				}
+				b := batch(rec(tm, "one"), rec(tm.Add(time.Hour), "two"))
+
+				// ACT
+				var err error
+				_, stderr := test.CaptureOutput(t, func() {
+					err = sut.Send(b)
+				})
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, len(srv.objects)).Equals(1)
+				test.IsTrue(t, len(srv.objects["bucket/08"]) > 0)
+				stderr.Contains("s3: send: 1 entries discarded")
+			},
+		},
+		{scenario: "send/template error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3KeyTemplate, template.Must(template.New("key").Parse(`{{.Time.Foo}}`)))
+
+				// ACT
+				err := sut.Send(batch(rec(tm, "one")))
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, len(srv.requests)).Equals(0)
+			},
+		},
+		{scenario: "send/invalid signature",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = sut.configure(s3Credentials, s3Creds{"access", "wrong", ""})
+				b := batch(rec(tm, "one"))
+
+				// ACT
+				err := sut.Send(b)
+
+				// ASSERT
+				test.That(t, err).IsNil()
+				test.That(t, len(srv.objects)).Equals(0)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			srv = newFakeS3()
+			defer srv.Close()
+
+			endpoint, _ := url.Parse(srv.URL)
+			sut = newS3BatchHandler()
+			_ = sut.configure(s3Bucket, "bucket")
+			_ = sut.configure(s3Credentials, s3Creds{"access", "secret", ""})
+			_ = sut.configure(s3Endpoint, endpoint)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
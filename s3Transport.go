@@ -0,0 +1,90 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+type S3Option = func(*s3) error // S3Option is a function that configures an S3 transport
+
+// S3Transport returns a transport factory function to create and configure
+// a transport that archives log entries as objects in a bucket of an S3
+// compatible object store, with specified configuration options applied.
+//
+// Entries are formatted by the target Formatter and accumulated in a batch.
+// When the batch is sent, entries are grouped into objects according to a
+// key derived from the time of each entry (see: S3KeyTemplate); each object
+// contains the entries for that key, newline delimited (e.g. NDJSON, when
+// using a JSON formatter) and, by default, gzip compressed.  An object that
+// is larger than the part size (see: S3PartSize) is uploaded using a
+// multipart upload.
+//
+// By default a batch is sent when it contains 10000 entries, 16 MiB of
+// formatted entries or after 5 minutes; these limits determine the size of
+// the objects that are created and may be configured using S3Batching.
+// With the default key template, objects are created in "hourly" prefixes,
+// e.g.:
+//
+//	2006/01/02/15/20060102T150607Z-0a1b2c3d.ndjson.gz
+//
+// Requests are signed using AWS Signature Version 4.  By default, requests
+// are sent to the AWS S3 endpoint for the region (see: S3Region) using
+// credentials from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables.  Any other S3 compatible store
+// may be used by configuring an endpoint (see: S3Endpoint).
+func S3Transport(bucket string, opts ...S3Option) TransportFactory {
+	return func() (transport, error) {
+		if bucket == "" {
+			return nil, fmt.Errorf("%w: %w: bucket is required", ErrS3Configuration, ErrInvalidConfiguration)
+		}
+
+		bh := newS3BatchHandler()
+		bh.bucket = bucket
+		bh.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		bh.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		bh.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+
+		t := &s3{
+			batchTransport: batchTransport{
+				name:  "s3",
+				ch:    make(chan []byte, 100),
+				batch: &Batch{maxBytes: 16 * 1024 * 1024, maxLatency: 5 * time.Minute},
+			},
+		}
+		t.batch.init(bh, 10000)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if bh.accessKey == "" || bh.secretKey == "" {
+			return nil, fmt.Errorf("%w: %w: credentials are required", ErrS3Configuration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// s3 implements a transport that archives log entries in an S3 compatible
+// object store.
+type s3 struct {
+	batchTransport
+}
+
+// logEntry implements the entryTransport interface.  The formatted entry
+// is sent to the transport channel prefixed with the time of the entry
+// (as 8 bytes of big-endian unix nanoseconds), used to derive the key of
+// the object to which the entry is written.
+func (t *s3) logEntry(e entry, format func(entry) []byte) {
+	b := format(e)
+	rec := make([]byte, 8, 8+len(b))
+	binary.BigEndian.PutUint64(rec, uint64(e.Time.UnixNano()))
+	t.ch <- append(rec, b...)
+}
@@ -0,0 +1,172 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+)
+
+// S3Batching applies batch options to configure the batching of log
+// entries sent by the transport.  By default a batch is sent when it
+// contains 10000 entries, 16 MiB of formatted entries or after 5 minutes.
+func S3Batching(opts ...BatchOption) S3Option {
+	return func(t *s3) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// S3Compress configures whether objects are gzip compressed.  The default
+// is true.
+func S3Compress(compress bool) S3Option {
+	return func(t *s3) error {
+		return t.batch.configure(s3Compress, compress)
+	}
+}
+
+// S3Credentials configures the credentials used to sign requests.  The
+// secret key may be specified as the name of an environment variable or
+// file from which to read the key (see: LogtailSourceToken).  A session
+// token is only required with temporary credentials and may be "".
+//
+// By default, credentials are read from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+func S3Credentials(accessKey, secretKey, sessionToken string) S3Option {
+	return func(t *s3) error {
+		if accessKey == "" || secretKey == "" {
+			return fmt.Errorf("%w: S3Credentials: access key and secret key are required", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(s3Credentials, s3Creds{
+			accessKey:    accessKey,
+			secretKey:    readSecret(secretKey),
+			sessionToken: sessionToken,
+		})
+	}
+}
+
+// S3Endpoint configures the url of an S3 compatible service, e.g.
+// http://localhost:9000 for a local MinIO server.  When an endpoint is
+// configured, the bucket is identified in the path of each request
+// ("path-style" requests).
+//
+// By default requests are sent to the AWS S3 endpoint for the region
+// (https://s3.<region>.amazonaws.com), with the bucket identified in the
+// host name ("virtual-hosted-style" requests).
+func S3Endpoint(s string) S3Option {
+	return func(t *s3) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: S3Endpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: S3Endpoint: %q: must be an http or https url", ErrInvalidConfiguration, s)
+		}
+		return t.batch.configure(s3Endpoint, u)
+	}
+}
+
+// S3HTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 30 seconds and all other
+// settings left at their defaults.
+func S3HTTPClient(c *http.Client) S3Option {
+	return func(t *s3) error {
+		if c == nil {
+			return fmt.Errorf("%w: S3HTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(s3HTTPClient, c)
+	}
+}
+
+// S3KeyTemplate configures the text/template used to derive the key of
+// the object to which each entry is written.  The template is executed
+// with an S3KeyData value.  Entries with the same key in a batch are
+// written to the same object; the ID is unique to each batch, so that
+// objects written by different batches (or processes) do not overwrite
+// each other.
+//
+// The default template is:
+//
+//	{{.Time.Format "2006/01/02/15"}}/{{.ID}}.ndjson{{if .Compressed}}.gz{{end}}
+//
+// e.g. to include labels and a daily prefix:
+//
+//	{{.Labels.service}}/{{.Time.Format "2006-01-02"}}/{{.ID}}.log.gz
+//
+// The template is validated when the option is applied.
+func S3KeyTemplate(s string) S3Option {
+	return func(t *s3) error {
+		tmpl, err := template.New("key").Option("missingkey=zero").Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: S3KeyTemplate: %w", ErrInvalidConfiguration, err)
+		}
+		if err := tmpl.Execute(io.Discard, S3KeyData{Labels: map[string]string{}}); err != nil {
+			return fmt.Errorf("%w: S3KeyTemplate: %w", ErrInvalidConfiguration, err)
+		}
+		return t.batch.configure(s3KeyTemplate, tmpl)
+	}
+}
+
+// S3Labels configures static labels available to the key template (see:
+// S3KeyTemplate).  This option may be specified multiple times; labels are
+// accumulated, with any label specified more than once taking the most
+// recently configured value.
+func S3Labels(labels map[string]string) S3Option {
+	return func(t *s3) error {
+		return t.batch.configure(s3Labels, labels)
+	}
+}
+
+// S3PartSize configures the size of the parts of a multipart upload.  An
+// object larger than the part size is uploaded in parts of this size.  The
+// part size must be at least 5 MiB; the default is 8 MiB.
+func S3PartSize(n int) S3Option {
+	return func(t *s3) error {
+		if n < s3MinPartSize {
+			return fmt.Errorf("%w: S3PartSize: %d: must be >= %d", ErrInvalidConfiguration, n, s3MinPartSize)
+		}
+		return t.batch.configure(s3PartSize, n)
+	}
+}
+
+// S3Region configures the region of the bucket, used to sign requests and
+// (if no endpoint is configured) to identify the AWS S3 endpoint.  The
+// default is us-east-1.
+func S3Region(s string) S3Option {
+	return func(t *s3) error {
+		if s == "" {
+			return fmt.Errorf("%w: S3Region: region is required", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(s3Region, s)
+	}
+}
+
+// S3Retry configures the number of times a request is retried if it fails
+// with an error that may succeed if retried (e.g. a network error or a 429
+// or 5xx response), and the delay before the first retry.  The delay is
+// doubled for each subsequent retry.
+//
+// By default requests are not retried; entries that could not be uploaded
+// are retained and sent again when the batch is next flushed.
+func S3Retry(n int, backoff time.Duration) S3Option {
+	return func(t *s3) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: S3Retry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(s3Retry, retryConfig{n, backoff})
+	}
+}
+
+// S3Timeout configures the timeout for requests.  The default is 30 seconds.
+func S3Timeout(d time.Duration) S3Option {
+	return func(t *s3) error {
+		return t.batch.configure(s3Timeout, d)
+	}
+}
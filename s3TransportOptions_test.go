@@ -0,0 +1,241 @@
+package ulog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestS3TransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		sut *s3
+		bh  *s3BatchHandler
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "S3Batching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Batching(BatchMaxEntries(10), BatchMaxLatency(time.Minute))(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.batch.max).Equals(10)
+				test.That(t, sut.batch.maxLatency).Equals(time.Minute)
+			},
+		},
+		{scenario: "S3Compress",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Compress(false)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, bh.compress)
+			},
+		},
+		{scenario: "S3Credentials",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				t.Setenv("S3_SECRET", "secret")
+
+				// ACT
+				err := S3Credentials("access", "S3_SECRET", "token")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.accessKey).Equals("access")
+				test.That(t, bh.secretKey).Equals("secret")
+				test.That(t, bh.sessionToken).Equals("token")
+			},
+		},
+		{scenario: "S3Credentials/missing",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Credentials("access", "", "")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3Endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Endpoint("http://localhost:9000")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint.String()).Equals("http://localhost:9000")
+				test.IsTrue(t, bh.pathStyle)
+			},
+		},
+		{scenario: "S3Endpoint/invalid",
+			exec: func(t *testing.T) {
+				for _, s := range []string{"localhost:9000", "ftp://localhost", "http://[::1"} {
+					t.Run(s, func(t *testing.T) {
+						// ACT
+						err := S3Endpoint(s)(sut)
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidConfiguration)
+					})
+				}
+			},
+		},
+		{scenario: "S3HTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := S3HTTPClient(client)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "S3HTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3HTTPClient(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3KeyTemplate",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3KeyTemplate(`{{.Labels.env}}/{{.Time.Format "2006"}}/{{.ID}}`)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.template.Root.String()).Equals(`{{.Labels.env}}/{{.Time.Format "2006"}}/{{.ID}}`)
+			},
+		},
+		{scenario: "S3KeyTemplate/invalid",
+			exec: func(t *testing.T) {
+				for _, s := range []string{`{{.ID`, `{{.Unknown}}`} {
+					t.Run(s, func(t *testing.T) {
+						// ACT
+						err := S3KeyTemplate(s)(sut)
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidConfiguration)
+					})
+				}
+			},
+		},
+		{scenario: "S3Labels",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Labels(map[string]string{"env": "prod"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Map(t, bh.labels).Equals(map[string]string{"env": "prod"})
+			},
+		},
+		{scenario: "S3PartSize",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3PartSize(16 * 1024 * 1024)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.partSize).Equals(16 * 1024 * 1024)
+			},
+		},
+		{scenario: "S3PartSize/too small",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3PartSize(1024)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3Region",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Region("eu-west-1")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.region).Equals("eu-west-1")
+				test.That(t, bh.endpoint.Host).Equals("s3.eu-west-1.amazonaws.com")
+			},
+		},
+		{scenario: "S3Region/with endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				_ = S3Endpoint("http://localhost:9000")(sut)
+
+				// ACT
+				err := S3Region("eu-west-1")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.region).Equals("eu-west-1")
+				test.That(t, bh.endpoint.Host).Equals("localhost:9000")
+			},
+		},
+		{scenario: "S3Region/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Region("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3Retry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Retry(3, time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.retries).Equals(3)
+				test.That(t, bh.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "S3Retry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Retry(-1, time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3Timeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := S3Timeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newS3BatchHandler()
+			sut = &s3{batchTransport: batchTransport{batch: &Batch{}}}
+			sut.batch.init(bh, 100)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
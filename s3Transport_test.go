@@ -0,0 +1,156 @@
+package ulog
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestS3Transport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// S3Transport tests
+		{scenario: "S3Transport/no bucket",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := S3Transport("")()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrS3Configuration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3Transport/no credentials",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				t.Setenv("AWS_ACCESS_KEY_ID", "")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+				// ACT
+				result, err := S3Transport("bucket")()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrS3Configuration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "S3Transport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*s3) error { return opterr }
+
+				// ACT
+				result, err := S3Transport("bucket", opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "S3Transport/defaults",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				t.Setenv("AWS_ACCESS_KEY_ID", "access")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+				t.Setenv("AWS_SESSION_TOKEN", "token")
+
+				// ACT
+				result, err := S3Transport("bucket")()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*s3](t, result); ok {
+					test.That(t, result.batch.max, "batch capacity").Equals(10000)
+					test.That(t, result.batch.maxBytes, "max bytes").Equals(16 * 1024 * 1024)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(5 * time.Minute)
+					if handler, ok := test.IsType[*s3BatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.bucket, "bucket").Equals("bucket")
+						test.That(t, handler.accessKey, "access key").Equals("access")
+						test.That(t, handler.secretKey, "secret key").Equals("secret")
+						test.That(t, handler.sessionToken, "session token").Equals("token")
+						test.That(t, handler.endpoint.String(), "endpoint").Equals("https://s3.us-east-1.amazonaws.com")
+						test.IsFalse(t, handler.pathStyle, "path style")
+						test.IsTrue(t, handler.compress, "compress")
+						test.That(t, handler.partSize, "part size").Equals(8 * 1024 * 1024)
+					}
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &s3{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				format := func(entry) []byte { return []byte("formatted") }
+
+				// ACT
+				sut.logEntry(entry{Time: tm}, format)
+
+				// ASSERT
+				rec := <-sut.ch
+				test.That(t, int64(binary.BigEndian.Uint64(rec)), "time").Equals(tm.UnixNano())
+				test.That(t, string(rec[8:]), "entry").Equals("formatted")
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "uploads to fake s3",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := newFakeS3()
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetTransport(S3Transport("logs",
+								S3Endpoint(srv.URL),
+								S3Credentials("access", "secret", ""),
+								S3Compress(false),
+								S3Labels(map[string]string{"service": "svc"}),
+								S3KeyTemplate(`{{.Labels.service}}/{{.Time.Format "2006-01-02"}}/{{.ID}}.log`),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("message 1")
+				logger.Warn("message 2")
+				closelog()
+
+				// ASSERT
+				test.That(t, len(srv.objects)).Equals(1)
+				for key, obj := range srv.objects {
+					test.IsTrue(t, strings.HasPrefix(key, "logs/svc/"), "key")
+					test.IsTrue(t, strings.HasSuffix(key, ".log"), "key")
+					lines := strings.Split(strings.TrimSuffix(string(obj), "\n"), "\n")
+					test.That(t, len(lines), "lines").Equals(2)
+					test.IsTrue(t, strings.Contains(lines[0], "message 1"), "line 1")
+					test.IsTrue(t, strings.Contains(lines[1], "message 2"), "line 2")
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
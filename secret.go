@@ -0,0 +1,28 @@
+package ulog
+
+import "os"
+
+// readSecret returns the value of a secret (e.g. an api key or token)
+// identified by a specified string, which may be:
+//
+//   - the name of an environment variable holding the secret value
+//   - the name of a file containing the secret value (and ONLY the secret
+//     value)
+//   - the secret value itself (not recommended, to avoid leaking secrets
+//     in source)
+func readSecret(s string) string {
+	// if s identifies an environment variable, the secret is
+	// the value of that variable
+	if v, ok := os.LookupEnv(s); ok {
+		return v
+	}
+
+	// if s identifies a file we can read from, the secret is
+	// the contents of that file
+	if b, err := os.ReadFile(s); err == nil {
+		return string(b)
+	}
+
+	// otherwise, s is the secret
+	return s
+}
@@ -0,0 +1,53 @@
+package ulog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestReadSecret(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "literal",
+			exec: func(t *testing.T) {
+				// ACT
+				result := readSecret("literal_token")
+
+				// ASSERT
+				test.That(t, result).Equals("literal_token")
+			},
+		},
+		{scenario: "from environment",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				os.Setenv("TEST_TOKEN", "envtoken")
+				defer os.Unsetenv("TEST_TOKEN")
+
+				// ACT
+				result := readSecret("TEST_TOKEN")
+
+				// ASSERT
+				test.That(t, result).Equals("envtoken")
+			},
+		},
+		{scenario: "from file",
+			exec: func(t *testing.T) {
+				// ACT
+				result := readSecret("testdata/token.txt")
+
+				// ASSERT
+				test.That(t, result).Equals("file_token")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package ulog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sigv4EmptyHash is the hex encoded SHA-256 hash of an empty payload.
+const sigv4EmptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sigv4 signs requests using AWS Signature Version 4.
+type sigv4 struct {
+	accessKey    string // the access key id
+	secretKey    string // the secret access key
+	sessionToken string // the session token of temporary credentials; "" if not used
+	region       string // the region of the service
+	service      string // the name of the service (e.g. "s3")
+}
+
+// sigv4Hash returns the hex encoded SHA-256 hash of specified data.
+func sigv4Hash(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// sigv4HMAC returns the HMAC-SHA256 of specified data using a specified key.
+func sigv4HMAC(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// sigv4Escape returns a string URI encoded as required by Signature
+// Version 4: every byte other than an unreserved character (A-Z, a-z,
+// 0-9, '-', '.', '_' and '~') is percent encoded, except '/' if the
+// string is a path.
+func sigv4Escape(s string, path bool) string {
+	sb := &strings.Builder{}
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', path && c == '/':
+			_ = sb.WriteByte(c)
+		default:
+			_ = sb.WriteByte('%')
+			_ = sb.WriteByte("0123456789ABCDEF"[c>>4])
+			_ = sb.WriteByte("0123456789ABCDEF"[c&15])
+		}
+	}
+	return sb.String()
+}
+
+// canonicalQuery returns the canonical query string of a url: the query
+// parameters sorted by name (and value), each name and value encoded and
+// joined by '='.
+func (s *sigv4) canonicalQuery(u *url.URL) string {
+	params := []string{}
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			params = append(params, sigv4Escape(k, false)+"="+sigv4Escape(v, false))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// sign signs a request with a specified payload hash at a specified time,
+// setting the X-Amz-Date, X-Amz-Content-Sha256 (and X-Amz-Security-Token,
+// if required) and Authorization headers.
+//
+// The host and all headers of the request (other than Authorization and
+// User-Agent) are signed; any headers must therefore be set before the
+// request is signed.
+func (s *sigv4) sign(rq *http.Request, payloadHash string, tm time.Time) {
+	tm = tm.UTC()
+	amzdate := tm.Format("20060102T150405Z")
+	date := tm.Format("20060102")
+
+	rq.Header.Set("X-Amz-Date", amzdate)
+	rq.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		rq.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	headers := map[string]string{"host": rq.URL.Host}
+	for k, v := range rq.Header {
+		k = strings.ToLower(k)
+		if k == "authorization" || k == "user-agent" {
+			continue
+		}
+		headers[k] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	canonical := &strings.Builder{}
+	_, _ = canonical.WriteString(rq.Method + "\n")
+	_, _ = canonical.WriteString(rq.URL.EscapedPath() + "\n")
+	_, _ = canonical.WriteString(s.canonicalQuery(rq.URL) + "\n")
+	for _, k := range names {
+		_, _ = canonical.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	_, _ = canonical.WriteString("\n" + signed + "\n" + payloadHash)
+
+	scope := date + "/" + s.region + "/" + s.service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzdate + "\n" + scope + "\n" + sigv4Hash([]byte(canonical.String()))
+
+	key := sigv4HMAC([]byte("AWS4"+s.secretKey), date)
+	key = sigv4HMAC(key, s.region)
+	key = sigv4HMAC(key, s.service)
+	key = sigv4HMAC(key, "aws4_request")
+	signature := hex.EncodeToString(sigv4HMAC(key, stringToSign))
+
+	rq.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+", SignedHeaders="+signed+", Signature="+signature)
+}
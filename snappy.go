@@ -0,0 +1,88 @@
+package ulog
+
+import (
+	"encoding/binary"
+)
+
+// snappyEncode returns the snappy block encoding of src.
+//
+// This is a simple, greedy implementation of the snappy block format
+// (https://github.com/google/snappy/blob/main/format_description.txt)
+// sufficient for compressing request bodies without introducing a
+// dependency.  It trades some compression ratio for simplicity
+// compared with the reference implementation, but the output is valid
+// for any snappy decoder.
+func snappyEncode(src []byte) []byte {
+	const (
+		tableBits = 14
+		minMatch  = 4
+		maxOffset = 1<<16 - 1
+	)
+
+	dst := make([]byte, 0, len(src)/2+16)
+	dst = binary.AppendUvarint(dst, uint64(len(src)))
+
+	load := func(i int) uint32 { return binary.LittleEndian.Uint32(src[i:]) }
+	hash := func(u uint32) uint32 { return (u * 0x1e35a7bd) >> (32 - tableBits) }
+
+	// table holds (1 + the position) of the most recent occurrence of each
+	// hashed 4-byte sequence; 0 indicates no occurrence
+	var table [1 << tableBits]int
+
+	lit := 0 // start of the pending literal
+	for s := 0; s+minMatch <= len(src); {
+		u := load(s)
+		h := hash(u)
+		cand := table[h] - 1
+		table[h] = s + 1
+
+		if cand < 0 || s-cand > maxOffset || load(cand) != u {
+			s++
+			continue
+		}
+
+		n := minMatch
+		for s+n < len(src) && src[cand+n] == src[s+n] {
+			n++
+		}
+		dst = snappyLiteral(dst, src[lit:s])
+		dst = snappyCopy(dst, s-cand, n)
+		s += n
+		lit = s
+	}
+	return snappyLiteral(dst, src[lit:])
+}
+
+// snappyLiteral appends a literal element to dst.
+func snappyLiteral(dst []byte, lit []byte) []byte {
+	if len(lit) == 0 {
+		return dst
+	}
+
+	n := uint32(len(lit) - 1)
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2)
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, lit...)
+}
+
+// snappyCopy appends copy elements to dst for a match of a specified
+// length at a specified offset, using copies with a 2-byte offset
+// (each copying at most 64 bytes).
+func snappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := min(length, 64)
+		dst = append(dst, byte(n-1)<<2|2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
@@ -0,0 +1,99 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+// snappyDecode decodes a snappy block, for verifying the output of
+// snappyEncode.
+func snappyDecode(src []byte) ([]byte, error) {
+	n, i := binary.Uvarint(src)
+	if i <= 0 {
+		return nil, errors.New("invalid length")
+	}
+	dst := make([]byte, 0, n)
+	for i < len(src) {
+		tag := src[i]
+		switch tag & 0x03 {
+		case 0:
+			ln := int(tag >> 2)
+			i++
+			if ln >= 60 {
+				nb := ln - 59
+				ln = 0
+				for j := 0; j < nb; j++ {
+					ln |= int(src[i+j]) << (8 * j)
+				}
+				i += nb
+			}
+			ln++
+			dst = append(dst, src[i:i+ln]...)
+			i += ln
+		case 2:
+			ln := int(tag>>2) + 1
+			offset := int(src[i+1]) | int(src[i+2])<<8
+			i += 3
+			if offset == 0 || offset > len(dst) {
+				return nil, errors.New("invalid offset")
+			}
+			for j := 0; j < ln; j++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, errors.New("unsupported tag")
+		}
+	}
+	if uint64(len(dst)) != n {
+		return nil, errors.New("length mismatch")
+	}
+	return dst, nil
+}
+
+func TestSnappyEncode(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		src      []byte
+	}{
+		{scenario: "empty", src: []byte{}},
+		{scenario: "short", src: []byte("abc")},
+		{scenario: "no repetition", src: []byte("the quick brown fox jumps over the lazy dog")},
+		{scenario: "repetition", src: []byte(strings.Repeat(`{"level":"info","message":"hello"}`, 100))},
+		{scenario: "long run", src: bytes.Repeat([]byte{'x'}, 1000)},
+		{scenario: "long literal", src: func() []byte {
+			b := make([]byte, 70000)
+			for i := range b {
+				b[i] = byte(i*7 + i/251)
+			}
+			return b
+		}()},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ACT
+			result := snappyEncode(tc.src)
+
+			// ASSERT
+			decoded, err := snappyDecode(result)
+			test.Error(t, err).IsNil()
+			test.That(t, decoded).Equals(tc.src)
+		})
+	}
+
+	t.Run("compresses repetitive input", func(t *testing.T) {
+		// ARRANGE
+		src := []byte(strings.Repeat(`{"level":"info","message":"hello"}`, 100))
+
+		// ACT
+		result := snappyEncode(src)
+
+		// ASSERT
+		test.IsTrue(t, len(result) < len(src)/10)
+	})
+}
@@ -0,0 +1,216 @@
+package ulog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	splunkAck           = cfgkey("splunk.ack")
+	splunkAuthorization = cfgkey("splunk.authorization")
+	splunkEndpoint      = cfgkey("splunk.endpoint")
+	splunkHeaders       = cfgkey("splunk.headers")
+	splunkHTTPClient    = cfgkey("splunk.httpClient")
+	splunkRetry         = cfgkey("splunk.retry")
+	splunkTimeout       = cfgkey("splunk.timeout")
+)
+
+// splunkAckConfig holds the indexer acknowledgement configuration of a
+// splunk batch handler.
+type splunkAckConfig struct {
+	channel string
+	timeout time.Duration
+}
+
+// splunkBatchHandler is a batch handler that sends batches of events to
+// a Splunk HTTP Event Collector.
+type splunkBatchHandler struct {
+	httpSender
+	endpoint      string
+	authorization string
+	channel       string        // the acknowledgement channel; if empty, acknowledgement is not requested
+	ackEndpoint   string        // the url of the acknowledgement endpoint
+	ackTimeout    time.Duration // the maximum time to wait for acknowledgement of a request
+	ackInterval   time.Duration // the interval at which acknowledgement status is polled
+}
+
+// splunkResponse is the response to a request sent to the HEC event or
+// ack endpoint.
+type splunkResponse struct {
+	Text  string          `json:"text"`
+	Code  int             `json:"code"`
+	AckId *int64          `json:"ackId"`
+	Acks  map[string]bool `json:"acks"`
+}
+
+// newSplunkBatchHandler creates a new, initialised splunk batch handler.
+func newSplunkBatchHandler() *splunkBatchHandler {
+	return &splunkBatchHandler{
+		httpSender: newHTTPSender(),
+	}
+}
+
+// newSplunkChannel returns a new, random acknowledgement channel id (a
+// version 4 UUID).
+func newSplunkChannel() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// splunkAckEndpoint returns the url of the acknowledgement endpoint
+// corresponding to a specified event endpoint, replacing a trailing /event
+// or /raw path segment with /ack (or appending /ack to any other path) so
+// that any prefix of the path (e.g. added by a proxy) is preserved.  If the
+// endpoint has no path, the default path (/services/collector/ack) is used.
+func splunkAckEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == "" {
+		path = "/services/collector"
+	}
+	for _, s := range []string{"/event", "/raw"} {
+		if strings.HasSuffix(path, s) {
+			path = strings.TrimSuffix(path, s)
+			break
+		}
+	}
+	u.Path = path + "/ack"
+	return u.String()
+}
+
+// configure applies configuration to the splunk batch handler.
+func (h *splunkBatchHandler) configure(key cfgkey, value any) error {
+	switch key {
+	case splunkAck:
+		cfg := value.(splunkAckConfig)
+		h.channel = cfg.channel
+		h.ackTimeout = cfg.timeout
+		h.ackInterval = min(time.Second, cfg.timeout/10)
+	case splunkAuthorization:
+		h.authorization = value.(string)
+	case splunkEndpoint:
+		h.endpoint = value.(string)
+		h.ackEndpoint = splunkAckEndpoint(h.endpoint)
+	case splunkHeaders:
+		h.setHeaders(value.(map[string]string))
+	case splunkHTTPClient:
+		h.client = value.(*http.Client)
+	case splunkRetry:
+		cfg := value.(retryConfig)
+		h.setRetry(cfg.n, cfg.backoff)
+	case splunkTimeout:
+		h.setTimeout(value.(time.Duration))
+	default:
+		return fmt.Errorf("%w: %w: %s", ErrSplunkConfiguration, ErrKeyNotSupported, key)
+	}
+	return nil
+}
+
+// post sends a request with a specified body to a specified url,
+// returning the decoded response.  A response body that cannot be
+// decoded results in an empty response.
+func (h *splunkBatchHandler) post(url string, body []byte) (*splunkResponse, error) {
+	rq, err := h.newRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	rq.Header.Set("Authorization", h.authorization)
+	rq.Header.Set("Content-Type", "application/json")
+	if h.channel != "" {
+		rq.Header.Set("X-Splunk-Request-Channel", h.channel)
+	}
+
+	body, err = h.do(rq)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &splunkResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		trace("splunk: error decoding response: " + err.Error())
+	}
+	return response, nil
+}
+
+// awaitAck polls the acknowledgement endpoint until a request with a
+// specified ack id is acknowledged or the acknowledgement timeout
+// expires.  The polling blocks the calling (transport) goroutine.
+func (h *splunkBatchHandler) awaitAck(id int64) error {
+	body := []byte(`{"acks":[` + strconv.FormatInt(id, 10) + `]}`)
+	key := strconv.FormatInt(id, 10)
+
+	deadline := time.Now().Add(h.ackTimeout)
+	for {
+		time.Sleep(h.ackInterval)
+
+		response, err := h.post(h.ackEndpoint, body)
+		if err != nil {
+			return err
+		}
+		if response.Acks[key] {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: request not acknowledged (ackId %d)", ErrUnexpectedResponse, id)
+		}
+	}
+}
+
+// Send sends a batch of events to the HTTP Event Collector.  The events
+// in the batch are concatenated in the body of a single request.
+//
+// If the request fails or the response has a status indicating that
+// the request may succeed if sent again (e.g. 429 or 503), an error is
+// returned and the batch is retained.  Any other unsuccessful response
+// results in the batch being discarded.
+//
+// If an acknowledgement channel is configured, the acknowledgement
+// endpoint is polled until the request is acknowledged.  If the request
+// is not acknowledged before the acknowledgement timeout expires (or the
+// acknowledgement endpoint fails with a retryable error), an error is
+// returned and the batch is retained (to be sent again), so that
+// events are delivered at least once.
+func (h *splunkBatchHandler) Send(batch *Batch) error {
+	tracef("splunk: send: sending %d events", batch.len)
+
+	body := bytes.Join(batch.entries, buf.newline)
+
+	response, err := h.post(h.endpoint, body)
+	if err != nil {
+		trace("splunk: send: error sending request: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("splunk: send: %d events discarded", batch.len)
+		return nil
+	}
+
+	if h.channel == "" {
+		return nil
+	}
+	if response.AckId == nil {
+		trace("splunk: send: no ackId in response")
+		return nil
+	}
+	if err := h.awaitAck(*response.AckId); err != nil {
+		trace("splunk: send: error awaiting acknowledgement: " + err.Error())
+		if isRetryable(err) {
+			return err
+		}
+		tracef("splunk: send: acknowledgement of %d events not confirmed", batch.len)
+	}
+	return nil
+}
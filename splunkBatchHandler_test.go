@@ -0,0 +1,283 @@
+package ulog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestSplunkBatchHandler(t *testing.T) {
+	// ARRANGE
+	var (
+		sut   *splunkBatchHandler
+		batch = func(events ...string) *Batch {
+			b := &Batch{}
+			for _, e := range events {
+				b.entries = append(b.entries, []byte(e))
+				b.size += len(e)
+				b.len++
+			}
+			return b
+		}
+	)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// configure tests
+		{scenario: "configure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				errs := []error{
+					sut.configure(splunkAck, splunkAckConfig{"channel", 5 * time.Second}),
+					sut.configure(splunkAuthorization, "Splunk token"),
+					sut.configure(splunkEndpoint, "http://localhost:8088/services/collector/event"),
+					sut.configure(splunkHeaders, map[string]string{"X-Custom": "value"}),
+					sut.configure(splunkHTTPClient, client),
+					sut.configure(splunkRetry, retryConfig{3, time.Second}),
+					sut.configure(splunkTimeout, time.Minute),
+				}
+
+				// ASSERT
+				test.Slice(t, errs).Equals([]error{nil, nil, nil, nil, nil, nil, nil})
+				test.That(t, sut.channel).Equals("channel")
+				test.That(t, sut.ackTimeout).Equals(5 * time.Second)
+				test.That(t, sut.ackInterval).Equals(500 * time.Millisecond)
+				test.That(t, sut.authorization).Equals("Splunk token")
+				test.That(t, sut.endpoint).Equals("http://localhost:8088/services/collector/event")
+				test.That(t, sut.ackEndpoint).Equals("http://localhost:8088/services/collector/ack")
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "configure/unknown key",
+			exec: func(t *testing.T) {
+				// ACT
+				err := sut.configure("unknown", "not used")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrSplunkConfiguration)
+				test.Error(t, err).Is(ErrKeyNotSupported)
+			},
+		},
+
+		// splunkAckEndpoint tests
+		{scenario: "splunkAckEndpoint",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					endpoint string
+					result   string
+				}{
+					{endpoint: "http://localhost:8088/services/collector/event", result: "http://localhost:8088/services/collector/ack"},
+					{endpoint: "http://localhost:8088/services/collector/raw", result: "http://localhost:8088/services/collector/ack"},
+					{endpoint: "http://localhost:8088/services/collector", result: "http://localhost:8088/services/collector/ack"},
+					{endpoint: "http://localhost:8088/services/collector/event/", result: "http://localhost:8088/services/collector/ack"},
+					{endpoint: "https://proxy.example.com/splunk/services/collector/event", result: "https://proxy.example.com/splunk/services/collector/ack"},
+					{endpoint: "https://proxy.example.com/hec/raw?channel=abc", result: "https://proxy.example.com/hec/ack?channel=abc"},
+					{endpoint: "http://localhost:8088", result: "http://localhost:8088/services/collector/ack"},
+					{endpoint: "://invalid", result: ""},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.endpoint, func(t *testing.T) {
+						// ACT
+						result := splunkAckEndpoint(tc.endpoint)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// newSplunkChannel tests
+		{scenario: "newSplunkChannel",
+			exec: func(t *testing.T) {
+				// ACT
+				result := newSplunkChannel()
+
+				// ASSERT
+				test.IsTrue(t, splunkChannelId.MatchString(result))
+				test.That(t, result[14:15], "version").Equals("4")
+			},
+		},
+
+		// send tests
+		{scenario: "send",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					authheader string
+					channel    string
+					body       []byte
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authheader = r.Header.Get("Authorization")
+					channel = r.Header.Get("X-Splunk-Request-Channel")
+					body, _ = io.ReadAll(r.Body)
+					_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL)
+				sut.authorization = "Splunk token"
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`, `{"event":2}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, authheader).Equals("Splunk token")
+				test.That(t, channel).Equals("")
+				test.That(t, string(body)).Equals(`{"event":1}` + "\n" + `{"event":2}`)
+			},
+		},
+		{scenario: "send/acknowledged",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var ackbody []byte
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/services/collector/ack" {
+						ackbody, _ = io.ReadAll(r.Body)
+						_, _ = w.Write([]byte(`{"acks":{"42":true}}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":42}`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL+"/services/collector/event")
+				_ = sut.configure(splunkAck, splunkAckConfig{"channel", 50 * time.Millisecond})
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, string(ackbody)).Equals(`{"acks":[42]}`)
+			},
+		},
+		{scenario: "send/not acknowledged",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/services/collector/ack" {
+						_, _ = w.Write([]byte(`{"acks":{"42":false}}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":42}`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL+"/services/collector/event")
+				_ = sut.configure(splunkAck, splunkAckConfig{"channel", 20 * time.Millisecond})
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+			},
+		},
+		{scenario: "send/acknowledgement disabled for token",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/services/collector/ack" {
+						w.WriteHeader(http.StatusBadRequest)
+						_, _ = w.Write([]byte(`{"text":"ACK is disabled","code":14}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":42}`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL)
+				_ = sut.configure(splunkAck, splunkAckConfig{"channel", 20 * time.Millisecond})
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/no ackId in response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`not json`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL)
+				_ = sut.configure(splunkAck, splunkAckConfig{"channel", 20 * time.Millisecond})
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					_, _ = w.Write([]byte(`{"text":"Server is busy","code":9}`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL)
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.Error(t, err).Is(ErrUnexpectedResponse)
+			},
+		},
+		{scenario: "send/non-retryable status",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"text":"Invalid data format","code":6}`))
+				}))
+				defer srv.Close()
+				_ = sut.configure(splunkEndpoint, srv.URL)
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+			},
+		},
+		{scenario: "send/invalid endpoint",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.endpoint = "\n"
+
+				// ACT
+				err := sut.Send(batch(`{"event":1}`))
+
+				// ASSERT
+				test.That(t, err).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = newSplunkBatchHandler()
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
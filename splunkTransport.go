@@ -0,0 +1,138 @@
+package ulog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type SplunkOption = func(*splunk) error // SplunkOption is a function that configures a splunk transport
+
+// SplunkMetadataId identifies an item of metadata in a Splunk HEC event.
+type SplunkMetadataId int
+
+const (
+	SplunkHost       SplunkMetadataId = iota // SplunkHost identifies the host of an event
+	SplunkIndex                              // SplunkIndex identifies the index to which an event is written
+	SplunkSource                             // SplunkSource identifies the source of an event
+	SplunkSourcetype                         // SplunkSourcetype identifies the sourcetype of an event
+	numSplunkMetadata
+)
+
+// SplunkTransport returns a transport factory function to create and
+// configure a transport that sends log entries to a Splunk HTTP Event
+// Collector (HEC), with specified configuration options applied.
+//
+// An endpoint and token must be configured using the SplunkEndpoint and
+// SplunkToken options.
+//
+// Each entry is formatted by the target Formatter to provide the event in
+// the HEC event envelope.  If the formatted entry is valid JSON (e.g. when
+// using the JSONFormatter) it is sent as a JSON event, otherwise it is sent
+// as a string.  The time of the event is the time of the entry.
+func SplunkTransport(opts ...SplunkOption) TransportFactory {
+	return func() (transport, error) {
+		bh := newSplunkBatchHandler()
+
+		t := &splunk{
+			batchTransport: batchTransport{
+				name:  "splunk",
+				ch:    make(chan []byte, 100),
+				batch: &Batch{maxLatency: 10 * time.Second},
+			},
+		}
+		t.batch.init(bh, 100)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		bh.applyTimeout()
+
+		if t.batch.oversize == TruncateOversizeEntries {
+			return nil, fmt.Errorf("%w: %w: TruncateOversizeEntries is not supported", ErrSplunkConfiguration, ErrInvalidConfiguration)
+		}
+		if bh.endpoint == "" {
+			return nil, fmt.Errorf("%w: %w: no endpoint configured", ErrSplunkConfiguration, ErrInvalidConfiguration)
+		}
+		if bh.authorization == "" {
+			return nil, fmt.Errorf("%w: %w: no token configured", ErrSplunkConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// splunk implements a transport that sends log entries to a Splunk HTTP
+// Event Collector.
+type splunk struct {
+	batchTransport
+	static [numSplunkMetadata]string // static metadata values
+	fields [numSplunkMetadata]string // names of the fields providing metadata values
+}
+
+// splunkEvent is the HEC event envelope.
+type splunkEvent struct {
+	Time       json.Number     `json:"time"`
+	Host       string          `json:"host,omitempty"`
+	Index      string          `json:"index,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Sourcetype string          `json:"sourcetype,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// metadata returns the metadata for an entry and the names of any fields
+// that provided metadata values.  A value from a field takes precedence
+// over a static value.
+func (t *splunk) metadata(e entry) ([numSplunkMetadata]string, []string) {
+	md := t.static
+	used := []string{}
+	if e.logcontext == nil || e.fields == nil {
+		return md, used
+	}
+	for id, k := range t.fields {
+		if k == "" {
+			continue
+		}
+		if v, ok := e.fields.m[k]; ok {
+			md[id] = fmt.Sprintf("%v", v)
+			used = append(used, k)
+		}
+	}
+	return md, used
+}
+
+// logEntry implements the entryTransport interface.  The entry is formatted,
+// without any fields that provide metadata values, and wrapped in a HEC
+// event envelope which is sent to the transport channel.
+func (t *splunk) logEntry(e entry, format func(entry) []byte) {
+	md, used := t.metadata(e)
+	if len(used) > 0 {
+		lc := *e.logcontext
+		lc.fields = e.fields.without(used...)
+		e.logcontext = &lc
+	}
+
+	event := format(e)
+	if !json.Valid(event) {
+		event, _ = json.Marshal(string(event))
+	}
+
+	ms := e.Time.UnixMilli()
+	rec, err := json.Marshal(splunkEvent{
+		Time:       json.Number(fmt.Sprintf("%d.%03d", ms/1000, ms%1000)),
+		Host:       md[SplunkHost],
+		Index:      md[SplunkIndex],
+		Source:     md[SplunkSource],
+		Sourcetype: md[SplunkSourcetype],
+		Event:      event,
+	})
+	if err != nil {
+		trace("splunk: logEntry: error encoding event: " + err.Error())
+		return
+	}
+	t.ch <- rec
+}
@@ -0,0 +1,169 @@
+package ulog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// splunkChannelId is a regular expression matching a valid HEC
+// acknowledgement channel id (a GUID).
+var splunkChannelId = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SplunkAck configures the transport to use indexer acknowledgement,
+// identifying requests using a specified channel id (a GUID).  If the
+// channel id is empty, a random channel id is used.
+//
+// After each batch is sent, the transport polls the acknowledgement
+// endpoint until the batch is acknowledged.  If the batch is not
+// acknowledged within the specified timeout the batch is retained and
+// sent again, so that events are delivered at least once (and may be
+// duplicated).
+//
+// The acknowledgement endpoint is derived from the configured endpoint by
+// replacing a trailing /event or /raw with /ack, preserving any prefix of
+// the path (e.g. /splunk/services/collector/event is acknowledged using
+// /splunk/services/collector/ack).
+//
+// Indexer acknowledgement must also be enabled for the token used by the
+// transport.
+func SplunkAck(channel string, timeout time.Duration) SplunkOption {
+	return func(t *splunk) error {
+		if channel == "" {
+			channel = newSplunkChannel()
+		}
+		if !splunkChannelId.MatchString(channel) {
+			return fmt.Errorf("%w: SplunkAck: %q: channel must be a GUID", ErrInvalidConfiguration, channel)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("%w: SplunkAck: timeout must be > 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(splunkAck, splunkAckConfig{channel, timeout})
+	}
+}
+
+// SplunkBatching applies batch options to configure the batching of log
+// entries sent by the transport.  By default a batch is sent when it
+// contains 100 entries or after 10 seconds.
+func SplunkBatching(opts ...BatchOption) SplunkOption {
+	return func(t *splunk) error {
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t.batch))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// SplunkEndpoint configures the url of the HTTP Event Collector.  If the
+// url does not specify a path, the path of the event endpoint
+// (/services/collector/event) is used.
+func SplunkEndpoint(s string) SplunkOption {
+	return func(t *splunk) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: SplunkEndpoint: %w", ErrInvalidConfiguration, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: SplunkEndpoint: %q: scheme must be http or https", ErrInvalidConfiguration, s)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/services/collector/event"
+		}
+		return t.batch.configure(splunkEndpoint, u.String())
+	}
+}
+
+// SplunkHeaders configures additional headers to be sent with each request.
+// This option may be specified multiple times; headers are accumulated,
+// with any header specified more than once taking the most recently
+// configured value.
+func SplunkHeaders(h map[string]string) SplunkOption {
+	return func(t *splunk) error {
+		return t.batch.configure(splunkHeaders, h)
+	}
+}
+
+// SplunkHTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func SplunkHTTPClient(c *http.Client) SplunkOption {
+	return func(t *splunk) error {
+		if c == nil {
+			return fmt.Errorf("%w: SplunkHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(splunkHTTPClient, c)
+	}
+}
+
+// SplunkMetadata configures static values for the host, index, source
+// and/or sourcetype of all events.  Any metadata not configured is
+// determined by the HTTP Event Collector (according to the configuration
+// of the token).
+func SplunkMetadata(md map[SplunkMetadataId]string) SplunkOption {
+	return func(t *splunk) error {
+		for id, v := range md {
+			if id < 0 || id >= numSplunkMetadata {
+				return fmt.Errorf("%w: SplunkMetadata: invalid metadata id (%d)", ErrInvalidConfiguration, id)
+			}
+			t.static[id] = v
+		}
+		return nil
+	}
+}
+
+// SplunkMetadataFields configures the names of fields that provide the host,
+// index, source and/or sourcetype of an event.  If an entry has a field
+// identified for an item of metadata, the value of the field replaces any
+// static value configured using SplunkMetadata.
+//
+// Fields that provide metadata are not included in the event.
+func SplunkMetadataFields(fields map[SplunkMetadataId]string) SplunkOption {
+	return func(t *splunk) error {
+		for id, k := range fields {
+			if id < 0 || id >= numSplunkMetadata {
+				return fmt.Errorf("%w: SplunkMetadataFields: invalid metadata id (%d)", ErrInvalidConfiguration, id)
+			}
+			t.fields[id] = k
+		}
+		return nil
+	}
+}
+
+// SplunkRetry configures the number of times a request is retried if it
+// fails with an error that may succeed if retried (e.g. a network error
+// or a 429 or 503 response), and the delay before the first retry.  The
+// delay is doubled for each subsequent retry.
+//
+// By default requests are not retried; a batch that cannot be sent is
+// retained and sent again when next flushed.
+func SplunkRetry(n int, backoff time.Duration) SplunkOption {
+	return func(t *splunk) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: SplunkRetry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		return t.batch.configure(splunkRetry, retryConfig{n, backoff})
+	}
+}
+
+// SplunkTimeout configures the timeout for requests.  The default is 5
+// seconds.
+func SplunkTimeout(d time.Duration) SplunkOption {
+	return func(t *splunk) error {
+		return t.batch.configure(splunkTimeout, d)
+	}
+}
+
+// SplunkToken configures the HEC token used to authenticate requests.
+//
+// The token may be specified as the name of an environment variable or file
+// from which to read the token (see: LogtailSourceToken).
+func SplunkToken(s string) SplunkOption {
+	return func(t *splunk) error {
+		return t.batch.configure(splunkAuthorization, "Splunk "+readSecret(s))
+	}
+}
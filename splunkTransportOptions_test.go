@@ -0,0 +1,226 @@
+package ulog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestSplunkTransportOptions(t *testing.T) {
+	// ARRANGE
+	var (
+		bh *splunkBatchHandler
+		st *splunk
+	)
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "SplunkAck",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkAck("01234567-89AB-cdef-0123-456789abcdef", time.Minute)(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.channel).Equals("01234567-89AB-cdef-0123-456789abcdef")
+				test.That(t, bh.ackTimeout).Equals(time.Minute)
+				test.That(t, bh.ackInterval).Equals(time.Second)
+			},
+		},
+		{scenario: "SplunkAck/random channel",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkAck("", time.Minute)(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, splunkChannelId.MatchString(bh.channel))
+			},
+		},
+		{scenario: "SplunkAck/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					SplunkAck("channel", time.Minute)(st),
+					SplunkAck("", 0)(st),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkBatching",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkBatching(BatchMaxEntries(42))(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, st.batch.max).Equals(42)
+			},
+		},
+		{scenario: "SplunkEndpoint/with path",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkEndpoint("https://hec.example.com/services/collector")(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("https://hec.example.com/services/collector")
+			},
+		},
+		{scenario: "SplunkEndpoint/without path",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkEndpoint("https://hec.example.com:8088")(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.endpoint).Equals("https://hec.example.com:8088/services/collector/event")
+			},
+		},
+		{scenario: "SplunkEndpoint/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					SplunkEndpoint("\n")(st),
+					SplunkEndpoint("localhost:8088")(st),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkHeaders(map[string]string{"X-Custom": "value"})(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "SplunkHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := SplunkHTTPClient(client)(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.client).Equals(client)
+			},
+		},
+		{scenario: "SplunkHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkHTTPClient(nil)(st)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkMetadata",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkMetadata(map[SplunkMetadataId]string{
+					SplunkHost:       "host",
+					SplunkIndex:      "index",
+					SplunkSource:     "source",
+					SplunkSourcetype: "sourcetype",
+				})(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, st.static).Equals([numSplunkMetadata]string{"host", "index", "source", "sourcetype"})
+			},
+		},
+		{scenario: "SplunkMetadata/invalid id",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkMetadata(map[SplunkMetadataId]string{numSplunkMetadata: "value"})(st)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkMetadataFields",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkMetadataFields(map[SplunkMetadataId]string{SplunkSource: "service"})(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, st.fields).Equals([numSplunkMetadata]string{SplunkSource: "service"})
+			},
+		},
+		{scenario: "SplunkMetadataFields/invalid id",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkMetadataFields(map[SplunkMetadataId]string{-1: "field"})(st)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkRetry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkRetry(3, time.Second)(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.retries).Equals(3)
+				test.That(t, bh.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "SplunkRetry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkRetry(-1, time.Second)(st)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkTimeout(time.Minute)(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.timeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "SplunkToken",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SplunkToken("token")(st)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, bh.authorization).Equals("Splunk token")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			bh = newSplunkBatchHandler()
+			st = &splunk{batchTransport: batchTransport{batch: &Batch{}}}
+			st.batch.init(bh, 16)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
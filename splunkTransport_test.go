@@ -0,0 +1,230 @@
+package ulog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestSplunkTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// SplunkTransport tests
+		{scenario: "SplunkTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*splunk) error { return opterr }
+
+				// ACT
+				result, err := SplunkTransport(SplunkEndpoint("http://localhost:8088"), SplunkToken("token"), opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "SplunkTransport/no endpoint",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := SplunkTransport(SplunkToken("token"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrSplunkConfiguration)
+			},
+		},
+		{scenario: "SplunkTransport/no token",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := SplunkTransport(SplunkEndpoint("http://localhost:8088"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrSplunkConfiguration)
+			},
+		},
+		{scenario: "SplunkTransport/truncate not supported",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := SplunkTransport(
+					SplunkEndpoint("http://localhost:8088"),
+					SplunkToken("token"),
+					SplunkBatching(BatchOversizeEntries(TruncateOversizeEntries)),
+				)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrSplunkConfiguration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SplunkTransport/with endpoint and token",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := SplunkTransport(SplunkEndpoint("http://localhost:8088"), SplunkToken("token"))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*splunk](t, result); ok {
+					test.That(t, result.batch.max, "batch capacity").Equals(100)
+					test.That(t, result.batch.maxLatency, "max latency").Equals(10 * time.Second)
+					if handler, ok := test.IsType[*splunkBatchHandler](t, result.batch.handler); ok {
+						test.That(t, handler.endpoint, "endpoint").Equals("http://localhost:8088/services/collector/event")
+						test.That(t, handler.ackEndpoint, "ack endpoint").Equals("http://localhost:8088/services/collector/ack")
+						test.That(t, handler.authorization, "authorization").Equals("Splunk token")
+					}
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry/JSON event",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &splunk{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(`{"message":"` + e.Message + `"}`) })
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`{"time":1283929565.432,"event":{"message":"message"}}`)
+			},
+		},
+		{scenario: "logEntry/string event",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &splunk{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(`level=info msg="` + e.Message + `"`) })
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`{"time":1283929565.432,"event":"level=info msg=\"message\""}`)
+			},
+		},
+		{scenario: "logEntry/with metadata",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &splunk{batchTransport: batchTransport{ch: make(chan []byte, 1)}}
+				sut.static[SplunkHost] = "static-host"
+				sut.static[SplunkIndex] = "main"
+				sut.static[SplunkSourcetype] = "_json"
+				sut.fields[SplunkHost] = "host"
+				sut.fields[SplunkSource] = "service"
+				e := entry{
+					logcontext: &logcontext{fields: newFields(2).merge(map[string]any{
+						"host": "field-host",
+						"key":  "value",
+					})},
+					Time:    tm,
+					Level:   InfoLevel,
+					Message: "message",
+				}
+				formatted := map[string]any{}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte {
+					formatted = e.fields.m
+					return []byte(`{}`)
+				})
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`{"time":1283929565.432,"host":"field-host","index":"main","sourcetype":"_json","event":{}}`)
+				test.Map(t, formatted, "formatted fields").Equals(map[string]any{"key": "value"})
+				test.That(t, len(e.fields.m), "original fields").Equals(2)
+			},
+		},
+
+		// end-to-end
+		{scenario: "sends events with acknowledgement",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var (
+					mu       sync.Mutex
+					events   []string
+					channels []string
+					polls    int
+				)
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					mu.Lock()
+					defer mu.Unlock()
+					channels = append(channels, r.Header.Get("X-Splunk-Request-Channel"))
+					switch r.URL.Path {
+					case "/services/collector/event":
+						events = append(events, strings.Split(string(body), "\n")...)
+						_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":7}`))
+					case "/services/collector/ack":
+						polls++
+						if polls == 1 {
+							_, _ = w.Write([]byte(`{"acks":{"7":false}}`))
+							return
+						}
+						_, _ = w.Write([]byte(`{"acks":{"7":true}}`))
+					}
+				}))
+				defer srv.Close()
+
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(JSONFormatter()),
+							TargetTransport(SplunkTransport(
+								SplunkEndpoint(srv.URL),
+								SplunkToken("token"),
+								SplunkAck("01234567-89ab-cdef-0123-456789abcdef", 100*time.Millisecond),
+								SplunkMetadata(map[SplunkMetadataId]string{SplunkSourcetype: "_json"}),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("entry 1")
+				logger.Info("entry 2")
+				closelog()
+
+				// ASSERT
+				mu.Lock()
+				defer mu.Unlock()
+				test.That(t, len(events), "events").Equals(2)
+				test.That(t, polls, "ack polls").Equals(2)
+				for _, c := range channels {
+					test.That(t, c, "channel").Equals("01234567-89ab-cdef-0123-456789abcdef")
+				}
+
+				event := struct {
+					Sourcetype string         `json:"sourcetype"`
+					Event      map[string]any `json:"event"`
+				}{}
+				test.Error(t, json.Unmarshal([]byte(events[1]), &event)).IsNil()
+				test.That(t, event.Sourcetype).Equals("_json")
+				test.That(t, event.Event["message"]).Equals(any("entry 2"))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
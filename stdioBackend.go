@@ -12,7 +12,8 @@ import (
 type stdioBackend struct {
 	Formatter
 	io.Writer
-	bufs pool
+	framing Framing
+	bufs    pool
 }
 
 // init initialises a stdio backend with a specified Formatter
@@ -35,18 +36,39 @@ func newStdioBackend(f Formatter, w io.Writer) *stdioBackend {
 }
 
 // dispatch satisfies the backend interface, formatting each log entry
-// and writing it to the configured io.Writer, appending char.newline.
+// and writing it to the configured io.Writer with the configured framing
+// applied.
 func (stdio *stdioBackend) dispatch(e entry) {
 	buf := stdio.bufs.Get().(*bytes.Buffer)
 	defer stdio.bufs.Put(buf)
 
 	buf.Reset()
 	stdio.Format(0, e, buf)
-	_ = buf.WriteByte(char.newline)
+
+	switch stdio.framing {
+	case NewlineFraming:
+		_ = buf.WriteByte(char.newline)
+	case NoFraming:
+	default:
+		// a length prefix must precede the formatted entry, so the
+		// framed entry is written using a second buffer
+		out := stdio.bufs.Get().(*bytes.Buffer)
+		defer stdio.bufs.Put(out)
+
+		out.Reset()
+		_, _ = out.Write(stdio.framing.appendFrame(out.AvailableBuffer(), buf.Bytes()))
+		buf = out
+	}
 
 	_, _ = buf.WriteTo(stdio.Writer)
 }
 
+// SetFraming sets the framing of a stdio backend
+func (stdio *stdioBackend) SetFraming(f Framing) error {
+	stdio.framing = f
+	return nil
+}
+
 // SetFormatter sets the formatter of a stdio backend
 func (stdio *stdioBackend) SetFormatter(f Formatter) error {
 	stdio.Formatter = f
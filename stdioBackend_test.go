@@ -45,6 +45,35 @@ func TestStdioBackend(t *testing.T) {
 			},
 		},
 
+		{scenario: "dispatch/framing",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					Framing
+					result string
+				}{
+					{Framing: NewlineFraming, result: "test\n"},
+					{Framing: LengthPrefixFraming, result: "\x00\x00\x00\x04test"},
+					{Framing: NoFraming, result: "test"},
+					{Framing: VarintFraming, result: "\x04test"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.String(), func(t *testing.T) {
+						// ARRANGE
+						buf := &bytes.Buffer{}
+						sut := newStdioBackend(&mockformatter{}, buf)
+						_ = sut.SetFraming(tc.Framing)
+
+						// ACT
+						sut.dispatch(entry{Message: "test"})
+						sut.dispatch(entry{Message: "test"})
+
+						// ASSERT
+						test.Value(t, buf.String()).Equals(tc.result + tc.result)
+					})
+				}
+			},
+		},
+
 		// SetFormatter tests
 		{scenario: "SetFormatter",
 			exec: func(t *testing.T) {
@@ -61,6 +90,21 @@ func TestStdioBackend(t *testing.T) {
 			},
 		},
 
+		// SetFraming tests
+		{scenario: "SetFraming",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &stdioBackend{}
+
+				// ACT
+				err := sut.SetFraming(VarintFraming)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Value(t, sut.framing).Equals(VarintFraming)
+			},
+		},
+
 		// SetOutput tests
 		{scenario: "SetOutput",
 			exec: func(t *testing.T) {
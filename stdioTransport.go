@@ -1,23 +1,28 @@
 package ulog
 
 import (
+	"errors"
 	"io"
 )
 
-// import (
-// 	"bytes"
-// 	"io"
-// 	"sync"
-// )
-
 type StdioTransportOption = func(*stdioTransport) error // StdioOption is a function for configuring a stdio transport
 
 // Stdio returns a factory that configures a transport to log messages
-// to an io.Writer.
-func StdioTransport(w io.Writer) TransportFactory {
+// to an io.Writer, with specified configuration options applied.
+//
+// By default, each entry is followed by a newline (see: StdioFraming).
+func StdioTransport(w io.Writer, opts ...StdioTransportOption) TransportFactory {
 	return func() (transport, error) {
 		t := &stdioTransport{}
 		t.init(w)
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
 		return t, nil
 	}
 }
@@ -28,6 +33,8 @@ func StdioTransport(w io.Writer) TransportFactory {
 // entries to an io.Writer.
 type stdioTransport struct {
 	io.Writer
+	framing Framing // the framing applied to each entry
+	buf     []byte  // buffer used to apply framing to each entry
 }
 
 // init initialises a stdio transport with a specified Writer.
@@ -36,13 +43,15 @@ func (t *stdioTransport) init(w io.Writer) {
 }
 
 // log implements the log method to satisfy the Transport
-// interface. It writes the log entry to the configured io.Writer
-// followed by a newline.
+// interface. It writes the log entry, with the configured
+// framing applied, to the configured io.Writer in a single
+// write.
 func (t *stdioTransport) log(b []byte) {
 	// there is no need to copy the slice contents in this transport
 	// as the output to the writer is synchronous; the target will
 	// not be able to re-use the slice for subsequent log entries
-	// until we have returned from this call
-	_, _ = t.Write(b)
-	_, _ = t.Write(buf.newline)
+	// until we have returned from this call.  For the same reason
+	// the transport is able to re-use a single buffer for framing
+	t.buf = t.framing.appendFrame(t.buf[:0], b)
+	_, _ = t.Write(t.buf)
 }
@@ -0,0 +1,17 @@
+package ulog
+
+import "fmt"
+
+// StdioFraming configures the framing applied to each entry.  The default
+// is NewlineFraming.  A binary format (e.g. msgpack) should use
+// NoFraming (if the format is self-delimiting) or a length prefix, since
+// the format may itself contain newline bytes.
+func StdioFraming(f Framing) StdioTransportOption {
+	return func(t *stdioTransport) error {
+		if !f.isValid() {
+			return fmt.Errorf("%w: StdioFraming: %s", ErrInvalidConfiguration, f)
+		}
+		t.framing = f
+		return nil
+	}
+}
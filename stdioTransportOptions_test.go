@@ -0,0 +1,46 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestStdioTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *stdioTransport
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "StdioFraming",
+			exec: func(t *testing.T) {
+				// ACT
+				err := StdioFraming(LengthPrefixFraming)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.framing).Equals(LengthPrefixFraming)
+			},
+		},
+		{scenario: "StdioFraming/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := StdioFraming(Framing(-1))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &stdioTransport{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package ulog
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/blugnu/test"
@@ -28,6 +30,35 @@ func TestStdioTransport(t *testing.T) {
 				}
 			},
 		},
+		{scenario: "Stdio factory applies options",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &bytes.Buffer{}
+
+				// ACT
+				result, err := StdioTransport(w, StdioFraming(VarintFraming))()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*stdioTransport](t, result); ok {
+					test.Value(t, result.framing).Equals(VarintFraming)
+				}
+			},
+		},
+		{scenario: "Stdio factory returns option errors",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*stdioTransport) error { return opterr }
+
+				// ACT
+				result, err := StdioTransport(&bytes.Buffer{}, opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
 		{scenario: "log writes to the writer",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -42,6 +73,56 @@ func TestStdioTransport(t *testing.T) {
 				test.Value(t, w.String()).Equals("test\n")
 			},
 		},
+		{scenario: "log writes a single framed entry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &writeRecorder{}
+				sut := &stdioTransport{framing: LengthPrefixFraming}
+				sut.init(w)
+
+				// ACT
+				sut.log([]byte("one"))
+				sut.log([]byte("three"))
+
+				// ASSERT
+				test.Slice(t, w.recorded()).Equals([]string{"\x00\x00\x00\x03one", "\x00\x00\x00\x05three"})
+			},
+		},
+		{scenario: "msgpack stream is decodable",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				w := &bytes.Buffer{}
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(InfoLevel),
+							TargetFormat(MsgpackFormatter()),
+							TargetTransport(StdioTransport(w, StdioFraming(NoFraming))),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("one\ntwo")
+				logger.Info("three")
+				closelog()
+
+				// ASSERT
+				b := w.Bytes()
+				messages := []any{}
+				for len(b) > 0 {
+					v, n, ok := mpDecode(b)
+					test.IsTrue(t, ok, "decoded")
+					if !ok {
+						break
+					}
+					messages = append(messages, v.(map[string]any)["message"])
+					b = b[n:]
+				}
+				test.Slice(t, messages).Equals([]any{"one\ntwo", "three"})
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
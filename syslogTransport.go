@@ -0,0 +1,340 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SyslogOption = func(*syslog) error // SyslogOption is a function that configures a syslog transport
+
+// SyslogFacilityCode identifies the syslog facility of messages.
+type SyslogFacilityCode int
+
+const (
+	SyslogKern     SyslogFacilityCode = iota // kernel messages
+	SyslogUser                               // user-level messages
+	SyslogMail                               // mail system
+	SyslogDaemon                             // system daemons
+	SyslogAuth                               // security/authorization messages
+	SyslogSyslog                             // messages generated internally by syslogd
+	SyslogLPR                                // line printer subsystem
+	SyslogNews                               // network news subsystem
+	SyslogUUCP                               // UUCP subsystem
+	SyslogCron                               // clock daemon
+	SyslogAuthPriv                           // security/authorization messages (private)
+	SyslogFTP                                // FTP daemon
+	_                                        // NTP subsystem (reserved)
+	_                                        // log audit (reserved)
+	_                                        // log alert (reserved)
+	_                                        // clock daemon (reserved)
+	SyslogLocal0                             // local use 0
+	SyslogLocal1                             // local use 1
+	SyslogLocal2                             // local use 2
+	SyslogLocal3                             // local use 3
+	SyslogLocal4                             // local use 4
+	SyslogLocal5                             // local use 5
+	SyslogLocal6                             // local use 6
+	SyslogLocal7                             // local use 7
+)
+
+// SyslogMessageFormat identifies the format of syslog messages.
+type SyslogMessageFormat int
+
+const (
+	SyslogRFC5424 SyslogMessageFormat = iota // SyslogRFC5424 formats messages according to RFC 5424
+	SyslogRFC3164                            // SyslogRFC3164 formats messages according to RFC 3164 (BSD syslog)
+)
+
+// syslogSeverity is the syslog severity for each Level.
+var syslogSeverity = [numLevels]int{
+	TraceLevel: 7, // debug
+	DebugLevel: 7, // debug
+	InfoLevel:  6, // informational
+	WarnLevel:  4, // warning
+	ErrorLevel: 3, // error
+	FatalLevel: 2, // critical
+}
+
+// syslogLocalPaths are the paths of the unix sockets on which a local
+// syslog daemon may be listening.
+var syslogLocalPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogTransport returns a transport factory function to create and
+// configure a transport that sends log entries to a syslog daemon or
+// collector, with specified configuration options applied.
+//
+// By default, messages are sent to the local syslog daemon (via the
+// /dev/log unix socket).  A remote collector may be configured using
+// the SyslogNetwork option.
+//
+// Each entry is formatted by the target Formatter to provide the MSG part
+// of the syslog message.  The Level of the entry determines the severity
+// of the message.
+//
+// Messages are sent over TCP (and TLS) connections using octet-counting
+// framing (RFC 6587) and over unix stream sockets terminated by a newline.
+// If the connection to the daemon or collector fails, the transport
+// reconnects with a backoff; messages logged while not connected are
+// discarded.
+func SyslogTransport(opts ...SyslogOption) TransportFactory {
+	return func() (transport, error) {
+		hostname, _ := os.Hostname()
+
+		t := &syslog{
+			ch:          make(chan []byte, 100),
+			protocol:    SyslogRFC5424,
+			facility:    SyslogUser,
+			hostname:    hostname,
+			appName:     filepath.Base(os.Args[0]),
+			procID:      strconv.Itoa(os.Getpid()),
+			dialTimeout: 5 * time.Second,
+			conn: &netconn{
+				name:       "syslog",
+				minBackoff: 100 * time.Millisecond,
+				maxBackoff: 30 * time.Second,
+			},
+		}
+		t.conn.dial = t.dial
+		t.conn.frame = t.frame
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		if t.tls != nil && t.network != "tcp" {
+			return nil, fmt.Errorf("%w: %w: tls requires a tcp network", ErrSyslogConfiguration, ErrInvalidConfiguration)
+		}
+		return t, nil
+	}
+}
+
+// syslog implements a transport that sends log entries to a syslog
+// daemon or collector.
+type syslog struct {
+	ch          chan []byte         // channel over which messages are received
+	conn        *netconn            // the connection to the daemon or collector
+	network     string              // the network of the daemon or collector; "" for the local daemon
+	address     string              // the address of the daemon or collector
+	tls         *tls.Config         // tls configuration for a tcp connection; nil if tls is not used
+	dialTimeout time.Duration       // timeout for establishing a connection
+	protocol    SyslogMessageFormat // the format of messages
+	facility    SyslogFacilityCode  // the facility of messages
+	hostname    string              // the HOSTNAME of messages
+	appName     string              // the APP-NAME (or TAG) of messages
+	procID      string              // the PROCID of messages
+	sdID        string              // the SD-ID of the structured-data element; "" if not used
+	sdFields    []string            // the names of fields in the structured-data element; nil for all fields
+}
+
+// dial establishes a connection to the configured network and address
+// or, if no network is configured, to the local syslog daemon.
+func (t *syslog) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: t.dialTimeout}
+
+	if t.network != "" {
+		if t.tls != nil {
+			return tls.DialWithDialer(d, t.network, t.address, t.tls)
+		}
+		return d.Dial(t.network, t.address)
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogLocalPaths {
+			if conn, err := d.Dial(network, path); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, errors.New("unix syslog delivery error")
+}
+
+// frame applies the framing required for a connection to a message.
+// Messages sent over a tcp connection are prefixed by their length;
+// messages sent over a unix stream connection are terminated by a
+// newline.  Datagrams are not framed.
+func (t *syslog) frame(conn net.Conn, msg []byte) []byte {
+	switch conn.RemoteAddr().Network() {
+	case "tcp", "tcp4", "tcp6":
+		b := strconv.AppendInt(make([]byte, 0, len(msg)+12), int64(len(msg)), 10)
+		b = append(b, ' ')
+		return append(b, msg...)
+	case "unix":
+		return append(msg, '\n')
+	}
+	return msg
+}
+
+// log implements the transport interface.  It is not used; entries are
+// received by logEntry.
+func (t *syslog) log([]byte) {}
+
+// logEntry implements the entryTransport interface.  The entry is
+// formatted, without any fields included in the structured-data element,
+// and the syslog message is sent to the transport channel.
+func (t *syslog) logEntry(e entry, format func(entry) []byte) {
+	var sd string
+	if t.sdID != "" {
+		var used []string
+		sd, used = t.structuredData(e)
+		if len(used) > 0 {
+			lc := *e.logcontext
+			lc.fields = e.fields.without(used...)
+			e.logcontext = &lc
+		}
+	}
+
+	msg := format(e)
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+
+	pri := int(t.facility)*8 + syslogSeverity[e.Level]
+
+	var hdr string
+	switch t.protocol {
+	case SyslogRFC3164:
+		hdr = t.rfc3164Header(pri, e.Time)
+	default:
+		hdr = t.rfc5424Header(pri, e.Time, sd)
+	}
+
+	rec := make([]byte, 0, len(hdr)+len(msg))
+	rec = append(rec, hdr...)
+	t.ch <- append(rec, msg...)
+}
+
+// rfc3164Header returns the header of an RFC 3164 message:
+//
+//	<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]:<space>
+//
+// The HOSTNAME is omitted from messages sent to the local daemon.
+func (t *syslog) rfc3164Header(pri int, tm time.Time) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "<%d>%s ", pri, tm.Local().Format(time.Stamp))
+	if t.network != "" && t.hostname != "" {
+		_, _ = sb.WriteString(t.hostname + " ")
+	}
+	_, _ = sb.WriteString(t.appName)
+	if t.procID != "" {
+		_, _ = sb.WriteString("[" + t.procID + "]")
+	}
+	_, _ = sb.WriteString(": ")
+	return sb.String()
+}
+
+// rfc5424Header returns the header of an RFC 5424 message, including any
+// structured-data:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA<space>
+//
+// Any empty header field is replaced by the NILVALUE ("-").
+func (t *syslog) rfc5424Header(pri int, tm time.Time, sd string) string {
+	nilvalue := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %s - %s ",
+		pri,
+		tm.Format("2006-01-02T15:04:05.000000Z07:00"),
+		nilvalue(t.hostname),
+		nilvalue(t.appName),
+		nilvalue(t.procID),
+		nilvalue(sd),
+	)
+}
+
+// structuredData returns the structured-data element for an entry and the
+// names of the fields included in the element.  If the entry has none of
+// the fields to be included, the element is empty.
+//
+// The element is only included in RFC 5424 messages; for RFC 3164 messages
+// the fields are left in the formatted entry.
+func (t *syslog) structuredData(e entry) (string, []string) {
+	if t.protocol != SyslogRFC5424 || e.logcontext == nil || e.fields == nil {
+		return "", nil
+	}
+
+	names := t.sdFields
+	if names == nil {
+		names = make([]string, 0, len(e.fields.m))
+		for k := range e.fields.m {
+			names = append(names, k)
+		}
+		slices.Sort(names)
+	}
+
+	used := make([]string, 0, len(names))
+	sb := &strings.Builder{}
+	for _, k := range names {
+		v, ok := e.fields.m[k]
+		if !ok {
+			continue
+		}
+		if len(used) == 0 {
+			_, _ = sb.WriteString("[" + t.sdID)
+		}
+		used = append(used, k)
+
+		_, _ = sb.WriteString(" " + syslogParamName(k) + `="`)
+		_, _ = sdParamEscaper.WriteString(sb, fmt.Sprintf("%v", v))
+		_ = sb.WriteByte('"')
+	}
+	if len(used) > 0 {
+		_ = sb.WriteByte(']')
+	}
+	return sb.String(), used
+}
+
+// sdParamEscaper escapes the characters that must be escaped in the value
+// of a structured-data parameter.
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogParamName returns a valid SD-NAME for a specified name.  Characters
+// that are not permitted ('=', ' ', ']', '"' and any character that is not
+// printable US-ASCII) are replaced with '_', and the name is truncated to
+// 32 characters.
+func syslogParamName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c <= 32 || c >= 127 || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 32 {
+		b = b[:32]
+	}
+	return string(b)
+}
+
+// stop closes the channel over which messages are received.
+func (t *syslog) stop() {
+	tracef("syslog: transport requested to stop...")
+	close(t.ch)
+}
+
+// run is the goroutine run loop for the transport.  The run loop
+// terminates when the channel over which messages are received is
+// closed, closing any established connection.
+func (t *syslog) run() {
+	for msg := range t.ch {
+		if err := t.conn.write(msg); err != nil {
+			trace("syslog: message discarded: " + err.Error())
+		}
+	}
+	t.conn.disconnect()
+	tracef("syslog: transport stopped")
+}
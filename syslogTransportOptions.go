@@ -0,0 +1,165 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// isPrintUSASCII returns true if a string consists only of printable
+// US-ASCII characters (excluding space).
+func isPrintUSASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] <= 32 || s[i] >= 127 {
+			return false
+		}
+	}
+	return true
+}
+
+// SyslogAppName configures the APP-NAME (RFC 5424) or TAG (RFC 3164) of
+// messages.  The default is the base name of the executable.
+func SyslogAppName(s string) SyslogOption {
+	return func(t *syslog) error {
+		if len(s) > 48 || !isPrintUSASCII(s) {
+			return fmt.Errorf("%w: SyslogAppName: %q: must be no more than 48 printable US-ASCII characters", ErrInvalidConfiguration, s)
+		}
+		t.appName = s
+		return nil
+	}
+}
+
+// SyslogDialTimeout configures the timeout for establishing a connection.
+// The default is 5 seconds.
+func SyslogDialTimeout(d time.Duration) SyslogOption {
+	return func(t *syslog) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: SyslogDialTimeout: must be > 0", ErrInvalidConfiguration)
+		}
+		t.dialTimeout = d
+		return nil
+	}
+}
+
+// SyslogFacility configures the facility of messages.  The default is
+// SyslogUser.
+func SyslogFacility(f SyslogFacilityCode) SyslogOption {
+	return func(t *syslog) error {
+		if f < SyslogKern || f > SyslogLocal7 {
+			return fmt.Errorf("%w: SyslogFacility: invalid facility (%d)", ErrInvalidConfiguration, f)
+		}
+		t.facility = f
+		return nil
+	}
+}
+
+// SyslogFormat configures the format of messages.  The default is
+// SyslogRFC5424.
+func SyslogFormat(f SyslogMessageFormat) SyslogOption {
+	return func(t *syslog) error {
+		switch f {
+		case SyslogRFC5424, SyslogRFC3164:
+			t.protocol = f
+			return nil
+		default:
+			return fmt.Errorf("%w: SyslogFormat: invalid format (%d)", ErrInvalidConfiguration, f)
+		}
+	}
+}
+
+// SyslogHostname configures the HOSTNAME of messages.  The default is the
+// host name reported by the kernel.
+func SyslogHostname(s string) SyslogOption {
+	return func(t *syslog) error {
+		if len(s) > 255 || !isPrintUSASCII(s) {
+			return fmt.Errorf("%w: SyslogHostname: %q: must be no more than 255 printable US-ASCII characters", ErrInvalidConfiguration, s)
+		}
+		t.hostname = s
+		return nil
+	}
+}
+
+// SyslogNetwork configures the network and address of the syslog daemon or
+// collector to which messages are sent.  The network must be one of "udp",
+// "tcp", "unix" (stream socket) or "unixgram" (datagram socket).
+//
+// e.g. SyslogNetwork("udp", "logs.example.com:514")
+func SyslogNetwork(network, address string) SyslogOption {
+	return func(t *syslog) error {
+		switch network {
+		case "udp", "tcp", "unix", "unixgram":
+		default:
+			return fmt.Errorf("%w: SyslogNetwork: %q: network must be udp, tcp, unix or unixgram", ErrInvalidConfiguration, network)
+		}
+		if address == "" {
+			return fmt.Errorf("%w: SyslogNetwork: address is required", ErrInvalidConfiguration)
+		}
+		t.network = network
+		t.address = address
+		return nil
+	}
+}
+
+// SyslogProcID configures the PROCID of messages.  The default is the
+// process id.
+func SyslogProcID(s string) SyslogOption {
+	return func(t *syslog) error {
+		if len(s) > 128 || !isPrintUSASCII(s) {
+			return fmt.Errorf("%w: SyslogProcID: %q: must be no more than 128 printable US-ASCII characters", ErrInvalidConfiguration, s)
+		}
+		t.procID = s
+		return nil
+	}
+}
+
+// SyslogReconnect configures the delay before attempting to reconnect
+// after a connection fails, and the maximum delay.  The delay is doubled
+// after each consecutive failure, up to the maximum.  The defaults are
+// 100ms and 30s respectively.
+func SyslogReconnect(backoff, maxBackoff time.Duration) SyslogOption {
+	return func(t *syslog) error {
+		if backoff <= 0 || maxBackoff < backoff {
+			return fmt.Errorf("%w: SyslogReconnect: backoff must be > 0 and <= max backoff", ErrInvalidConfiguration)
+		}
+		t.conn.minBackoff = backoff
+		t.conn.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
+// SyslogStructuredData configures a structured-data element (RFC 5424
+// only) with a specified SD-ID, generated from the fields of each entry.
+// If field names are specified only those fields are included in the
+// element, otherwise all fields are included.
+//
+// Fields included in the element are not included in the formatted
+// entry.  If an entry has none of the fields, the element is omitted.
+//
+// A custom SD-ID must include an enterprise number, e.g. "ulog@32473".
+func SyslogStructuredData(id string, fields ...string) SyslogOption {
+	return func(t *syslog) error {
+		if id == "" || len(id) > 32 || !isPrintUSASCII(id) || strings.ContainsAny(id, `="]`) {
+			return fmt.Errorf("%w: SyslogStructuredData: %q: invalid SD-ID", ErrInvalidConfiguration, id)
+		}
+		t.sdID = id
+		t.sdFields = nil
+		if len(fields) > 0 {
+			t.sdFields = fields
+		}
+		return nil
+	}
+}
+
+// SyslogTLS configures the transport to connect to a tcp network using
+// TLS, with a specified configuration.  A nil configuration uses the
+// default configuration.
+func SyslogTLS(cfg *tls.Config) SyslogOption {
+	return func(t *syslog) error {
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		t.tls = cfg
+		return nil
+	}
+}
@@ -0,0 +1,258 @@
+package ulog
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestSyslogTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *syslog
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "SyslogAppName",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogAppName("app")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.appName).Equals("app")
+			},
+		},
+		{scenario: "SyslogAppName/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					SyslogAppName("app name")(sut),
+					SyslogAppName(strings.Repeat("x", 49))(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogDialTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogDialTimeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.dialTimeout).Equals(time.Minute)
+			},
+		},
+		{scenario: "SyslogDialTimeout/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogDialTimeout(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogFacility",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogFacility(SyslogLocal3)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.facility).Equals(SyslogFacilityCode(19))
+			},
+		},
+		{scenario: "SyslogFacility/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogFacility(SyslogLocal7 + 1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogFormat",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogFormat(SyslogRFC3164)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.protocol).Equals(SyslogRFC3164)
+			},
+		},
+		{scenario: "SyslogFormat/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogFormat(SyslogMessageFormat(-1))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogHostname",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogHostname("host.example.com")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.hostname).Equals("host.example.com")
+			},
+		},
+		{scenario: "SyslogHostname/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogHostname("host\n")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogNetwork",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogNetwork("tcp", "logs.example.com:6514")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.network).Equals("tcp")
+				test.That(t, sut.address).Equals("logs.example.com:6514")
+			},
+		},
+		{scenario: "SyslogNetwork/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					SyslogNetwork("ip", "logs.example.com")(sut),
+					SyslogNetwork("udp", "")(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogProcID",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogProcID("worker-1")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.procID).Equals("worker-1")
+			},
+		},
+		{scenario: "SyslogProcID/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogProcID(strings.Repeat("x", 129))(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogReconnect",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogReconnect(time.Second, time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.conn.minBackoff).Equals(time.Second)
+				test.That(t, sut.conn.maxBackoff).Equals(time.Minute)
+			},
+		},
+		{scenario: "SyslogReconnect/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					SyslogReconnect(0, time.Minute)(sut),
+					SyslogReconnect(time.Minute, time.Second)(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogStructuredData",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogStructuredData("ulog@32473", "a", "b")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.sdID).Equals("ulog@32473")
+				test.Slice(t, sut.sdFields).Equals([]string{"a", "b"})
+			},
+		},
+		{scenario: "SyslogStructuredData/all fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.sdFields = []string{"a"}
+
+				// ACT
+				err := SyslogStructuredData("ulog@32473")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.sdFields).IsNil()
+			},
+		},
+		{scenario: "SyslogStructuredData/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				errs := []error{
+					SyslogStructuredData("")(sut),
+					SyslogStructuredData("meta data")(sut),
+					SyslogStructuredData("meta=data")(sut),
+				}
+
+				// ASSERT
+				test.Error(t, errs[0]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[1]).Is(ErrInvalidConfiguration)
+				test.Error(t, errs[2]).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "SyslogTLS",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cfg := &tls.Config{ServerName: "logs.example.com"}
+
+				// ACT
+				err := SyslogTLS(cfg)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tls).Equals(cfg)
+			},
+		},
+		{scenario: "SyslogTLS/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := SyslogTLS(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.tls).IsNotNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &syslog{conn: &netconn{}}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
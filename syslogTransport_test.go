@@ -0,0 +1,539 @@
+package ulog
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestSyslogTransport(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	// logto returns a logger that logs to a syslog transport configured
+	// with specified options
+	logto := func(t *testing.T, opts ...SyslogOption) (Logger, func()) {
+		logger, closelog, err := NewLogger(context.Background(),
+			Mux(
+				MuxTarget(
+					TargetLevel(InfoLevel),
+					TargetFormat(&mockformatter{}),
+					TargetTransport(SyslogTransport(opts...)),
+				),
+			),
+		)
+		test.Error(t, err).IsNil()
+		return logger, closelog
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// SyslogTransport tests
+		{scenario: "SyslogTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*syslog) error { return opterr }
+
+				// ACT
+				result, err := SyslogTransport(opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "SyslogTransport/tls without tcp",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := SyslogTransport(SyslogNetwork("udp", "localhost:514"), SyslogTLS(nil))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrSyslogConfiguration)
+			},
+		},
+		{scenario: "SyslogTransport/defaults",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				hostname, _ := os.Hostname()
+
+				// ACT
+				result, err := SyslogTransport()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*syslog](t, result); ok {
+					test.That(t, result.network, "network").Equals("")
+					test.That(t, result.protocol, "protocol").Equals(SyslogRFC5424)
+					test.That(t, result.facility, "facility").Equals(SyslogUser)
+					test.That(t, result.hostname, "hostname").Equals(hostname)
+					test.That(t, result.appName, "app name").Equals(filepath.Base(os.Args[0]))
+					test.That(t, result.procID, "proc id").Equals(strconv.Itoa(os.Getpid()))
+					test.That(t, result.conn.minBackoff, "min backoff").Equals(100 * time.Millisecond)
+					test.That(t, result.conn.maxBackoff, "max backoff").Equals(30 * time.Second)
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry/RFC5424",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{
+					ch:       make(chan []byte, 1),
+					facility: SyslogLocal0,
+					hostname: "host",
+					appName:  "app",
+					procID:   "42",
+				}
+				e := entry{Time: tm, Level: WarnLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(e.Message + "\n") })
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("<132>1 2010-09-08T07:06:05.432100Z host app 42 - - message")
+			},
+		},
+		{scenario: "logEntry/RFC5424/nil values",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{ch: make(chan []byte, 1)}
+				e := entry{Time: tm, Level: ErrorLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(e.Message) })
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("<3>1 2010-09-08T07:06:05.432100Z - - - - - message")
+			},
+		},
+		{scenario: "logEntry/RFC5424/structured data",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{
+					ch:       make(chan []byte, 1),
+					facility: SyslogUser,
+					sdID:     "ulog@32473",
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(2).merge(map[string]any{
+						"b key": `a "quoted" [value]`,
+						"a":     1,
+					})},
+					Time:    tm,
+					Level:   InfoLevel,
+					Message: "message",
+				}
+				var formatted *fields
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte {
+					formatted = e.fields
+					return []byte(e.Message)
+				})
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`<14>1 2010-09-08T07:06:05.432100Z - - - - [ulog@32473 a="1" b_key="a \"quoted\" [value\]"] message`)
+				test.That(t, formatted, "formatted fields").IsNil()
+			},
+		},
+		{scenario: "logEntry/RFC5424/structured data/selected fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{
+					ch:       make(chan []byte, 1),
+					sdID:     "ulog@32473",
+					sdFields: []string{"missing", "b"},
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(2).merge(map[string]any{
+						"a": 1,
+						"b": 2,
+					})},
+					Time:    tm,
+					Level:   InfoLevel,
+					Message: "message",
+				}
+				formatted := map[string]any{}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte {
+					formatted = e.fields.m
+					return []byte(e.Message)
+				})
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`<6>1 2010-09-08T07:06:05.432100Z - - - - [ulog@32473 b="2"] message`)
+				test.Map(t, formatted, "formatted fields").Equals(map[string]any{"a": 1})
+			},
+		},
+		{scenario: "logEntry/RFC5424/structured data/no fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{
+					ch:       make(chan []byte, 1),
+					sdID:     "ulog@32473",
+					sdFields: []string{"a"},
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"b": 2})},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(e.Message) })
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals(`<6>1 2010-09-08T07:06:05.432100Z - - - - - message`)
+			},
+		},
+		{scenario: "logEntry/RFC3164",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{
+					ch:       make(chan []byte, 1),
+					network:  "udp",
+					protocol: SyslogRFC3164,
+					facility: SyslogDaemon,
+					hostname: "host",
+					appName:  "app",
+					procID:   "42",
+					sdID:     "ulog@32473",
+				}
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"a": 1})},
+					Time:       tm,
+					Level:      InfoLevel,
+					Message:    "message",
+				}
+				formatted := map[string]any{}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte {
+					formatted = e.fields.m
+					return []byte(e.Message)
+				})
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("<30>" + tm.Local().Format(time.Stamp) + " host app[42]: message")
+				test.Map(t, formatted, "formatted fields").Equals(map[string]any{"a": 1})
+			},
+		},
+		{scenario: "logEntry/RFC3164/local",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{
+					ch:       make(chan []byte, 1),
+					protocol: SyslogRFC3164,
+					hostname: "host",
+					appName:  "app",
+				}
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				sut.logEntry(e, func(e entry) []byte { return []byte(e.Message) })
+
+				// ASSERT
+				test.That(t, string(<-sut.ch)).Equals("<6>" + tm.Local().Format(time.Stamp) + " app: message")
+			},
+		},
+
+		// frame tests
+		{scenario: "frame",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &syslog{}
+				testcases := []struct {
+					network string
+					result  string
+				}{
+					{network: "tcp", result: "7 message"},
+					{network: "unix", result: "message\n"},
+					{network: "udp", result: "message"},
+					{network: "unixgram", result: "message"},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.network, func(t *testing.T) {
+						// ACT
+						result := sut.frame(&mockconn{network: tc.network}, []byte("message"))
+
+						// ASSERT
+						test.That(t, string(result)).Equals(tc.result)
+					})
+				}
+			},
+		},
+
+		// syslogParamName tests
+		{scenario: "syslogParamName",
+			exec: func(t *testing.T) {
+				// ACT
+				result := syslogParamName("a=b]c\"d é" + strings.Repeat("x", 40))
+
+				// ASSERT
+				test.That(t, result).Equals("a_b_c_d___" + strings.Repeat("x", 22))
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "sends to local daemon",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dir, err := os.MkdirTemp("", "ulog")
+				test.Error(t, err).IsNil()
+				defer os.RemoveAll(dir)
+				path := filepath.Join(dir, "log")
+
+				og := syslogLocalPaths
+				defer func() { syslogLocalPaths = og }()
+				syslogLocalPaths = []string{filepath.Join(dir, "missing"), path}
+
+				l, err := net.ListenPacket("unixgram", path)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				logger, closelog := logto(t, SyslogAppName("app"))
+
+				// ACT
+				logger.Info("message")
+				closelog()
+
+				// ASSERT
+				b := make([]byte, 1024)
+				_ = l.SetReadDeadline(time.Now().Add(time.Second))
+				n, _, err := l.ReadFrom(b)
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, strings.HasPrefix(string(b[:n]), "<14>1 "), "header")
+				test.IsTrue(t, strings.HasSuffix(string(b[:n]), " app "+strconv.Itoa(os.Getpid())+" - - message"), "message")
+			},
+		},
+		{scenario: "sends to local daemon/stream socket",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dir, err := os.MkdirTemp("", "ulog")
+				test.Error(t, err).IsNil()
+				defer os.RemoveAll(dir)
+				path := filepath.Join(dir, "log")
+
+				og := syslogLocalPaths
+				defer func() { syslogLocalPaths = og }()
+				syslogLocalPaths = []string{path}
+
+				l, err := net.Listen("unix", path)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := make(chan string, 2)
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					s := bufio.NewScanner(conn)
+					for s.Scan() {
+						received <- s.Text()
+					}
+				}()
+
+				logger, closelog := logto(t, SyslogFormat(SyslogRFC3164), SyslogAppName("app"), SyslogProcID(""))
+
+				// ACT
+				logger.Info("message 1")
+				logger.Info("message 2")
+				closelog()
+
+				// ASSERT
+				for _, msg := range []string{"message 1", "message 2"} {
+					select {
+					case s := <-received:
+						test.IsTrue(t, strings.HasSuffix(s, " app: "+msg), msg)
+					case <-time.After(time.Second):
+						t.Fatal("timed out")
+					}
+				}
+			},
+		},
+		{scenario: "sends to local daemon/no daemon",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := syslogLocalPaths
+				defer func() { syslogLocalPaths = og }()
+				syslogLocalPaths = []string{filepath.Join(os.TempDir(), "ulog-missing")}
+				sut := &syslog{}
+
+				// ACT
+				conn, err := sut.dial()
+
+				// ASSERT
+				test.That(t, conn).IsNil()
+				test.That(t, err).IsNotNil()
+			},
+		},
+		{scenario: "sends over udp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.ListenPacket("udp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+
+				logger, closelog := logto(t,
+					SyslogNetwork("udp", l.LocalAddr().String()),
+					SyslogFacility(SyslogLocal7),
+					SyslogHostname("host"),
+					SyslogAppName("app"),
+					SyslogProcID("42"),
+				)
+
+				// ACT
+				logger.Error("message")
+				closelog()
+
+				// ASSERT
+				b := make([]byte, 1024)
+				_ = l.SetReadDeadline(time.Now().Add(time.Second))
+				n, _, err := l.ReadFrom(b)
+				test.Error(t, err).IsNil()
+				test.IsTrue(t, strings.HasPrefix(string(b[:n]), "<187>1 "), "header")
+				test.IsTrue(t, strings.HasSuffix(string(b[:n]), " host app 42 - - message"), "message")
+			},
+		},
+		{scenario: "sends over tcp",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := make(chan []byte, 1)
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					b, _ := readAll(conn)
+					received <- b
+				}()
+
+				logger, closelog := logto(t, SyslogNetwork("tcp", l.Addr().String()))
+
+				// ACT
+				logger.Info("message 1")
+				logger.Info("message 2")
+				closelog()
+
+				// ASSERT
+				select {
+				case b := <-received:
+					msgs := []string{}
+					for len(b) > 0 {
+						sp := strings.IndexByte(string(b), ' ')
+						n, err := strconv.Atoi(string(b[:sp]))
+						test.Error(t, err).IsNil()
+						msgs = append(msgs, string(b[sp+1:sp+1+n]))
+						b = b[sp+1+n:]
+					}
+					test.That(t, len(msgs), "messages").Equals(2)
+					test.IsTrue(t, strings.HasSuffix(msgs[1], " message 2"), "message 2")
+				case <-time.After(time.Second):
+					t.Fatal("timed out")
+				}
+			},
+		},
+		{scenario: "sends over tls",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				srv := httptest.NewTLSServer(http.NotFoundHandler())
+				defer srv.Close()
+
+				l, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+				test.Error(t, err).IsNil()
+				defer l.Close()
+				received := make(chan []byte, 1)
+				go func() {
+					conn, err := l.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+					b, _ := readAll(conn)
+					received <- b
+				}()
+
+				cfg := srv.Client().Transport.(*http.Transport).TLSClientConfig
+				logger, closelog := logto(t,
+					SyslogNetwork("tcp", l.Addr().String()),
+					SyslogTLS(cfg),
+				)
+
+				// ACT
+				logger.Info("message")
+				closelog()
+
+				// ASSERT
+				select {
+				case b := <-received:
+					test.IsTrue(t, strings.HasSuffix(string(b), " message"), "message")
+				case <-time.After(time.Second):
+					t.Fatal("timed out")
+				}
+			},
+		},
+		{scenario: "discards messages when not connected",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				test.Error(t, err).IsNil()
+				addr := l.Addr().String()
+				l.Close()
+
+				logger, closelog := logto(t, SyslogNetwork("tcp", addr), SyslogReconnect(time.Hour, time.Hour))
+
+				// ACT
+				logger.Info("message 1")
+				logger.Info("message 2")
+				closelog()
+
+				// ASSERT
+				// the test is that the transport closes without blocking
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+// readAll reads from a connection until it is closed by the remote end
+// or a read deadline of 1 second expires.
+func readAll(conn net.Conn) ([]byte, error) {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	b := []byte{}
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		b = append(b, buf[:n]...)
+		if err != nil {
+			return b, err
+		}
+	}
+}
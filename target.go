@@ -64,6 +64,11 @@ func (t *target) close() {
 // dispatch dispatches a log entry to the target.  The entry is
 // formatted and sent to the target Transport's Log function.
 //
+// If the Transport requires the entry as well as the formatted bytes
+// (an entryTransport) the entry is passed to the Transport together
+// with the format function of the target; the Transport is then
+// responsible for formatting the entry.
+//
 // dispatch is not thread-safe, using a single, shared buffer for
 // all calls to the function; the buffer is managed by the target.
 //
@@ -71,15 +76,17 @@ func (t *target) close() {
 // Transport is responsible for making its own copy of the slice
 // content BEFORE returning from the Log() function.
 func (t *target) dispatch(e entry) {
-	t.buf.Reset()
-	t.Format(t.formatIdx, e, t.buf)
+	if tr, ok := t.transport.(entryTransport); ok {
+		tr.logEntry(e, t.format)
+		return
+	}
 
 	// HERE BE DRAGONS!
 	//
-	// t.buf.Bytes(), which is sent to the transport.Log() function,
-	// is the slice which backs the target bytes buffer; it will be
-	// re-used for the next log entry once the Log() function has
-	// returned.
+	// the slice returned by t.format(), which is sent to the
+	// transport.Log() function, is the slice which backs the target
+	// bytes buffer; it will be re-used for the next log entry once the
+	// Log() function has returned.
 	//
 	// If the transport is asynchronous (e.g. logtail, which batches
 	// logs over a channel), then the TRANSPORT must copy the slice
@@ -92,5 +99,14 @@ func (t *target) dispatch(e entry) {
 	// This improves the efficiency of the target by avoiding copying
 	// slices that do not need to be copied.
 
-	t.log(t.buf.Bytes())
+	t.log(t.format(e))
+}
+
+// format formats an entry using the target Formatter, returning the
+// formatted bytes.  The returned slice is backed by the target buffer
+// and is valid only until the next call to format.
+func (t *target) format(e entry) []byte {
+	t.buf.Reset()
+	t.Format(t.formatIdx, e, t.buf)
+	return t.buf.Bytes()
 }
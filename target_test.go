@@ -56,6 +56,42 @@ func TestTarget_dispatch(t *testing.T) {
 	})
 }
 
+func TestTarget_dispatch_entryTransport(t *testing.T) {
+	// ARRANGE
+	var (
+		logged    entry
+		formatted string
+	)
+	sut := &target{
+		buf:       &bytes.Buffer{},
+		Formatter: &mockformatter{},
+		transport: &mockentrytransport{logEntryfn: func(e entry, format func(entry) []byte) {
+			logged = e
+			formatted = string(format(entry{Message: "modified"}))
+		}},
+	}
+
+	// ACT
+	sut.dispatch(entry{Message: "original"})
+
+	// ASSERT
+	t.Run("sends entry to Transport", func(t *testing.T) {
+		wanted := "original"
+		got := logged.Message
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+
+	t.Run("Transport formats entry with Formatter", func(t *testing.T) {
+		wanted := "modified"
+		got := formatted
+		if wanted != got {
+			t.Errorf("\nwanted %#v\ngot    %#v", wanted, got)
+		}
+	})
+}
+
 func TestTarget(t *testing.T) {
 	// ARRANGE
 	cfgWasApplied := false
@@ -0,0 +1,291 @@
+package ulog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type TemplateFormatterOption func(*templatefmt) error // TemplateFormatterOption is a function for configuring a template formatter
+
+// templateDefault is the default template of a template formatter.
+const templateDefault = `{{timestamp .Time}} {{.Level}} {{.Message}}{{with fields .Fields}} {{.}}{{end}}`
+
+// TemplateData is the data with which the template of a template formatter
+// is executed for each entry.
+type TemplateData struct {
+	Time     time.Time      // the time of the entry
+	Level    string         // the label of the level of the entry (see: TemplateLevelLabels)
+	Message  string         // the message of the entry
+	Caller   string         // the callsite of the entry as a short path and line (e.g. "pkg/file.go:42"); empty if not enabled
+	File     string         // the file of the callsite of the entry; empty if not enabled
+	Line     int            // the line of the callsite of the entry; 0 if not enabled
+	Function string         // the function of the callsite of the entry; empty if not enabled
+	Fields   map[string]any // the fields of the entry
+}
+
+// Field returns the value of a named field as a string, or "" if the entry
+// has no field with that name.
+func (d TemplateData) Field(name string) string {
+	v, ok := d.Fields[name]
+	if !ok {
+		return ""
+	}
+	return templateValue(v)
+}
+
+// templateValue returns the string representation of a field value.
+// Errors are represented by their Error() string and structs as JSON.
+func templateValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Struct || (rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct) {
+		if j, err := jsonMarshal(v); err == nil {
+			return string(j)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// templateFields returns fields in logfmt style (key=value), sorted by
+// name.  Values are quoted if required.
+func templateFields(m map[string]any) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	sb := &strings.Builder{}
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(consoleQuote(templateValue(m[k])))
+	}
+	return sb.String()
+}
+
+// TemplateFormatter returns a function that configures a formatter that
+// writes log entries using a text/template, with specified configuration
+// options applied.  This may be used to reproduce an exact line format,
+// e.g. one expected by an existing log parser.
+//
+// The template is executed with a TemplateData value for each entry.  In
+// addition to the standard text/template functions, the following are
+// available:
+//
+//	timestamp  formats a time using the configured layout (see: TemplateTimeFormat)
+//	fields     formats fields in logfmt style (key=value), sorted by name
+//	json       returns the JSON encoding of a value
+//
+// Individual fields may be referenced by name using the Field method, e.g.
+// {{.Field "requestId"}}.
+//
+// The template may be configured using TemplateText or, using a compact
+// pattern language, TemplatePattern.  The default template is equivalent
+// to the pattern:
+//
+//	%time %level %msg %fields
+//
+// The template is parsed and validated when the formatter is created, so
+// that any error in the template is returned by NewLogger.
+func TemplateFormatter(opts ...TemplateFormatterOption) FormatterFactory {
+	return func() (Formatter, error) {
+		tf := &templatefmt{
+			text:       templateDefault,
+			timeFormat: "2006-01-02T15:04:05.000000Z07:00",
+			levels: [numLevels]string{
+				TraceLevel: "TRACE",
+				DebugLevel: "DEBUG",
+				InfoLevel:  "INFO",
+				WarnLevel:  "WARN",
+				ErrorLevel: "ERROR",
+				FatalLevel: "FATAL",
+			},
+		}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(tf))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+
+		if err := tf.parse(); err != nil {
+			return nil, fmt.Errorf("%w: TemplateFormatter: %w", ErrInvalidConfiguration, err)
+		}
+		return tf, nil
+	}
+}
+
+type templatefmt struct {
+	text       string             // the text of the template
+	template   *template.Template // the parsed template
+	timeFormat string             // the layout of the time of entries (UTC)
+	levels     [numLevels]string  // the label for each Level
+}
+
+// parse parses the template text and validates the template by executing
+// it with sample data.
+func (w *templatefmt) parse() error {
+	tmpl, err := template.New("entry").
+		Option("missingkey=zero").
+		Funcs(template.FuncMap{
+			"fields": templateFields,
+			"json": func(v any) (string, error) {
+				b, err := jsonMarshal(v)
+				return string(b), err
+			},
+			"timestamp": func(t time.Time) string { return t.UTC().Format(w.timeFormat) },
+		}).
+		Parse(w.text)
+	if err != nil {
+		return err
+	}
+
+	sample := TemplateData{
+		Time:     time.Now(),
+		Level:    w.levels[InfoLevel],
+		Message:  "message",
+		Caller:   "pkg/file.go:1",
+		File:     "/src/pkg/file.go",
+		Line:     1,
+		Function: "pkg.fn",
+		Fields:   map[string]any{},
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return err
+	}
+
+	w.template = tmpl
+	return nil
+}
+
+// Format implements a Formatter that writes log entries using a template.
+// If the template fails to execute for an entry, an error is written in
+// place of the entry.
+func (w *templatefmt) Format(id int, e entry, b ByteWriter) {
+	data := TemplateData{
+		Time:    e.Time,
+		Level:   w.levels[e.Level],
+		Message: e.Message,
+	}
+	if e.callsite != nil {
+		data.File = e.callsite.file
+		data.Line = e.callsite.line
+		data.Function = e.callsite.function
+		data.Caller = consoleShortPath(e.callsite.file) + ":" + strconv.Itoa(e.callsite.line)
+	}
+	if e.logcontext != nil && e.fields != nil {
+		data.Fields = e.fields.m
+	}
+
+	buf := &bytes.Buffer{}
+	if err := w.template.Execute(buf, data); err != nil {
+		_, _ = b.Write([]byte("TEMPLATE_ERROR: " + err.Error()))
+		return
+	}
+	_, _ = b.Write(buf.Bytes())
+}
+
+// templatePattern returns the template text for a pattern (see:
+// TemplatePattern).
+func templatePattern(pattern string) (string, error) {
+	directives := map[string]string{
+		"caller":  ".Caller",
+		"fields":  "fields .Fields",
+		"file":    ".File",
+		"func":    ".Function",
+		"level":   ".Level",
+		"line":    ".Line",
+		"message": ".Message",
+		"msg":     ".Message",
+		"time":    "timestamp .Time",
+	}
+
+	sb := &strings.Builder{}
+	literal := &strings.Builder{}
+	// a literal is quoted if it contains an action delimiter or ends with
+	// '{' (which would run into the delimiter of a following directive)
+	flush := func() {
+		s := literal.String()
+		literal.Reset()
+		if strings.Contains(s, "{{") || strings.HasSuffix(s, "{") {
+			s = "{{" + strconv.Quote(s) + "}}"
+		}
+		sb.WriteString(s)
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			literal.WriteByte(pattern[i])
+			continue
+		}
+		if i+1 < len(pattern) && pattern[i+1] == '%' {
+			literal.WriteByte('%')
+			i++
+			continue
+		}
+
+		// width: an optional '-' followed by digits
+		j := i + 1
+		if j < len(pattern) && pattern[j] == '-' {
+			j++
+		}
+		for j < len(pattern) && pattern[j] >= '0' && pattern[j] <= '9' {
+			j++
+		}
+		width := pattern[i+1 : j]
+		if width == "-" {
+			return "", fmt.Errorf("pattern: invalid width at offset %d", i)
+		}
+
+		// name: a sequence of lower case letters
+		k := j
+		for k < len(pattern) && pattern[k] >= 'a' && pattern[k] <= 'z' {
+			k++
+		}
+		name := pattern[j:k]
+
+		var expr string
+		switch {
+		case name == "field":
+			end := strings.IndexByte(pattern[k:], '}')
+			if k >= len(pattern) || pattern[k] != '{' || end < 2 {
+				return "", fmt.Errorf("pattern: %%field requires a name, e.g. %%field{name}, at offset %d", i)
+			}
+			expr = ".Field " + strconv.Quote(pattern[k+1:k+end])
+			k += end + 1
+		case directives[name] != "":
+			expr = directives[name]
+		default:
+			return "", fmt.Errorf("pattern: unknown directive %q at offset %d", "%"+name, i)
+		}
+
+		flush()
+		if width != "" {
+			expr = "printf " + strconv.Quote("%"+width+"v") + " (" + expr + ")"
+		}
+		sb.WriteString("{{" + expr + "}}")
+		i = k - 1
+	}
+	flush()
+
+	return sb.String(), nil
+}
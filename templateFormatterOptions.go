@@ -0,0 +1,100 @@
+package ulog
+
+import (
+	"fmt"
+)
+
+// TemplateLevelLabels configures the labels of each Level, provided to the
+// template as TemplateData.Level.
+//
+// A map[Level]string is used to override the default label for each level
+// that is required; for any Level not included in the map, the currently
+// configured label will be left as-is.
+//
+// The default labels are:
+//
+//	TraceLevel: TRACE
+//	DebugLevel: DEBUG
+//	InfoLevel:  INFO
+//	WarnLevel:  WARN
+//	ErrorLevel: ERROR
+//	FatalLevel: FATAL
+//
+// Unlike LogfmtLevelLabels, labels are not padded; a pattern may specify a
+// width for the level (e.g. %-5level) or a template may use printf.
+func TemplateLevelLabels(levels map[Level]string) TemplateFormatterOption {
+	return func(tf *templatefmt) error {
+		for k, v := range levels {
+			if k <= levelNotSet || k >= numLevels {
+				return fmt.Errorf("%w: TemplateLevelLabels: invalid level (%d)", ErrInvalidConfiguration, k)
+			}
+			tf.levels[k] = v
+		}
+		return nil
+	}
+}
+
+// TemplatePattern configures the template using a compact pattern
+// language, comprising literal text and directives:
+//
+//	%time          the time of the entry (see: TemplateTimeFormat)
+//	%level         the level label (see: TemplateLevelLabels)
+//	%msg           the message (also: %message)
+//	%caller        the callsite as a short path and line, e.g. pkg/file.go:42
+//	%file          the file of the callsite
+//	%line          the line of the callsite
+//	%func          the function of the callsite
+//	%fields        all fields in logfmt style (key=value), sorted by name
+//	%field{name}   the value of the named field
+//	%%             a literal '%'
+//
+// A directive may specify a width between the '%' and the directive name,
+// with a leading '-' to left-align the value, e.g. %-5level.
+//
+// e.g.
+//
+//	%time %-5level [%caller] %msg %fields
+//
+// An error is returned if the pattern contains an unknown or invalid
+// directive.  This option replaces any template configured using
+// TemplateText.
+func TemplatePattern(pattern string) TemplateFormatterOption {
+	return func(tf *templatefmt) error {
+		text, err := templatePattern(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: TemplatePattern: %w", ErrInvalidConfiguration, err)
+		}
+		tf.text = text
+		return nil
+	}
+}
+
+// TemplateText configures the text/template used to format entries (see:
+// TemplateFormatter).  This option replaces any template configured using
+// TemplatePattern.
+//
+// e.g.
+//
+//	{{timestamp .Time}} [{{printf "%-5s" .Level}}] {{.Message}} user={{.Field "user"}}
+func TemplateText(text string) TemplateFormatterOption {
+	return func(tf *templatefmt) error {
+		if text == "" {
+			return fmt.Errorf("%w: TemplateText: template is required", ErrInvalidConfiguration)
+		}
+		tf.text = text
+		return nil
+	}
+}
+
+// TemplateTimeFormat configures the layout (see: time.Layout) used by the
+// timestamp template function (and the %time directive) to format the
+// time of entries, in UTC.  The default is "2006-01-02T15:04:05.000000Z07:00".
+func TemplateTimeFormat(layout string) TemplateFormatterOption {
+	return func(tf *templatefmt) error {
+		if layout == "" {
+			return fmt.Errorf("%w: TemplateTimeFormat: layout is required", ErrInvalidConfiguration)
+		}
+		tf.timeFormat = layout
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package ulog
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestTemplateFormatterOptions(t *testing.T) {
+	// ARRANGE
+	var sut *templatefmt
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "TemplateLevelLabels",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplateLevelLabels(map[Level]string{InfoLevel: "INF", WarnLevel: "WRN"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.levels[InfoLevel]).Equals("INF")
+				test.That(t, sut.levels[WarnLevel]).Equals("WRN")
+			},
+		},
+		{scenario: "TemplateLevelLabels/invalid level",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplateLevelLabels(map[Level]string{numLevels: "X"})(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "TemplatePattern",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplatePattern("%level %msg")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.text).Equals("{{.Level}} {{.Message}}")
+			},
+		},
+		{scenario: "TemplatePattern/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplatePattern("%unknown")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "TemplateText",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplateText("{{.Message}}")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.text).Equals("{{.Message}}")
+			},
+		},
+		{scenario: "TemplateText/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplateText("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "TemplateTimeFormat",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplateTimeFormat("15:04")(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.timeFormat).Equals("15:04")
+			},
+		},
+		{scenario: "TemplateTimeFormat/empty",
+			exec: func(t *testing.T) {
+				// ACT
+				err := TemplateTimeFormat("")(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			sut = &templatefmt{}
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
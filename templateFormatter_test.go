@@ -0,0 +1,232 @@
+package ulog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	// ARRANGE
+	tm := time.Date(2010, 9, 8, 7, 6, 5, 432100000, time.UTC)
+
+	// format returns an entry formatted by a formatter created with
+	// specified options
+	format := func(t *testing.T, e entry, opts ...TemplateFormatterOption) string {
+		t.Helper()
+		f, err := TemplateFormatter(opts...)()
+		test.Error(t, err).IsNil()
+
+		buf := &bytes.Buffer{}
+		f.Format(0, e, buf)
+		return buf.String()
+	}
+
+	fields := func(m map[string]any) *logcontext {
+		return &logcontext{fields: newFields(1).merge(m)}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// TemplateFormatter tests
+		{scenario: "TemplateFormatter",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := TemplateFormatter()()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.(*templatefmt).text).Equals(templateDefault)
+				test.That(t, result.(*templatefmt).template).IsNotNil()
+			},
+		},
+		{scenario: "TemplateFormatter/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+
+				// ACT
+				result, err := TemplateFormatter(func(*templatefmt) error { return opterr })()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "TemplateFormatter/parse error",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := TemplateFormatter(TemplateText("{{.Message"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "TemplateFormatter/execution error",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := TemplateFormatter(TemplateText("{{.Unknown}}"))()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "NewLogger/template error",
+			exec: func(t *testing.T) {
+				// ACT
+				_, _, err := NewLogger(context.Background(),
+					LoggerFormat(TemplateFormatter(TemplateText("{{.Message"))),
+				)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+
+		// Format tests
+		{scenario: "Format/default",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				result := format(t, e)
+
+				// ASSERT
+				test.That(t, result).Equals("2010-09-08T07:06:05.432100Z INFO message")
+			},
+		},
+		{scenario: "Format/default/fields",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				e := entry{Time: tm, Level: WarnLevel, Message: "message",
+					logcontext: fields(map[string]any{
+						"b":      1,
+						"a":      "text with spaces",
+						"err":    errors.New("error"),
+						"struct": struct{ ID int }{ID: 42},
+					}),
+				}
+
+				// ACT
+				result := format(t, e)
+
+				// ASSERT
+				test.That(t, result).Equals(`2010-09-08T07:06:05.432100Z WARN message a="text with spaces" b=1 err=error struct="{\"ID\":42}"`)
+			},
+		},
+		{scenario: "Format/text",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				e := entry{Time: tm, Level: ErrorLevel, Message: "message",
+					callsite:   &callsite{file: "/src/pkg/file.go", line: 42, function: "pkg.fn"},
+					logcontext: fields(map[string]any{"user": "alice", "n": 1}),
+				}
+
+				// ACT
+				result := format(t, e,
+					TemplateText(`{{.Time.Format "15:04"}}|{{.Level}}|{{.File}}|{{.Line}}|{{.Function}}|{{.Field "user"}}|{{.Field "missing"}}|{{json .Fields}}`),
+				)
+
+				// ASSERT
+				test.That(t, result).Equals(`07:06|ERROR|/src/pkg/file.go|42|pkg.fn|alice||{"n":1,"user":"alice"}`)
+			},
+		},
+		{scenario: "Format/pattern",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				e := entry{Time: tm, Level: InfoLevel, Message: "message",
+					callsite:   &callsite{file: "/src/pkg/file.go", line: 42, function: "pkg.fn"},
+					logcontext: fields(map[string]any{"user": "alice"}),
+				}
+
+				// ACT
+				result := format(t, e,
+					TemplatePattern("%time %-5level [%caller] %msg (%field{user}) 100%% %fields"),
+					TemplateTimeFormat("2006-01-02 15:04:05"),
+					TemplateLevelLabels(map[Level]string{InfoLevel: "INF"}),
+				)
+
+				// ASSERT
+				test.That(t, result).Equals("2010-09-08 07:06:05 INF   [pkg/file.go:42] message (alice) 100% user=alice")
+			},
+		},
+		{scenario: "Format/pattern/braces",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				e := entry{Time: tm, Level: InfoLevel, Message: "message"}
+
+				// ACT
+				result := format(t, e, TemplatePattern("[{%level}] {%msg}"))
+
+				// ASSERT
+				test.That(t, result).Equals("[{INFO}] {message}")
+			},
+		},
+		{scenario: "Format/execution error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				e := entry{Time: tm, Level: InfoLevel, Message: "message",
+					logcontext: fields(map[string]any{"n": 1}),
+				}
+
+				// ACT
+				result := format(t, e, TemplateText(`{{with .Fields.n}}{{.X}}{{end}}`))
+
+				// ASSERT
+				test.IsTrue(t, strings.HasPrefix(result, "TEMPLATE_ERROR: "))
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func Test_templatePattern(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		pattern string
+		result  string
+		err     bool
+	}{
+		{pattern: "", result: ""},
+		{pattern: "literal", result: "literal"},
+		{pattern: "100%%", result: "100%"},
+		{pattern: "{{literal}}", result: `{{"{{literal}}"}}`},
+		{pattern: "{%msg}", result: `{{"{"}}{{.Message}}}`},
+		{pattern: "[{%level}] %msg", result: `{{"[{"}}{{.Level}}}] {{.Message}}`},
+		{pattern: "%msg", result: "{{.Message}}"},
+		{pattern: "%message", result: "{{.Message}}"},
+		{pattern: "%file:%line %func", result: "{{.File}}:{{.Line}} {{.Function}}"},
+		{pattern: "%5line", result: `{{printf "%5v" (.Line)}}`},
+		{pattern: "%field{a.b}", result: `{{.Field "a.b"}}`},
+		{pattern: "%unknown", err: true},
+		{pattern: "%", err: true},
+		{pattern: "%-level", err: true},
+		{pattern: "%field", err: true},
+		{pattern: "%field{}", err: true},
+		{pattern: "%field{name", err: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			// ACT
+			result, err := templatePattern(tc.pattern)
+
+			// ASSERT
+			test.That(t, err != nil).Equals(tc.err)
+			test.That(t, result).Equals(tc.result)
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package ulog
 
-import "io"
+import (
+	"io"
+	"net"
+)
 
 type mockbackend struct {
-	dispatchfn  func(entry)
-	setformatfn func(Formatter) error
-	setoutputfn func(io.Writer) error
-	startfn     func() (func(), error)
+	dispatchfn   func(entry)
+	setformatfn  func(Formatter) error
+	setframingfn func(Framing) error
+	setoutputfn  func(io.Writer) error
+	startfn      func() (func(), error)
 }
 
 func (b *mockbackend) dispatch(e entry) {
@@ -17,6 +21,10 @@ func (b *mockbackend) SetFormatter(f Formatter) error {
 	return b.setformatfn(f)
 }
 
+func (b *mockbackend) SetFraming(f Framing) error {
+	return b.setframingfn(f)
+}
+
 func (b *mockbackend) SetOutput(w io.Writer) error {
 	return b.setoutputfn(w)
 }
@@ -33,7 +41,7 @@ type mockBatchHandler struct {
 }
 
 func (m *mockBatchHandler) configure(key cfgkey, value any) error { return nil }
-func (m *mockBatchHandler) send(batch *Batch) error {
+func (m *mockBatchHandler) Send(batch *Batch) error {
 	m.sendCalls++
 
 	fn := func(*Batch) error { return nil }
@@ -68,6 +76,15 @@ func (m *mocktransport) log([]byte) {
 	m.logWasCalled = true
 }
 
+type mockentrytransport struct {
+	mocktransport
+	logEntryfn func(entry, func(entry) []byte)
+}
+
+func (m *mockentrytransport) logEntry(e entry, format func(entry) []byte) {
+	m.logEntryfn(e, format)
+}
+
 type mockmutex struct {
 	lockWasCalled   bool
 	unlockWasCalled bool
@@ -111,3 +128,28 @@ func (md *mockdispatcher) dispatch(e entry) {
 func (md *mockdispatcher) Reset() {
 	md.entry = noop.entry
 }
+
+type mockconn struct {
+	net.Conn
+	network        string
+	written        [][]byte
+	writefn        func([]byte) (int, error)
+	closeWasCalled bool
+}
+
+func (m *mockconn) Close() error {
+	m.closeWasCalled = true
+	return nil
+}
+
+func (m *mockconn) RemoteAddr() net.Addr {
+	return &net.UnixAddr{Net: m.network, Name: "mock"}
+}
+
+func (m *mockconn) Write(b []byte) (int, error) {
+	if m.writefn != nil {
+		return m.writefn(b)
+	}
+	m.written = append(m.written, append([]byte{}, b...))
+	return len(b), nil
+}
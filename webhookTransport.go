@@ -0,0 +1,278 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type WebhookOption = func(*webhook) error // WebhookOption is a function that configures a webhook transport
+
+// webhookDefaultTemplate is the default template for the payload of a
+// webhook request; a JSON object with a "text" property, accepted by both
+// Slack and Microsoft Teams incoming webhooks.
+const webhookDefaultTemplate = `{"text":{{json .Text}}}`
+
+// WebhookData is the data with which the template of a webhook payload is
+// executed (see: WebhookTemplate).
+//
+// For an alert, the Time, Level, Message, Fields and Entry identify the
+// entry that raised the alert and Digest is nil.  For a digest, Digest
+// summarises the suppressed alerts and all other fields (other than Text
+// and Time, the time of the digest) have zero values.
+type WebhookData struct {
+	Text    string              // a summary of the alert or digest, suitable as the text of a chat message
+	Time    time.Time           // the time of the entry (or digest)
+	Level   Level               // the level of the entry
+	Message string              // the message of the entry
+	Fields  map[string]any      // the fields of the entry; nil if the entry has no fields
+	Entry   string              // the entry, formatted by the target Formatter
+	Digest  []WebhookDigestItem // suppressed alerts, if a digest; otherwise nil
+}
+
+// WebhookDigestItem summarises the occurrences of an alert that were
+// suppressed by throttling.
+type WebhookDigestItem struct {
+	Level   Level     // the level of the suppressed entries
+	Message string    // the message of the suppressed entries
+	Count   int       // the number of entries suppressed
+	First   time.Time // the time of the first suppressed entry
+	Last    time.Time // the time of the most recent suppressed entry
+}
+
+// String returns a summary of the suppressed alerts, e.g.
+// "42 more occurrences of ERROR: message".
+func (item WebhookDigestItem) String() string {
+	return fmt.Sprintf("%d more occurrences of %s", item.Count, webhookText(item.Level, item.Message))
+}
+
+// webhookText returns the text summarising an alert for an entry with a
+// specified level and message.
+func webhookText(level Level, msg string) string {
+	return strings.ToUpper(level.String()) + ": " + msg
+}
+
+// webhookThrottle records the state of throttling of an alert.
+type webhookThrottle struct {
+	WebhookDigestItem           // the suppressed occurrences of the alert
+	start             time.Time // the start of the current throttling window
+}
+
+// newWebhookTemplate returns a template for webhook payloads parsed from a
+// specified string, with a "json" function that returns the JSON encoding
+// of a value.
+func newWebhookTemplate(s string) (*template.Template, error) {
+	return template.New("webhook").
+		Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				b, err := json.Marshal(v)
+				return string(b), err
+			},
+		}).
+		Parse(s)
+}
+
+// WebhookTransport returns a transport factory function to create and
+// configure a transport that posts alerts for log entries to a webhook,
+// such as a Slack or Microsoft Teams incoming webhook, with specified
+// configuration options applied.
+//
+// An alert is posted for every entry received by the transport; to post
+// alerts only for errors, use the transport in a mux target with a level
+// of ErrorLevel:
+//
+//	ulog.MuxTarget(
+//	    ulog.TargetLevel(ulog.ErrorLevel),
+//	    ulog.TargetTransport(ulog.WebhookTransport(url)),
+//	)
+//
+// The payload of each request is obtained by executing a template (see:
+// WebhookTemplate); the default payload is a JSON object with a "text"
+// property, e.g. {"text":"ERROR: message"}.
+//
+// Repeated alerts are throttled: after an alert has been posted, further
+// entries with the same level and message are suppressed until the
+// throttling window has elapsed (see: WebhookThrottle).  Suppressed
+// entries are summarised in a digest, posted periodically (see:
+// WebhookDigestInterval) and when the transport is stopped, e.g.
+// {"text":"42 more occurrences of ERROR: message"}.
+//
+// Alerts are posted asynchronously; an alert that cannot be posted is
+// discarded.
+func WebhookTransport(url string, opts ...WebhookOption) TransportFactory {
+	return func() (transport, error) {
+		if url == "" {
+			return nil, fmt.Errorf("%w: %w: url is required", ErrWebhookConfiguration, ErrInvalidConfiguration)
+		}
+
+		t := &webhook{
+			httpSender:     newHTTPSender(),
+			ch:             make(chan WebhookData, 100),
+			url:            url,
+			template:       template.Must(newWebhookTemplate(webhookDefaultTemplate)),
+			window:         time.Minute,
+			digestInterval: 5 * time.Minute,
+			throttles:      map[string]*webhookThrottle{},
+		}
+
+		errs := []error{}
+		for _, opt := range opts {
+			errs = append(errs, opt(t))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		t.applyTimeout()
+		return t, nil
+	}
+}
+
+// webhook implements a transport that posts alerts to a webhook.
+type webhook struct {
+	httpSender
+	ch             chan WebhookData            // channel over which alerts are received
+	url            string                      // the url of the webhook
+	template       *template.Template          // the template of request payloads
+	window         time.Duration               // the throttling window; 0 = no throttling
+	digestInterval time.Duration               // the interval at which digests are posted
+	throttles      map[string]*webhookThrottle // throttling state, by alert key
+	order          []string                    // alert keys, in the order in which they were first throttled
+}
+
+// logEntry implements the entryTransport interface.  The data for an alert
+// is sent to the transport channel.
+func (t *webhook) logEntry(e entry, format func(entry) []byte) {
+	d := WebhookData{
+		Text:    webhookText(e.Level, e.Message),
+		Time:    e.Time,
+		Level:   e.Level,
+		Message: e.Message,
+		Entry:   string(format(e)),
+	}
+	if e.logcontext != nil && e.fields != nil {
+		d.Fields = e.fields.m
+	}
+	t.ch <- d
+}
+
+// log implements the transport interface.  It is not used; alerts are
+// received by logEntry.
+func (t *webhook) log([]byte) {}
+
+// stop closes the channel over which alerts are received.
+func (t *webhook) stop() {
+	tracef("webhook: transport requested to stop...")
+	close(t.ch)
+}
+
+// alert posts an alert unless it is throttled, in which case the
+// occurrence is recorded for inclusion in a digest.
+func (t *webhook) alert(d WebhookData) {
+	if t.window <= 0 {
+		t.post(d)
+		return
+	}
+
+	tm := now()
+	key := d.Level.String() + "\x00" + d.Message
+	th, ok := t.throttles[key]
+	if ok && tm.Sub(th.start) < t.window {
+		if th.Count == 0 {
+			th.First = d.Time
+		}
+		th.Count++
+		th.Last = d.Time
+		return
+	}
+	if !ok {
+		th = &webhookThrottle{WebhookDigestItem: WebhookDigestItem{Level: d.Level, Message: d.Message}}
+		t.throttles[key] = th
+		t.order = append(t.order, key)
+	}
+	th.start = tm
+	t.post(d)
+}
+
+// digest posts a digest of any suppressed alerts.  Throttling state is
+// discarded for any alert for which no occurrences were suppressed and
+// the throttling window has elapsed.
+func (t *webhook) digest() {
+	tm := now()
+	d := WebhookData{Time: tm}
+	text := []string{}
+	order := t.order[:0]
+	for _, key := range t.order {
+		th := t.throttles[key]
+		if th.Count > 0 {
+			d.Digest = append(d.Digest, th.WebhookDigestItem)
+			text = append(text, th.String())
+			th.Count = 0
+		} else if tm.Sub(th.start) >= t.window {
+			delete(t.throttles, key)
+			continue
+		}
+		order = append(order, key)
+	}
+	t.order = order
+
+	if len(d.Digest) == 0 {
+		return
+	}
+	d.Text = strings.Join(text, "\n")
+	t.post(d)
+}
+
+// post posts the payload for specified data to the webhook.
+func (t *webhook) post(d WebhookData) {
+	buf := &bytes.Buffer{}
+	if err := t.template.Execute(buf, d); err != nil {
+		trace("webhook: alert discarded: error executing template: " + err.Error())
+		return
+	}
+
+	rq, err := t.newRequest(http.MethodPost, t.url, buf.Bytes())
+	if err != nil {
+		trace("webhook: alert discarded: " + err.Error())
+		return
+	}
+	rq.Header.Set("Content-Type", "application/json")
+
+	if _, err := t.do(rq); err != nil {
+		trace("webhook: alert discarded: " + err.Error())
+	}
+}
+
+// run is the goroutine run loop for the transport.  Alerts are posted (or
+// throttled) as they are received and a digest of suppressed alerts is
+// posted periodically.
+//
+// The run loop terminates when the channel over which alerts are received
+// is closed, after posting a final digest of any suppressed alerts.
+func (t *webhook) run() {
+	var tick <-chan time.Time
+	if t.window > 0 {
+		ticker := time.NewTicker(t.digestInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case d, ok := <-t.ch:
+			if !ok {
+				t.digest()
+				tracef("webhook: transport stopped")
+				return
+			}
+			t.alert(d)
+
+		case <-tick:
+			t.digest()
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ulog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookDigestInterval configures the interval at which a digest of
+// suppressed alerts is posted.  A digest is only posted if alerts were
+// suppressed since the previous digest.  The default is 5 minutes.
+func WebhookDigestInterval(d time.Duration) WebhookOption {
+	return func(t *webhook) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: WebhookDigestInterval: %s: must be > 0", ErrInvalidConfiguration, d)
+		}
+		t.digestInterval = d
+		return nil
+	}
+}
+
+// WebhookHeaders configures additional headers to be sent with each
+// request, e.g. to provide authentication required by the webhook.  This
+// option may be specified multiple times; headers are accumulated, with
+// any header specified more than once taking the most recently configured
+// value.
+func WebhookHeaders(h map[string]string) WebhookOption {
+	return func(t *webhook) error {
+		t.setHeaders(h)
+		return nil
+	}
+}
+
+// WebhookHTTPClient configures the http.Client used to send requests.
+//
+// By default, a client is used with a Timeout of 5 seconds and all other
+// settings left at their defaults.
+func WebhookHTTPClient(c *http.Client) WebhookOption {
+	return func(t *webhook) error {
+		if c == nil {
+			return fmt.Errorf("%w: WebhookHTTPClient: client is nil", ErrInvalidConfiguration)
+		}
+		t.client = c
+		return nil
+	}
+}
+
+// WebhookRetry configures the number of times a request is retried if it
+// fails with an error that may succeed if retried (e.g. a network error or
+// a 429 or 5xx response), and the delay before the first retry.  The delay
+// is doubled for each subsequent retry.
+//
+// By default requests are not retried; an alert that cannot be posted is
+// discarded.
+func WebhookRetry(n int, backoff time.Duration) WebhookOption {
+	return func(t *webhook) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("%w: WebhookRetry: retries and backoff must be >= 0", ErrInvalidConfiguration)
+		}
+		t.setRetry(n, backoff)
+		return nil
+	}
+}
+
+// WebhookTemplate configures the text/template used to produce the JSON
+// payload of each request.  The template is executed with a WebhookData
+// value and may use a "json" function to obtain the JSON encoding of a
+// value.  The default template is:
+//
+//	{"text":{{json .Text}}}
+//
+// e.g. a Slack payload using blocks, with a different title for digests:
+//
+//	{"blocks":[
+//	  {"type":"header","text":{"type":"plain_text","text":{{if .Digest}}"Suppressed alerts"{{else}}{{json .Level.String}}{{end}}}},
+//	  {"type":"section","text":{"type":"mrkdwn","text":{{json .Text}}}}
+//	]}
+//
+// The template is validated when the option is applied, by executing it
+// with sample alert and digest data; an error is returned if the template
+// cannot be executed or does not produce valid JSON.
+func WebhookTemplate(s string) WebhookOption {
+	return func(t *webhook) error {
+		tmpl, err := newWebhookTemplate(s)
+		if err != nil {
+			return fmt.Errorf("%w: WebhookTemplate: %w", ErrInvalidConfiguration, err)
+		}
+
+		samples := []WebhookData{
+			{Text: "ERROR: message", Level: ErrorLevel, Message: "message", Fields: map[string]any{"key": "value"}, Entry: "entry"},
+			{Text: "1 more occurrences of ERROR: message", Digest: []WebhookDigestItem{{Level: ErrorLevel, Message: "message", Count: 1}}},
+		}
+		for _, d := range samples {
+			sb := &strings.Builder{}
+			if err := tmpl.Execute(sb, d); err != nil {
+				return fmt.Errorf("%w: WebhookTemplate: %w", ErrInvalidConfiguration, err)
+			}
+			if !json.Valid([]byte(sb.String())) {
+				return fmt.Errorf("%w: WebhookTemplate: template does not produce valid JSON: %s", ErrInvalidConfiguration, sb.String())
+			}
+		}
+
+		t.template = tmpl
+		return nil
+	}
+}
+
+// WebhookThrottle configures the throttling window.  After an alert has
+// been posted, further entries with the same level and message are
+// suppressed until the window has elapsed; suppressed entries are
+// summarised in a digest (see: WebhookDigestInterval).
+//
+// The default is 1 minute.  A window of 0 disables throttling; an alert is
+// posted for every entry.
+func WebhookThrottle(d time.Duration) WebhookOption {
+	return func(t *webhook) error {
+		if d < 0 {
+			return fmt.Errorf("%w: WebhookThrottle: %s: must be >= 0", ErrInvalidConfiguration, d)
+		}
+		t.window = d
+		return nil
+	}
+}
+
+// WebhookTimeout configures the timeout for requests.  The default is 5
+// seconds.
+func WebhookTimeout(d time.Duration) WebhookOption {
+	return func(t *webhook) error {
+		t.setTimeout(d)
+		return nil
+	}
+}
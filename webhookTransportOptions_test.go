@@ -0,0 +1,168 @@
+package ulog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestWebhookTransportOptions(t *testing.T) {
+	// ARRANGE
+	var sut *webhook
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "WebhookDigestInterval",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookDigestInterval(time.Hour)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.digestInterval).Equals(time.Hour)
+			},
+		},
+		{scenario: "WebhookDigestInterval/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookDigestInterval(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "WebhookHeaders",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookHeaders(map[string]string{"X-Custom": "value"})(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.headers.Get("X-Custom")).Equals("value")
+			},
+		},
+		{scenario: "WebhookHTTPClient",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				client := &http.Client{}
+
+				// ACT
+				err := WebhookHTTPClient(client)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.client).Equals(client)
+			},
+		},
+		{scenario: "WebhookHTTPClient/nil",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookHTTPClient(nil)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "WebhookRetry",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookRetry(3, time.Second)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.retries).Equals(3)
+				test.That(t, sut.backoff).Equals(time.Second)
+			},
+		},
+		{scenario: "WebhookRetry/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookRetry(1, -1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "WebhookTemplate",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				s := `{"blocks":[
+					{"type":"header","text":{"type":"plain_text","text":{{if .Digest}}"Suppressed alerts"{{else}}{{json .Level.String}}{{end}}}},
+					{"type":"section","text":{"type":"mrkdwn","text":{{json .Text}}}}
+				]}`
+
+				// ACT
+				err := WebhookTemplate(s)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.template.Root.String()).Equals(s)
+			},
+		},
+		{scenario: "WebhookTemplate/invalid",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					scenario string
+					template string
+				}{
+					{scenario: "parse error", template: `{"text":{{json .Text}`},
+					{scenario: "execution error", template: `{"text":{{.Unknown}}}`},
+					{scenario: "alert execution error", template: `{"count":{{(index .Digest 0).Count}}}`},
+					{scenario: "invalid json", template: `{"text":{{.Text}}}`},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.scenario, func(t *testing.T) {
+						// ACT
+						err := WebhookTemplate(tc.template)(sut)
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidConfiguration)
+					})
+				}
+			},
+		},
+		{scenario: "WebhookThrottle",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookThrottle(0)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.window).Equals(time.Duration(0))
+			},
+		},
+		{scenario: "WebhookThrottle/invalid",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookThrottle(-1)(sut)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "WebhookTimeout",
+			exec: func(t *testing.T) {
+				// ACT
+				err := WebhookTimeout(time.Minute)(sut)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, sut.timeout).Equals(time.Minute)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			tr, _ := WebhookTransport("http://localhost")()
+			sut = tr.(*webhook)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
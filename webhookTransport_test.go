@@ -0,0 +1,371 @@
+package ulog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+// webhookReceiver is an http server recording the payloads of requests.
+type webhookReceiver struct {
+	sync.Mutex
+	*httptest.Server
+	payloads []string
+	status   int
+}
+
+func newWebhookReceiver() *webhookReceiver {
+	r := &webhookReceiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, rq *http.Request) {
+		r.Lock()
+		defer r.Unlock()
+		body, _ := io.ReadAll(rq.Body)
+		r.payloads = append(r.payloads, string(body))
+		w.WriteHeader(r.status)
+	}))
+	return r
+}
+
+func TestWebhookTransport(t *testing.T) {
+	// ARRANGE
+	var (
+		sut *webhook
+		rcv *webhookReceiver
+		tm  = time.Date(2010, 9, 8, 7, 6, 5, 0, time.UTC)
+		clk = tm
+	)
+	defer test.Using(&now, func() time.Time { return clk })()
+
+	alert := func(lv Level, msg string) WebhookData {
+		return WebhookData{Text: webhookText(lv, msg), Time: clk, Level: lv, Message: msg}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// WebhookTransport tests
+		{scenario: "WebhookTransport/no url",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := WebhookTransport("")()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(ErrWebhookConfiguration)
+				test.Error(t, err).Is(ErrInvalidConfiguration)
+			},
+		},
+		{scenario: "WebhookTransport/option error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				opterr := errors.New("option error")
+				opt := func(*webhook) error { return opterr }
+
+				// ACT
+				result, err := WebhookTransport("http://localhost", opt)()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+				test.Error(t, err).Is(opterr)
+			},
+		},
+		{scenario: "WebhookTransport/defaults",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := WebhookTransport("http://localhost")()
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				if result, ok := test.IsType[*webhook](t, result); ok {
+					test.That(t, result.url, "url").Equals("http://localhost")
+					test.That(t, result.window, "window").Equals(time.Minute)
+					test.That(t, result.digestInterval, "digest interval").Equals(5 * time.Minute)
+					test.That(t, result.client.Timeout, "timeout").Equals(5 * time.Second)
+				}
+			},
+		},
+
+		// logEntry tests
+		{scenario: "logEntry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.ch = make(chan WebhookData, 1)
+				e := entry{
+					logcontext: &logcontext{fields: newFields(1).merge(map[string]any{"key": "value"})},
+					Time:       tm,
+					Level:      ErrorLevel,
+					Message:    "message",
+				}
+				format := func(entry) []byte { return []byte("formatted") }
+
+				// ACT
+				sut.logEntry(e, format)
+
+				// ASSERT
+				d := <-sut.ch
+				test.That(t, d.Text).Equals("ERROR: message")
+				test.That(t, d.Time).Equals(tm)
+				test.That(t, d.Level).Equals(ErrorLevel)
+				test.That(t, d.Message).Equals("message")
+				test.Map(t, d.Fields).Equals(map[string]any{"key": "value"})
+				test.That(t, d.Entry).Equals("formatted")
+			},
+		},
+
+		// alert tests
+		{scenario: "alert",
+			exec: func(t *testing.T) {
+				// ACT
+				sut.alert(alert(ErrorLevel, `"quoted"`))
+
+				// ASSERT
+				test.Slice(t, rcv.payloads).Equals([]string{`{"text":"ERROR: \"quoted\""}`})
+			},
+		},
+		{scenario: "alert/throttled",
+			exec: func(t *testing.T) {
+				// ACT
+				sut.alert(alert(ErrorLevel, "one"))
+				sut.alert(alert(ErrorLevel, "one"))
+				sut.alert(alert(FatalLevel, "one"))
+				sut.alert(alert(ErrorLevel, "two"))
+				clk = clk.Add(30 * time.Second)
+				sut.alert(alert(ErrorLevel, "one"))
+
+				// ASSERT
+				test.Slice(t, rcv.payloads).Equals([]string{
+					`{"text":"ERROR: one"}`,
+					`{"text":"FATAL: one"}`,
+					`{"text":"ERROR: two"}`,
+				})
+				test.That(t, sut.throttles["ERROR\x00one"].Count).Equals(2)
+				test.That(t, sut.throttles["ERROR\x00one"].First).Equals(tm)
+				test.That(t, sut.throttles["ERROR\x00one"].Last).Equals(clk)
+			},
+		},
+		{scenario: "alert/window elapsed",
+			exec: func(t *testing.T) {
+				// ACT
+				sut.alert(alert(ErrorLevel, "one"))
+				clk = clk.Add(time.Minute)
+				sut.alert(alert(ErrorLevel, "one"))
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(2)
+			},
+		},
+		{scenario: "alert/throttling disabled",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.window = 0
+
+				// ACT
+				sut.alert(alert(ErrorLevel, "one"))
+				sut.alert(alert(ErrorLevel, "one"))
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(2)
+				test.That(t, len(sut.throttles)).Equals(0)
+			},
+		},
+
+		// digest tests
+		{scenario: "digest",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				for i := 0; i < 43; i++ {
+					sut.alert(alert(ErrorLevel, "one"))
+				}
+				sut.alert(alert(ErrorLevel, "two"))
+				sut.alert(alert(WarnLevel, "three"))
+				sut.alert(alert(WarnLevel, "three"))
+
+				// ACT
+				sut.digest()
+
+				// ASSERT
+				test.Slice(t, rcv.payloads[3:]).Equals([]string{
+					`{"text":"42 more occurrences of ERROR: one\n1 more occurrences of WARN: three"}`,
+				})
+				test.That(t, sut.throttles["ERROR\x00one"].Count).Equals(0)
+			},
+		},
+		{scenario: "digest/nothing suppressed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.alert(alert(ErrorLevel, "one"))
+
+				// ACT
+				sut.digest()
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(1)
+				test.That(t, len(sut.throttles)).Equals(1)
+			},
+		},
+		{scenario: "digest/discards expired throttles",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.alert(alert(ErrorLevel, "one"))
+				sut.alert(alert(ErrorLevel, "two"))
+				sut.alert(alert(ErrorLevel, "two"))
+				clk = clk.Add(time.Minute)
+
+				// ACT
+				sut.digest()
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(3)
+				test.That(t, len(sut.throttles)).Equals(1)
+				test.Slice(t, sut.order).Equals([]string{"ERROR\x00two"})
+
+				// ACT
+				sut.digest()
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(3)
+				test.That(t, len(sut.throttles)).Equals(0)
+			},
+		},
+		{scenario: "digest/template data",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut.template = webhookTemplate(t, `{{if .Digest}}{"count":{{(index .Digest 0).Count}},"first":{{json (index .Digest 0).First}}}{{else}}{}{{end}}`)
+				sut.alert(alert(ErrorLevel, "one"))
+				sut.alert(alert(ErrorLevel, "one"))
+				sut.alert(alert(ErrorLevel, "one"))
+
+				// ACT
+				sut.digest()
+
+				// ASSERT
+				test.That(t, rcv.payloads[1]).Equals(`{"count":2,"first":"2010-09-08T07:06:05Z"}`)
+			},
+		},
+
+		// post tests
+		{scenario: "post/template error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+				sut.template = webhookTemplate(t, `{{.Level.Foo}}`)
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.post(alert(ErrorLevel, "one"))
+				})
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(0)
+				stderr.Contains("webhook: alert discarded: error executing template")
+			},
+		},
+		{scenario: "post/invalid url",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+				sut.url = "\n"
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.post(alert(ErrorLevel, "one"))
+				})
+
+				// ASSERT
+				stderr.Contains("webhook: alert discarded")
+			},
+		},
+		{scenario: "post/error response",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				og := traceFn
+				defer func() { traceFn = og }()
+				EnableTraceLogs(nil)
+				rcv.status = http.StatusBadRequest
+
+				// ACT
+				_, stderr := test.CaptureOutput(t, func() {
+					sut.post(alert(ErrorLevel, "one"))
+				})
+
+				// ASSERT
+				test.That(t, len(rcv.payloads)).Equals(1)
+				stderr.Contains("webhook: alert discarded: unexpected response: 400 Bad Request")
+			},
+		},
+
+		// end-to-end tests
+		{scenario: "posts alerts and digest",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				logger, closelog, err := NewLogger(context.Background(),
+					Mux(
+						MuxTarget(
+							TargetLevel(ErrorLevel),
+							TargetTransport(WebhookTransport(rcv.URL,
+								WebhookTemplate(`{"text":{{json .Text}},"key":{{json .Fields.key}}}`),
+							)),
+						),
+					),
+				)
+				test.Error(t, err).IsNil()
+
+				// ACT
+				logger.Info("not alerted")
+				logger.WithField("key", "value").Error("failed")
+				logger.WithField("key", "value").Error("failed")
+				logger.WithField("key", "value").Error("failed")
+				closelog()
+
+				// ASSERT
+				rcv.Lock()
+				defer rcv.Unlock()
+				test.That(t, len(rcv.payloads)).Equals(2)
+
+				payload := map[string]any{}
+				test.Error(t, json.Unmarshal([]byte(rcv.payloads[0]), &payload)).IsNil()
+				test.Map(t, payload).Equals(map[string]any{"text": "ERROR: failed", "key": "value"})
+				test.That(t, rcv.payloads[1]).Equals(`{"text":"2 more occurrences of ERROR: failed","key":null}`)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			// ARRANGE
+			clk = tm
+			rcv = newWebhookReceiver()
+			defer rcv.Close()
+
+			tr, _ := WebhookTransport(rcv.URL)()
+			sut = tr.(*webhook)
+
+			// ACT
+			tc.exec(t)
+		})
+	}
+}
+
+// webhookTemplate returns a webhook template parsed from a specified
+// string, failing the test if the template cannot be parsed.
+func webhookTemplate(t *testing.T, s string) *template.Template {
+	t.Helper()
+	tmpl, err := newWebhookTemplate(s)
+	if err != nil {
+		t.Fatalf("webhookTemplate: %v", err)
+	}
+	return tmpl
+}